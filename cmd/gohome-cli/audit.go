@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	auditv1 "github.com/joshp123/gohome/proto/gen/audit/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func auditCmd(ctx context.Context, conn *grpc.ClientConn, args []string, jsonOutput bool) {
+	out := outputMode{json: jsonOutput}
+	flags := flag.NewFlagSet("audit", flag.ExitOnError)
+	caller := flags.String("caller", "", "Only entries from this token name")
+	method := flags.String("method", "", "Only methods containing this text (e.g. SetTemperature)")
+	since := flags.Duration("since", 0, "Only entries newer than this (e.g. 24h)")
+	limit := flags.Uint("limit", 20, "Maximum entries to show")
+	failures := flags.Bool("failures", false, "Only failed or denied calls")
+	_ = flags.Parse(args)
+
+	req := &auditv1.QueryRequest{
+		Caller:       *caller,
+		Method:       *method,
+		Limit:        uint32(*limit),
+		FailuresOnly: *failures,
+	}
+	if *since > 0 {
+		req.Since = timestamppb.New(time.Now().Add(-*since))
+	}
+
+	resp, err := auditv1.NewAuditServiceClient(conn).Query(ctx, req)
+	if err != nil {
+		fatal("audit query", err)
+	}
+	if out.json {
+		out.printJSON(resp)
+		return
+	}
+	rows := [][]string{{"TIME", "CALLER", "METHOD", "CODE", "REQUEST"}}
+	for _, entry := range resp.Entries {
+		rows = append(rows, []string{
+			entry.Time.AsTime().Local().Format(time.DateTime),
+			entry.Caller,
+			entry.Method,
+			entry.Code,
+			entry.RequestJson,
+		})
+	}
+	out.table(rows)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fullstorydev/grpcurl"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// connOptions holds the global authentication flags.
+type connOptions struct {
+	tokenFile string
+	tls       bool
+	caFile    string
+	certFile  string
+	keyFile   string
+}
+
+// connOptionsFromEnv seeds options from GOHOME_* variables; flags override them.
+func connOptionsFromEnv() connOptions {
+	return connOptions{
+		tokenFile: os.Getenv("GOHOME_TOKEN_FILE"),
+		tls:       os.Getenv("GOHOME_TLS") == "1",
+		caFile:    os.Getenv("GOHOME_TLS_CA_FILE"),
+		certFile:  os.Getenv("GOHOME_TLS_CERT_FILE"),
+		keyFile:   os.Getenv("GOHOME_TLS_KEY_FILE"),
+	}
+}
+
+func (o connOptions) useTLS() bool {
+	return o.tls || o.caFile != "" || o.certFile != ""
+}
+
+// token returns the bearer token from GOHOME_TOKEN or the token file.
+func (o connOptions) token() (string, error) {
+	if value := strings.TrimSpace(os.Getenv("GOHOME_TOKEN")); value != "" {
+		return value, nil
+	}
+	if o.tokenFile == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(o.tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("read token: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func (o connOptions) dial(ctx context.Context, addr string) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if o.useTLS() {
+		tlsCfg, err := grpcurl.ClientTLSConfig(false, o.caFile, o.certFile, o.keyFile)
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(tlsCfg)
+	}
+
+	var opts []grpc.DialOption
+	token, err := o.token()
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(token)))
+	}
+	return grpcurl.BlockingDial(ctx, "tcp", addr, creds, opts...)
+}
+
+// bearerToken sends the token as gRPC authorization metadata. Plaintext is
+// allowed because deployments typically run over an encrypted tailnet.
+type bearerToken string
+
+func (b bearerToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(b)}, nil
+}
+
+func (b bearerToken) RequireTransportSecurity() bool {
+	return false
+}
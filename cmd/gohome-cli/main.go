@@ -13,13 +13,15 @@ import (
 
 	"github.com/fullstorydev/grpcurl"
 	"github.com/jhump/protoreflect/grpcreflect"
-	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/joshp123/gohome/internal/config"
 	registryv1 "github.com/joshp123/gohome/proto/gen/registry/v1"
 	"google.golang.org/grpc"
 )
 
+// globalOpts holds the connection flags parsed before the command.
+var globalOpts connOptions
+
 func main() {
 	args := os.Args[1:]
 	if len(args) == 0 {
@@ -33,14 +35,37 @@ func main() {
 	}
 
 	jsonOutput := false
+	opts := connOptionsFromEnv()
 	for len(args) > 0 {
-		if args[0] == "--json" {
+		switch args[0] {
+		case "--json":
 			jsonOutput = true
 			args = args[1:]
 			continue
+		case "--tls":
+			opts.tls = true
+			args = args[1:]
+			continue
+		case "--token-file", "--ca-file", "--cert-file", "--key-file":
+			if len(args) < 2 {
+				fatal(args[0], fmt.Errorf("missing value"))
+			}
+			switch args[0] {
+			case "--token-file":
+				opts.tokenFile = args[1]
+			case "--ca-file":
+				opts.caFile = args[1]
+			case "--cert-file":
+				opts.certFile = args[1]
+			case "--key-file":
+				opts.keyFile = args[1]
+			}
+			args = args[2:]
+			continue
 		}
 		break
 	}
+	globalOpts = opts
 
 	if len(args) == 0 {
 		usage()
@@ -51,7 +76,7 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	conn, err := grpcurl.BlockingDial(ctx, "tcp", addr, insecure.NewCredentials())
+	conn, err := opts.dial(ctx, addr)
 	if err != nil {
 		fatal("dial", err)
 	}
@@ -72,6 +97,12 @@ func main() {
 		roborockCmd(ctx, conn, args[1:], jsonOutput)
 	case "airgradient":
 		airgradientCmd(ctx, conn, args[1:], jsonOutput)
+	case "audit":
+		auditCmd(ctx, conn, args[1:], jsonOutput)
+	case "rate":
+		rateCmd(ctx, conn, args[1:], jsonOutput)
+	case "oauth":
+		oauthCmd(ctx, conn, args[1:], jsonOutput)
 	default:
 		usage()
 		os.Exit(2)
@@ -245,7 +276,11 @@ func resolveHTTPBase() string {
 			host = "gohome"
 		}
 	}
-	return fmt.Sprintf("http://%s:%s", host, port)
+	scheme := "http"
+	if globalOpts.useTLS() {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s:%s", scheme, host, port)
 }
 
 func configSearchPaths() []string {
@@ -279,6 +314,9 @@ func usage() {
 	fmt.Println("  tado <zones|set>")
 	fmt.Println("  roborock <status|rooms|clean|dock|locate|map>")
 	fmt.Println("  airgradient <current|snapshot|metrics|config>")
+	fmt.Println("  audit [--caller name] [--method text] [--since 24h] [--limit N] [--failures]")
+	fmt.Println("  rate [provider]")
+	fmt.Println("  oauth <status|refresh|revoke>")
 	fmt.Println("  plugins list")
 	fmt.Println("  plugins describe <plugin_id>")
 	fmt.Println("  services")
@@ -286,7 +324,12 @@ func usage() {
 	fmt.Println("  call <service/method> --data '{}' (or pipe JSON via stdin)")
 	fmt.Println("")
 	fmt.Println("Global flags:")
-	fmt.Println("  --json  output raw JSON")
+	fmt.Println("  --json               output raw JSON")
+	fmt.Println("  --token-file <path>  bearer token file (or GOHOME_TOKEN / GOHOME_TOKEN_FILE)")
+	fmt.Println("  --tls                dial with TLS (or GOHOME_TLS=1)")
+	fmt.Println("  --ca-file <path>     server CA (or GOHOME_TLS_CA_FILE)")
+	fmt.Println("  --cert-file <path>   client certificate for mTLS (or GOHOME_TLS_CERT_FILE)")
+	fmt.Println("  --key-file <path>    client key for mTLS (or GOHOME_TLS_KEY_FILE)")
 }
 
 func fatal(action string, err error) {
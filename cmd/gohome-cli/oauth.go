@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	oauthv1 "github.com/joshp123/gohome/proto/gen/oauth/v1"
+	"google.golang.org/grpc"
+)
+
+func oauthCmd(ctx context.Context, conn *grpc.ClientConn, args []string, jsonOutput bool) {
+	out := outputMode{json: jsonOutput}
+	if len(args) == 0 {
+		oauthUsage()
+		os.Exit(2)
+	}
+
+	client := oauthv1.NewOAuthServiceClient(conn)
+	switch args[0] {
+	case "status":
+		resp, err := client.ListProviders(ctx, &oauthv1.ListProvidersRequest{})
+		if err != nil {
+			fatal("oauth status", err)
+		}
+		if out.json {
+			out.printJSON(resp)
+			return
+		}
+		oauthTable(out, resp.Providers)
+	case "refresh":
+		if len(args) < 2 {
+			fatal("oauth refresh", fmt.Errorf("usage: gohome-cli oauth refresh <provider>[/account]"))
+		}
+		provider, account, _ := strings.Cut(args[1], "/")
+		resp, err := client.Refresh(ctx, &oauthv1.RefreshRequest{Provider: provider, Account: account})
+		if err != nil {
+			fatal("oauth refresh", err)
+		}
+		if out.json {
+			out.printJSON(resp)
+			return
+		}
+		oauthTable(out, []*oauthv1.ProviderStatus{resp.Provider})
+	case "revoke":
+		if len(args) < 2 {
+			fatal("oauth revoke", fmt.Errorf("usage: gohome-cli oauth revoke <provider>[/account]"))
+		}
+		provider, account, _ := strings.Cut(args[1], "/")
+		resp, err := client.Revoke(ctx, &oauthv1.RevokeRequest{Provider: provider, Account: account})
+		if err != nil {
+			fatal("oauth revoke", err)
+		}
+		if out.json {
+			out.printJSON(resp)
+			return
+		}
+		if resp.RevokedRemotely {
+			fmt.Printf("revoked %s with the provider; local and blob state wiped\n", args[1])
+		} else {
+			fmt.Printf("%s has no revocation endpoint; local and blob state wiped\n", args[1])
+		}
+	default:
+		oauthUsage()
+		os.Exit(2)
+	}
+}
+
+func oauthTable(out outputMode, providers []*oauthv1.ProviderStatus) {
+	rows := [][]string{{"PROVIDER", "ACCOUNT", "FLOW", "TOKEN EXPIRES", "LAST REFRESH", "STATE", "ERROR"}}
+	for _, p := range providers {
+		rows = append(rows, []string{
+			p.Provider,
+			p.Account,
+			p.Flow,
+			rateTime(p.AccessTokenExpiry),
+			oauthLastRefresh(p),
+			oauthStateSync(p),
+			oauthError(p),
+		})
+	}
+	out.table(rows)
+}
+
+func oauthLastRefresh(p *oauthv1.ProviderStatus) string {
+	if p.LastRefresh == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%s)", rateTime(p.LastRefresh), p.LastRefreshOutcome)
+}
+
+func oauthStateSync(p *oauthv1.ProviderStatus) string {
+	if p.Revoked {
+		return "revoked"
+	}
+	if p.ReauthRequired {
+		return "reauth required"
+	}
+	if p.StateSyncDetail != "" {
+		return fmt.Sprintf("%s: %s", p.StateSync, p.StateSyncDetail)
+	}
+	return p.StateSync
+}
+
+func oauthError(p *oauthv1.ProviderStatus) string {
+	if p.LastError == "" {
+		return "-"
+	}
+	return p.LastError
+}
+
+func oauthUsage() {
+	fmt.Println("gohome-cli oauth <command>")
+	fmt.Println("")
+	fmt.Println("Commands:")
+	fmt.Println("  status")
+	fmt.Println("  refresh <provider>[/account]")
+	fmt.Println("  revoke <provider>[/account]")
+}
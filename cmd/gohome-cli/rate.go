@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	ratev1 "github.com/joshp123/gohome/proto/gen/rate/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func rateCmd(ctx context.Context, conn *grpc.ClientConn, args []string, jsonOutput bool) {
+	out := outputMode{json: jsonOutput}
+	client := ratev1.NewRateServiceClient(conn)
+
+	var providers []*ratev1.ProviderStatus
+	if len(args) > 0 {
+		resp, err := client.GetProvider(ctx, &ratev1.GetProviderRequest{Provider: args[0]})
+		if err != nil {
+			fatal("rate provider", err)
+		}
+		if out.json {
+			out.printJSON(resp)
+			return
+		}
+		providers = append(providers, resp.Provider)
+	} else {
+		resp, err := client.ListProviders(ctx, &ratev1.ListProvidersRequest{})
+		if err != nil {
+			fatal("rate providers", err)
+		}
+		if out.json {
+			out.printJSON(resp)
+			return
+		}
+		providers = resp.Providers
+	}
+
+	rows := [][]string{{"PROVIDER", "BUDGET", "COOLDOWN", "CIRCUIT", "LAST", "CACHE", "LAST DENIAL"}}
+	for _, p := range providers {
+		rows = append(rows, []string{
+			p.Provider,
+			rateBudget(p),
+			rateTime(p.CooldownUntil),
+			rateCircuit(p.Circuit),
+			rateLastStatus(p.LastStatus),
+			rateCache(p),
+			rateDenial(p.LastDenial),
+		})
+	}
+	out.table(rows)
+}
+
+func rateBudget(p *ratev1.ProviderStatus) string {
+	if p.Policy != "limits" {
+		return p.Policy
+	}
+	parts := make([]string, 0, len(p.Windows))
+	for _, w := range p.Windows {
+		parts = append(parts, fmt.Sprintf("%s %d/%d (%s)", w.Window, w.Remaining, w.Limit, w.Source))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func rateCircuit(c *ratev1.Circuit) string {
+	if c == nil || c.State == "" {
+		return "-"
+	}
+	if c.State == "open" {
+		return fmt.Sprintf("open until %s (%d failures: %s)", rateTime(c.RetryAt), c.ConsecutiveFailures, c.LastError)
+	}
+	return c.State
+}
+
+func rateCache(p *ratev1.ProviderStatus) string {
+	if p.CacheEntries == 0 && p.CacheHits+p.CacheMisses == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d entries, %.0f%% hits", p.CacheEntries, p.CacheHitRatio*100)
+}
+
+func rateLastStatus(code int32) string {
+	if code == 0 {
+		return "-"
+	}
+	return fmt.Sprint(code)
+}
+
+// rateDenial renders e.g. "2026-01-02 18:04:11: budget for background
+// requests until 2026-01-03 00:00:00".
+func rateDenial(d *ratev1.Denial) string {
+	if d == nil {
+		return "-"
+	}
+	text := fmt.Sprintf("%s: %s for %s requests", rateTime(d.Time), d.Reason, d.Priority)
+	if d.RetryAt != nil {
+		text += " until " + rateTime(d.RetryAt)
+	}
+	if d.ServedFromCache {
+		text += " (served from cache)"
+	}
+	return text
+}
+
+func rateTime(ts *timestamppb.Timestamp) string {
+	if ts == nil {
+		return "-"
+	}
+	return ts.AsTime().Local().Format(time.DateTime)
+}
@@ -257,6 +257,9 @@ func roborockCmd(ctx context.Context, conn *grpc.ClientConn, args []string, json
 			query.Set("path", "false")
 		}
 		fmt.Printf("%s/roborock/map.png?%s\n", endpoint, query.Encode())
+		if token, _ := globalOpts.token(); token != "" {
+			fmt.Fprintln(os.Stderr, "note: fetching the map requires an Authorization: Bearer header")
+		}
 	default:
 		roborockUsage()
 		os.Exit(2)
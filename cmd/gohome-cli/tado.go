@@ -29,14 +29,23 @@ func tadoCmd(ctx context.Context, conn *grpc.ClientConn, args []string, jsonOutp
 			out.printJSON(resp)
 			return
 		}
-		rows := [][]string{{"ZONE", "ID"}}
+		multi := multipleTadoAccounts(resp.Zones)
+		header := []string{"ZONE", "ID"}
+		if multi {
+			header = append(header, "ACCOUNT")
+		}
+		rows := [][]string{header}
 		for _, zone := range resp.Zones {
-			rows = append(rows, []string{zone.Name, zone.Id})
+			row := []string{zone.Name, zone.Id}
+			if multi {
+				row = append(row, zone.Account)
+			}
+			rows = append(rows, row)
 		}
 		out.table(rows)
 	case "set":
 		if len(args) < 3 {
-			fatal("tado set", fmt.Errorf("usage: gohome-cli tado set <zone> <temp>"))
+			fatal("tado set", fmt.Errorf("usage: gohome-cli tado set [account/]<zone> <temp>"))
 		}
 		zoneName := args[1]
 		temp, err := strconv.ParseFloat(args[2], 64)
@@ -47,20 +56,17 @@ func tadoCmd(ctx context.Context, conn *grpc.ClientConn, args []string, jsonOutp
 		if err != nil {
 			fatal("tado list zones", err)
 		}
-		zoneMap := make(map[string]string)
-		for _, zone := range zones.Zones {
-			zoneMap[zone.Name] = zone.Id
-		}
-		zoneID, err := resolveNamedID("zone", zoneName, zoneMap)
+		target, err := resolveNamedID("zone", zoneName, tadoZoneOptions(zones.Zones))
 		if err != nil {
 			fatal("tado set", err)
 		}
-		_, err = client.SetTemperature(ctx, &tadov1.SetTemperatureRequest{ZoneId: zoneID, TemperatureCelsius: temp})
+		account, zoneID, _ := strings.Cut(target, "/")
+		_, err = client.SetTemperature(ctx, &tadov1.SetTemperatureRequest{ZoneId: zoneID, TemperatureCelsius: temp, Account: account})
 		if err != nil {
 			fatal("tado set", err)
 		}
 		if out.json {
-			out.printJSON(map[string]any{"zone": zoneName, "temperature_celsius": temp, "status": "ok"})
+			out.printJSON(map[string]any{"zone": zoneName, "account": account, "temperature_celsius": temp, "status": "ok"})
 			return
 		}
 		fmt.Printf("ok: %s -> %.1f°C\n", strings.ToLower(zoneName), temp)
@@ -75,5 +81,38 @@ func tadoUsage() {
 	fmt.Println("")
 	fmt.Println("Commands:")
 	fmt.Println("  zones")
-	fmt.Println("  set <zone> <temp>")
+	fmt.Println("  set [account/]<zone> <temp>")
+}
+
+func multipleTadoAccounts(zones []*tadov1.Zone) bool {
+	for _, zone := range zones {
+		if zone.Account != zones[0].Account {
+			return true
+		}
+	}
+	return false
+}
+
+// tadoZoneOptions maps zone labels to "account/id". With several accounts
+// every zone is reachable as account/zone, and by bare name when unique.
+func tadoZoneOptions(zones []*tadov1.Zone) map[string]string {
+	options := make(map[string]string)
+	if !multipleTadoAccounts(zones) {
+		for _, zone := range zones {
+			options[zone.Name] = zone.Account + "/" + zone.Id
+		}
+		return options
+	}
+	counts := make(map[string]int)
+	for _, zone := range zones {
+		counts[normalizeName(zone.Name)]++
+	}
+	for _, zone := range zones {
+		target := zone.Account + "/" + zone.Id
+		options[zone.Account+"/"+zone.Name] = target
+		if counts[normalizeName(zone.Name)] == 1 {
+			options[zone.Name] = target
+		}
+	}
+	return options
 }
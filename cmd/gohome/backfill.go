@@ -36,7 +36,7 @@ func backfillUsage() {
 	fmt.Println("gohome backfill <command> [args]")
 	fmt.Println("")
 	fmt.Println("Commands:")
-	fmt.Println("  tado --start YYYY-MM-DD --end YYYY-MM-DD [--zones name1,name2] [--config path]")
+	fmt.Println("  tado --start YYYY-MM-DD --end YYYY-MM-DD [--account name] [--zones name1,name2] [--config path]")
 	fmt.Println("  growatt [--max-weeks N] [--stop-after-empty-weeks N] [--chunk-delay 25s] [--rate-limit-backoff 2m] [--config path] [--import-url url]")
 }
 
@@ -45,6 +45,7 @@ func tadoBackfillCmd(args []string) {
 	startStr := flags.String("start", defaultTadoBackfillStart, "Backfill start date (YYYY-MM-DD)")
 	endStr := flags.String("end", time.Now().Format("2006-01-02"), "Backfill end date (YYYY-MM-DD)")
 	zoneFilter := flags.String("zones", "", "Optional comma-separated zone names to include (default: all)")
+	account := flags.String("account", "", "Tado account to backfill (default: the default account)")
 	configPath := flags.String("config", config.DefaultPath, "Path to config.pbtxt")
 	importURL := flags.String("import-url", tado.DefaultImportURL, "VictoriaMetrics import URL")
 	batchSize := flags.Int("batch-size", 5000, "Samples per import batch")
@@ -71,7 +72,11 @@ func tadoBackfillCmd(args []string) {
 		fatal("backfill tado", fmt.Errorf("tado config missing"))
 	}
 
-	runtimeCfg, err := tado.ConfigFromProto(cfg.Tado)
+	configs, err := tado.ConfigsFromProto(cfg.Tado)
+	if err != nil {
+		fatal("backfill tado", err)
+	}
+	runtimeCfg, err := tado.SelectAccount(configs, *account)
 	if err != nil {
 		fatal("backfill tado", err)
 	}
@@ -79,6 +84,9 @@ func tadoBackfillCmd(args []string) {
 	if err != nil {
 		fatal("backfill tado", err)
 	}
+	ctx := context.Background()
+	client.Start(ctx)
+	defer func() { _ = client.Stop(ctx) }()
 
 	var zones []string
 	if strings.TrimSpace(*zoneFilter) != "" {
@@ -99,7 +107,7 @@ func tadoBackfillCmd(args []string) {
 		Throttle:  *throttle,
 	}
 
-	if err := tado.Backfill(context.Background(), client, opts); err != nil {
+	if err := tado.Backfill(ctx, client, opts); err != nil {
 		fatal("backfill tado", err)
 	}
 }
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+
+	"github.com/joshp123/gohome/internal/config"
+	"github.com/joshp123/gohome/internal/plugins"
+	"github.com/joshp123/gohome/internal/server"
+)
+
+func configMain(args []string) {
+	if len(args) == 0 {
+		configUsage()
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "validate":
+		configValidateCmd(args[1:])
+	case "print-effective":
+		configPrintEffectiveCmd(args[1:])
+	case "enabled":
+		configEnabledCmd(args[1:])
+	default:
+		configUsage()
+		os.Exit(2)
+	}
+}
+
+func configUsage() {
+	fmt.Println("gohome config <command> [args]")
+	fmt.Println("")
+	fmt.Println("Commands:")
+	fmt.Println("  validate [--config path]")
+	fmt.Println("  print-effective [--config path] [--format textproto|json]")
+	fmt.Println("  enabled [--config path]")
+}
+
+// configValidateCmd reports every core and plugin config error, then exits
+// non-zero if there were any.
+func configValidateCmd(args []string) {
+	flags := flag.NewFlagSet("config validate", flag.ExitOnError)
+	configPath := flags.String("config", config.DefaultPath, "Path to config.pbtxt")
+	_ = flags.Parse(args)
+
+	cfg, err := config.Parse(*configPath)
+	if err != nil {
+		fatal("config validate", err)
+	}
+
+	errs := config.ValidateAll(cfg)
+	enabled := config.EnabledPlugins(cfg)
+	compiled := compiledSet()
+	for _, id := range sortedKeys(enabled) {
+		if !compiled[id] {
+			errs = append(errs, fmt.Errorf("%s: enabled in config but not compiled into this build", id))
+		}
+	}
+	errs = append(errs, plugins.CheckConfig(cfg, enabled)...)
+	if _, err := server.NewAuthorizer(cfg.GetCore().GetAuth()); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", *configPath, err)
+		}
+		os.Exit(1)
+	}
+	fmt.Printf("%s: ok\n", *configPath)
+}
+
+func configPrintEffectiveCmd(args []string) {
+	flags := flag.NewFlagSet("config print-effective", flag.ExitOnError)
+	configPath := flags.String("config", config.DefaultPath, "Path to config.pbtxt")
+	format := flags.String("format", "textproto", "Output format: textproto or json")
+	_ = flags.Parse(args)
+
+	cfg, err := config.Parse(*configPath)
+	if err != nil {
+		fatal("config print-effective", err)
+	}
+
+	var out []byte
+	switch *format {
+	case "textproto":
+		out, err = prototext.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(cfg)
+	case "json":
+		out, err = protojson.MarshalOptions{Multiline: true, Indent: "  ", UseProtoNames: true}.Marshal(cfg)
+	default:
+		err = fmt.Errorf("unknown format %q (want textproto or json)", *format)
+	}
+	if err != nil {
+		fatal("config print-effective", err)
+	}
+	os.Stdout.Write(out)
+	if len(out) > 0 && out[len(out)-1] != '\n' {
+		fmt.Println()
+	}
+}
+
+// configEnabledCmd lists configured and compiled plugins and whether the
+// daemon would activate each one.
+func configEnabledCmd(args []string) {
+	flags := flag.NewFlagSet("config enabled", flag.ExitOnError)
+	configPath := flags.String("config", config.DefaultPath, "Path to config.pbtxt")
+	_ = flags.Parse(args)
+
+	cfg, err := config.Parse(*configPath)
+	if err != nil {
+		fatal("config enabled", err)
+	}
+
+	enabled := config.EnabledPlugins(cfg)
+	compiled := compiledSet()
+
+	ids := make(map[string]bool, len(enabled)+len(compiled))
+	for id := range enabled {
+		ids[id] = true
+	}
+	for id := range compiled {
+		ids[id] = true
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PLUGIN\tSTATE")
+	for _, id := range sortedKeys(ids) {
+		state := "active"
+		switch {
+		case enabled[id] && !compiled[id]:
+			state = "configured, not compiled"
+		case !enabled[id]:
+			state = "compiled, not configured"
+		}
+		fmt.Fprintf(w, "%s\t%s\n", id, state)
+	}
+	_ = w.Flush()
+}
+
+func compiledSet() map[string]bool {
+	out := make(map[string]bool)
+	for _, id := range plugins.IDs() {
+		out[id] = true
+	}
+	return out
+}
+
+func sortedKeys(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for key := range set {
+		out = append(out, key)
+	}
+	sort.Strings(out)
+	return out
+}
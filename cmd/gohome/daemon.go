@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/joshp123/gohome/internal/auth"
+	"github.com/joshp123/gohome/internal/config"
+	"github.com/joshp123/gohome/internal/core"
+	"github.com/joshp123/gohome/internal/plugins"
+	"github.com/joshp123/gohome/internal/server"
+	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+)
+
+// daemon holds the running server state that a config reload can change.
+type daemon struct {
+	configPath string
+	runCtx     context.Context
+
+	grpc       *server.GRPCServer
+	tokens     *auth.Tokens
+	authz      *server.Authorizer
+	grpcHealth *core.GRPCHealth
+	http       *server.HTTPServer
+	metrics    *prometheus.Registry
+	registry   *core.RegistryService
+	health     *core.HealthBroadcaster
+	status     *core.CorePlugin
+
+	mu        sync.Mutex
+	cfg       *configv1.Config
+	pluginCfg map[string]*configv1.Config // config each active plugin last applied
+	active    []core.Plugin
+	served    map[string]string // gRPC service -> plugin ID registered at startup
+	stale     map[string]bool   // plugin IDs whose startup gRPC registration was stopped
+}
+
+// listed returns the plugins shown by the registry: the core status entry first.
+func (d *daemon) listed() []core.Plugin {
+	return append([]core.Plugin{d.status}, d.active...)
+}
+
+// httpHandler builds the HTTP routes for the current plugin set.
+func (d *daemon) httpHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", server.IndexHandler(d.listed()))
+	mux.HandleFunc("/health", server.HealthHandler)
+	mux.Handle("/metrics", server.MetricsHandler(d.metrics))
+	mux.Handle("/dashboards/", server.DashboardsHandler(core.DashboardsMap(d.active)))
+	mux.Handle(server.GatewayPrefix, d.grpc.Gateway())
+	mux.Handle(server.GatewayPrefix+"/", d.grpc.Gateway())
+	for _, plugin := range d.active {
+		if registrant, ok := plugin.(core.HTTPRegistrant); ok {
+			registrant.RegisterHTTP(mux)
+		}
+	}
+	return mux
+}
+
+// reload re-reads the config file and applies it. Failures leave the previous
+// config running and are reported through the core plugin's health.
+func (d *daemon) reload() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	notes, err := d.applyConfig()
+	switch {
+	case err != nil:
+		log.Printf("config reload failed: %v", err)
+		d.status.SetHealth(core.HealthDegraded, "config reload failed: "+err.Error())
+	case len(notes) > 0:
+		log.Printf("config reloaded: %s", strings.Join(notes, "; "))
+		d.status.SetHealth(core.HealthDegraded, "config reloaded; "+strings.Join(notes, "; "))
+	default:
+		log.Printf("config reloaded")
+		d.status.SetHealth(core.HealthHealthy, "")
+	}
+}
+
+func (d *daemon) applyConfig() ([]string, error) {
+	next, err := config.Load(d.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := config.EnabledPlugins(next)
+	compiled := compiledSet()
+	for id := range enabled {
+		if !compiled[id] {
+			return nil, fmt.Errorf("enabled plugin %q is not compiled into this build", id)
+		}
+	}
+
+	var notes []string
+	if next.Core.GrpcAddr != d.cfg.Core.GrpcAddr {
+		notes = append(notes, "core.grpc_addr change requires a restart")
+	}
+	if next.Core.HttpAddr != d.cfg.Core.HttpAddr {
+		notes = append(notes, "core.http_addr change requires a restart")
+	}
+	if !proto.Equal(next.Core.GetTls(), d.cfg.Core.GetTls()) {
+		notes = append(notes, "core.tls change requires a restart")
+	}
+	if !proto.Equal(next.Core.GetAudit(), d.cfg.Core.GetAudit()) {
+		notes = append(notes, "core.audit change requires a restart")
+	}
+	if !proto.Equal(next.Core.GetRateState(), d.cfg.Core.GetRateState()) {
+		notes = append(notes, "core.rate_state change requires a restart")
+	}
+
+	activeIDs := make(map[string]bool, len(d.active))
+	var kept, removed, changed []core.Plugin
+	for _, plugin := range d.active {
+		id := plugin.ID()
+		activeIDs[id] = true
+		if !enabled[id] {
+			removed = append(removed, plugin)
+			continue
+		}
+		kept = append(kept, plugin)
+		if config.PluginChanged(d.pluginCfg[id], next, id) {
+			changed = append(changed, plugin)
+		}
+	}
+
+	want := make(map[string]bool)
+	for id := range enabled {
+		if !activeIDs[id] {
+			want[id] = true
+		}
+	}
+	added := plugins.Build(next, want)
+	if err := core.ValidatePlugins(append(append([]core.Plugin{}, kept...), added...)); err != nil {
+		return nil, err
+	}
+	ordered, err := core.StartOrder(append(append([]core.Plugin{}, kept...), added...))
+	if err != nil {
+		return nil, err
+	}
+
+	// Token files are re-read on every reload so rotated secrets take effect.
+	// Policies go first so a new token never runs without its scopes.
+	if err := d.authz.Reload(next.Core.GetAuth()); err != nil {
+		return nil, err
+	}
+	if err := d.tokens.Reload(next.Core.GetAuth()); err != nil {
+		_ = d.authz.Reload(d.cfg.Core.GetAuth())
+		return nil, err
+	}
+
+	// Validation passed; from here on the new config is applied plugin by plugin.
+	var errs []error
+	for _, plugin := range changed {
+		id := plugin.ID()
+		reconfigurable, ok := plugin.(core.Reconfigurable)
+		if !ok {
+			notes = append(notes, id+" config changed; restart required")
+			continue
+		}
+		if err := reconfigurable.Reconfigure(next); err != nil {
+			errs = append(errs, fmt.Errorf("reconfigure %s: %w", id, err))
+			continue
+		}
+		d.pluginCfg[id] = next
+	}
+
+	if len(removed) > 0 {
+		stopCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		if err := core.StopPlugins(stopCtx, removed); err != nil {
+			errs = append(errs, err)
+		}
+		cancel()
+		core.UnregisterCollectors(d.metrics, removed)
+		if err := core.RemoveDashboards(d.cfg.Core.DashboardDir, removed); err != nil {
+			errs = append(errs, err)
+		}
+		for _, plugin := range removed {
+			delete(d.pluginCfg, plugin.ID())
+			d.stale[plugin.ID()] = true
+		}
+	}
+
+	if len(added) > 0 {
+		if err := core.StartPlugins(d.runCtx, added); err != nil {
+			errs = append(errs, err)
+			ordered = withoutPlugins(ordered, added)
+			added = nil
+		}
+		if err := core.RegisterCollectors(d.metrics, added); err != nil {
+			errs = append(errs, err)
+		}
+		for _, plugin := range added {
+			d.pluginCfg[plugin.ID()] = next
+			if len(plugin.Manifest().Services) > 0 {
+				notes = append(notes, plugin.ID()+" gRPC services require a restart")
+			}
+		}
+	}
+
+	dashboardTargets := added
+	if next.Core.DashboardDir != d.cfg.Core.DashboardDir {
+		dashboardTargets = ordered
+	}
+	if err := core.WriteDashboards(next.Core.DashboardDir, dashboardTargets); err != nil {
+		errs = append(errs, err)
+	}
+
+	d.cfg = next
+	d.active = ordered
+	d.registry.SetPlugins(d.listed())
+	d.health.SetPlugins(d.listed())
+	d.http.SetHandler(d.httpHandler())
+	d.grpc.SetUnavailable(d.unavailableServices())
+	d.grpcHealth.SetServices(d.servingServices())
+	d.grpcHealth.SetDegradedServing(degradedServing(next))
+
+	return notes, errors.Join(errs...)
+}
+
+// unavailableServices lists registered gRPC services whose plugin is no
+// longer the one serving them.
+func (d *daemon) unavailableServices() []string {
+	active := make(map[string]bool, len(d.active))
+	for _, plugin := range d.active {
+		active[plugin.ID()] = true
+	}
+	var out []string
+	for service, id := range d.served {
+		if !active[id] || d.stale[id] {
+			out = append(out, service)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// servingServices maps each active plugin to the gRPC services it still
+// backs, for grpc.health.v1.
+func (d *daemon) servingServices() map[string][]string {
+	active := make(map[string]bool, len(d.active))
+	for _, plugin := range d.active {
+		active[plugin.ID()] = true
+	}
+	out := make(map[string][]string)
+	for service, id := range d.served {
+		if active[id] && !d.stale[id] {
+			out[id] = append(out[id], service)
+		}
+	}
+	return out
+}
+
+// degradedServing reports whether DEGRADED plugins count as SERVING.
+func degradedServing(cfg *configv1.Config) bool {
+	return cfg.GetCore().GetGrpcHealthDegraded() != configv1.DegradedHealth_DEGRADED_HEALTH_NOT_SERVING
+}
+
+func withoutPlugins(plugins []core.Plugin, drop []core.Plugin) []core.Plugin {
+	skip := make(map[string]bool, len(drop))
+	for _, plugin := range drop {
+		skip[plugin.ID()] = true
+	}
+	out := make([]core.Plugin, 0, len(plugins))
+	for _, plugin := range plugins {
+		if !skip[plugin.ID()] {
+			out = append(out, plugin)
+		}
+	}
+	return out
+}
@@ -1,16 +1,27 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/joshp123/gohome/internal/audit"
+	"github.com/joshp123/gohome/internal/auth"
 	"github.com/joshp123/gohome/internal/config"
 	"github.com/joshp123/gohome/internal/core"
+	"github.com/joshp123/gohome/internal/oauth"
 	"github.com/joshp123/gohome/internal/plugins"
+	"github.com/joshp123/gohome/internal/rate"
 	"github.com/joshp123/gohome/internal/router"
 	"github.com/joshp123/gohome/internal/server"
+	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -20,6 +31,10 @@ var (
 	buildCommit  = "unknown"
 )
 
+// shutdownTimeout bounds each shutdown phase on SIGTERM: draining servers,
+// then stopping plugins and saving state.
+const shutdownTimeout = 30 * time.Second
+
 func main() {
 	if len(os.Args) > 1 && os.Args[1] == "oauth" {
 		oauthMain(os.Args[2:])
@@ -33,6 +48,10 @@ func main() {
 		backfillMain(os.Args[2:])
 		return
 	}
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		configMain(os.Args[2:])
+		return
+	}
 
 	flags := flag.NewFlagSet("gohome", flag.ExitOnError)
 	configPath := flags.String("config", config.DefaultPath, "Path to config.pbtxt")
@@ -54,21 +73,79 @@ func main() {
 		log.Fatalf("plugin enablement: %v", err)
 	}
 
-	activePlugins := core.FilterPlugins(compiledPlugins, enabled, false)
+	activePlugins, err := core.StartOrder(core.FilterPlugins(compiledPlugins, enabled, false))
+	if err != nil {
+		log.Fatalf("plugin order: %v", err)
+	}
 
 	if err := core.WriteDashboards(cfg.Core.DashboardDir, activePlugins); err != nil {
 		log.Fatalf("write dashboards: %v", err)
 	}
 
-	grpcServer, err := server.NewGRPCServer(cfg.Core.GrpcAddr)
+	tokens, err := auth.LoadTokens(cfg.Core.GetAuth())
+	if err != nil {
+		log.Fatalf("auth: %v", err)
+	}
+	tlsConfig, err := server.TLSConfig(cfg.Core.GetTls())
+	if err != nil {
+		log.Fatalf("tls: %v", err)
+	}
+	authz, err := server.NewAuthorizer(cfg.Core.GetAuth())
+	if err != nil {
+		log.Fatalf("authz: %v", err)
+	}
+	auditCfg := cfg.Core.GetAudit()
+	auditLog, err := audit.Open(auditCfg.GetPath(), int64(auditCfg.GetMaxSizeMb())<<20, int(auditCfg.GetMaxFiles()))
+	if err != nil {
+		log.Fatalf("audit: %v", err)
+	}
+	serverOpts := server.Options{Tokens: tokens, Authz: authz, Audit: auditLog, TLS: tlsConfig}
+
+	grpcServer, err := server.NewGRPCServer(cfg.Core.GrpcAddr, serverOpts)
 	if err != nil {
 		log.Fatalf("grpc listen: %v", err)
 	}
 
-	router.RegisterPlugins(grpcServer.Server, activePlugins)
+	runCtx, stopRun := context.WithCancel(context.Background())
+	defer stopRun()
+
+	d := &daemon{
+		configPath: *configPath,
+		runCtx:     runCtx,
+		grpc:       grpcServer,
+		tokens:     tokens,
+		authz:      authz,
+		status:     core.NewCorePlugin(buildVersion),
+		cfg:        cfg,
+		pluginCfg:  make(map[string]*configv1.Config, len(activePlugins)),
+		active:     activePlugins,
+		served:     make(map[string]string),
+		stale:      make(map[string]bool),
+	}
+	for _, plugin := range activePlugins {
+		d.pluginCfg[plugin.ID()] = cfg
+		for _, service := range plugin.Manifest().Services {
+			d.served[service] = plugin.ID()
+		}
+	}
+
+	d.health = core.NewHealthBroadcaster(d.listed(), core.DefaultHealthPollInterval)
+	go d.health.Run(runCtx)
+
+	d.registry = router.RegisterPlugins(grpcServer.Server, d.listed(), d.health)
+
+	audit.RegisterAuditService(grpcServer.Server, auditLog)
+	rate.RegisterRateService(grpcServer.Server)
+	oauth.RegisterOAuthService(grpcServer.Server)
+
+	d.grpcHealth = core.NewGRPCHealth(degradedServing(cfg))
+	d.grpcHealth.SetServices(d.servingServices())
+	d.grpcHealth.Register(grpcServer.Server)
+	go d.grpcHealth.Run(runCtx, d.health)
 
-	metricsRegistry := core.MetricsRegistry(activePlugins)
-	metricsRegistry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+	d.metrics = core.MetricsRegistry(activePlugins)
+	d.metrics.MustRegister(server.MetricsCollectors()...)
+	d.metrics.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
 		Name: "gohome_build_info",
 		Help: "Build information",
 		ConstLabels: prometheus.Labels{
@@ -77,25 +154,105 @@ func main() {
 		},
 	}, func() float64 { return 1 }))
 
-	httpMux := http.NewServeMux()
-	httpMux.HandleFunc("/health", server.HealthHandler)
-	httpMux.Handle("/metrics", server.MetricsHandler(metricsRegistry))
-	httpMux.Handle("/dashboards/", server.DashboardsHandler(core.DashboardsMap(activePlugins)))
-	for _, plugin := range activePlugins {
-		if registrant, ok := plugin.(core.HTTPRegistrant); ok {
-			registrant.RegisterHTTP(httpMux)
+	httpServer := server.NewHTTPServer(cfg.Core.HttpAddr, d.httpHandler(), serverOpts)
+	d.http = httpServer
+
+	if rs := cfg.Core.GetRateState(); rs != nil {
+		store, err := rateStore(cfg)
+		if err != nil {
+			log.Fatalf("rate state: %v", err)
 		}
+		rate.EnablePersistence(runCtx, store)
+		go rate.RunPersistence(runCtx, time.Duration(rs.SaveIntervalSeconds)*time.Second)
+	}
+
+	if err := core.StartPlugins(runCtx, activePlugins); err != nil {
+		log.Fatalf("plugin start: %v", err)
 	}
 
-	httpServer := server.NewHTTPServer(cfg.Core.HttpAddr, httpMux)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
+	serveErr := make(chan error, 2)
 	go func() {
-		if err := httpServer.ListenAndServe(); err != nil {
-			log.Fatalf("http serve: %v", err)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- fmt.Errorf("http serve: %w", err)
 		}
 	}()
+	go func() {
+		if err := grpcServer.Serve(); err != nil {
+			serveErr <- fmt.Errorf("grpc serve: %w", err)
+		}
+	}()
+
+	exitCode := 0
+wait:
+	for {
+		select {
+		case sig := <-signals:
+			if sig == syscall.SIGHUP {
+				log.Printf("received %s, reloading %s", sig, *configPath)
+				d.reload()
+				continue
+			}
+			log.Printf("received %s, shutting down", sig)
+			break wait
+		case err := <-serveErr:
+			log.Printf("%v; shutting down", err)
+			exitCode = 1
+			break wait
+		}
+	}
+	signal.Stop(signals)
+
+	// End health watch streams first; GracefulStop waits for them.
+	d.grpcHealth.Shutdown()
+	d.health.Close()
+	d.mu.Lock()
+	stopping := d.active
+	d.mu.Unlock()
+	if err := shutdown(grpcServer, httpServer, stopping); err != nil {
+		log.Printf("shutdown: %v", err)
+		exitCode = 1
+	}
+	if err := auditLog.Close(); err != nil {
+		log.Printf("audit close: %v", err)
+	}
+	stopRun()
+	os.Exit(exitCode)
+}
+
+// shutdown drains gRPC and HTTP traffic, then stops plugins in reverse start
+// order and saves rate state. Stopping gets its own timeout so a slow drain
+// cannot leave plugins unstopped or state unsaved.
+func shutdown(grpcServer *server.GRPCServer, httpServer *server.HTTPServer, plugins []core.Plugin) error {
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelDrain()
+
+	var errs []error
+	if err := grpcServer.Shutdown(drainCtx); err != nil {
+		errs = append(errs, fmt.Errorf("grpc: %w", err))
+	}
+	if err := httpServer.Shutdown(drainCtx); err != nil {
+		errs = append(errs, fmt.Errorf("http: %w", err))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := core.StopPlugins(ctx, plugins); err != nil {
+		errs = append(errs, err)
+	}
+	if err := rate.SaveAll(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("rate state: %w", err))
+	}
+	return errors.Join(errs...)
+}
 
-	if err := grpcServer.Serve(); err != nil {
-		log.Fatalf("grpc serve: %v", err)
+// rateStore builds the persistence backend for core.rate_state.
+func rateStore(cfg *configv1.Config) (oauth.BlobStore, error) {
+	rs := cfg.Core.GetRateState()
+	if rs.GetBackend() == configv1.RateStateBackend_RATE_STATE_BACKEND_BLOB {
+		return oauth.NewBlobStore(cfg.GetOauth())
 	}
+	return rate.NewFileStore(rs.GetDir()), nil
 }
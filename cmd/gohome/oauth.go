@@ -3,15 +3,11 @@ package main
 import (
 	"bufio"
 	"context"
-	"crypto/rand"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
-	"net"
-	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
@@ -25,8 +21,9 @@ import (
 	"github.com/joshp123/gohome/internal/oauth"
 	"github.com/joshp123/gohome/internal/oauthflow"
 	"github.com/joshp123/gohome/internal/plugins"
+	"github.com/joshp123/gohome/internal/secrets"
+	"github.com/joshp123/gohome/internal/sops"
 	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
-	"golang.org/x/oauth2"
 )
 
 func oauthMain(args []string) {
@@ -42,6 +39,8 @@ func oauthMain(args []string) {
 		deviceCmd(args[1:])
 	case "persist":
 		persistCmd(args[1:])
+	case "rekey":
+		rekeyCmd(args[1:])
 	default:
 		oauthUsage()
 		os.Exit(2)
@@ -52,14 +51,18 @@ func oauthUsage() {
 	fmt.Println("gohome oauth <command> [args]")
 	fmt.Println("")
 	fmt.Println("Commands:")
-	fmt.Println("  auth-code --provider <id> --redirect-url <url> [--config <path>] [--no-open]")
-	fmt.Println("  device --provider <id> [--config <path>] [--no-open]")
-	fmt.Println("  persist --provider <id> --state <path> [--config <path>]")
+	fmt.Println("  auth-code --provider <id> [--account <name>] --redirect-url <url> [--config <path>] [--no-open]")
+	fmt.Println("  device --provider <id> [--account <name>] [--config <path>] [--no-open]")
+	fmt.Println("  persist --provider <id> [--account <name>] --state <path> [--config <path>]")
+	fmt.Println("  rekey --new-key-file <path> [--config <path>]")
+	fmt.Println("")
+	fmt.Println("auth-code, device and persist write the bootstrap secret with --persist agenix (default), sops or none.")
 }
 
 func authCodeCmd(args []string) {
 	flags := flag.NewFlagSet("auth-code", flag.ExitOnError)
 	provider := flags.String("provider", "", "OAuth provider ID")
+	account := flags.String("account", "", "Named account (default: the provider's default account)")
 	redirectURL := flags.String("redirect-url", "", "Redirect URL")
 	bootstrapFile := flags.String("bootstrap-file", "", "Override bootstrap file path")
 	configPath := flags.String("config", config.DefaultPath, "Path to config.pbtxt")
@@ -69,13 +72,14 @@ func authCodeCmd(args []string) {
 	cleanup := flags.Bool("cleanup", false, "Remove temp state file after successful persist")
 	jsonOut := flags.Bool("json", false, "Output JSON to stdout")
 	printToken := flags.Bool("print-token", false, "Include refresh token in output")
-	persistAgenix := flags.Bool("persist-agenix", true, "Persist bootstrap secret via agenix")
+	persist := addPersistFlags(flags)
 	timeout := flags.Duration("timeout", 5*time.Minute, "Timeout for auth flow")
-	agenixRepo := flags.String("agenix-repo", defaultAgenixRepo(), "Path to nix-secrets repo")
-	agenixSecret := flags.String("agenix-secret", "", "Override agenix secret name")
-	agenixRecipients := flags.String("agenix-recipients", "", "Space-separated recipient override")
 	skipBlob := flags.Bool("skip-blob", false, "Skip blob storage persistence")
 	_ = flags.Parse(args)
+	persistOpts, err := persist.options()
+	if err != nil {
+		fatal("oauth", err)
+	}
 
 	if *provider == "" || *redirectURL == "" {
 		oauthUsage()
@@ -87,7 +91,7 @@ func authCodeCmd(args []string) {
 		fatal("oauth", err)
 	}
 
-	decl, err := lookupDeclaration(cfg, *provider)
+	decl, err := lookupDeclaration(cfg, *provider, *account)
 	if err != nil {
 		fatal("oauth", err)
 	}
@@ -104,7 +108,7 @@ func authCodeCmd(args []string) {
 		fatal("oauth", fmt.Errorf("provider %q missing scope", decl.Provider))
 	}
 
-	bootstrapPath, err := resolveBootstrapPath(cfg, *provider, *bootstrapFile)
+	bootstrapPath, err := resolveBootstrapPath(cfg, *provider, *account, *bootstrapFile)
 	if err != nil {
 		fatal("oauth", err)
 	}
@@ -113,23 +117,12 @@ func authCodeCmd(args []string) {
 		fatal("oauth", err)
 	}
 
-	conf := &oauth2.Config{
-		ClientID:     bootstrap.ClientID,
-		ClientSecret: bootstrap.ClientSecret,
-		Endpoint: oauth2.Endpoint{
-			AuthURL:  decl.AuthorizeURL,
-			TokenURL: decl.TokenURL,
-		},
-		RedirectURL: *redirectURL,
-		Scopes:      strings.Fields(decl.Scope),
-	}
-
-	state, err := randomState(16)
+	session, err := oauthflow.NewAuthCodeSession(decl, bootstrap, *redirectURL)
 	if err != nil {
 		fatal("oauth", err)
 	}
 
-	authURL := conf.AuthCodeURL(state, oauth2.AccessTypeOffline)
+	authURL := session.AuthURL()
 	printAuthPrompt(*jsonOut, "Open this URL to authorize:", authURL, "")
 
 	if !*noOpen {
@@ -139,31 +132,25 @@ func authCodeCmd(args []string) {
 	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
 
-	code, err := waitForAuthCode(ctx, *redirectURL, state, *jsonOut)
+	code, err := waitForAuthCode(ctx, session, *redirectURL, *jsonOut)
 	if err != nil {
 		fatal("oauth", err)
 	}
 
-	token, err := conf.Exchange(ctx, code)
+	token, err := session.Exchange(ctx, code)
 	if err != nil {
 		fatal("oauth", err)
 	}
-	if token.RefreshToken == "" {
-		fatal("oauth", fmt.Errorf("no refresh_token returned; check scope and redirect URL"))
-	}
 
 	output, err := persistOAuthState(ctx, cfg, decl, bootstrap, token.RefreshToken, oauthRunOptions{
-		flow:             "auth-code",
-		jsonOut:          *jsonOut,
-		printToken:       *printToken,
-		stateOut:         *stateOut,
-		statePath:        *statePath,
-		cleanup:          *cleanup,
-		persistAgenix:    *persistAgenix,
-		agenixRepo:       *agenixRepo,
-		agenixSecret:     *agenixSecret,
-		agenixRecipients: parseRecipients(*agenixRecipients),
-		skipBlob:         *skipBlob,
+		flow:       "auth-code",
+		jsonOut:    *jsonOut,
+		printToken: *printToken,
+		stateOut:   *stateOut,
+		statePath:  *statePath,
+		cleanup:    *cleanup,
+		persist:    persistOpts,
+		skipBlob:   *skipBlob,
 	})
 	if err != nil {
 		fatal("oauth", err)
@@ -175,6 +162,7 @@ func authCodeCmd(args []string) {
 func deviceCmd(args []string) {
 	flags := flag.NewFlagSet("device", flag.ExitOnError)
 	provider := flags.String("provider", "", "OAuth provider ID")
+	account := flags.String("account", "", "Named account (default: the provider's default account)")
 	bootstrapFile := flags.String("bootstrap-file", "", "Override bootstrap file path")
 	configPath := flags.String("config", config.DefaultPath, "Path to config.pbtxt")
 	noOpen := flags.Bool("no-open", false, "Do not open the browser automatically")
@@ -183,13 +171,14 @@ func deviceCmd(args []string) {
 	cleanup := flags.Bool("cleanup", false, "Remove temp state file after successful persist")
 	jsonOut := flags.Bool("json", false, "Output JSON to stdout")
 	printToken := flags.Bool("print-token", false, "Include refresh token in output")
-	persistAgenix := flags.Bool("persist-agenix", true, "Persist bootstrap secret via agenix")
+	persist := addPersistFlags(flags)
 	timeout := flags.Duration("timeout", 5*time.Minute, "Timeout for device flow")
-	agenixRepo := flags.String("agenix-repo", defaultAgenixRepo(), "Path to nix-secrets repo")
-	agenixSecret := flags.String("agenix-secret", "", "Override agenix secret name")
-	agenixRecipients := flags.String("agenix-recipients", "", "Space-separated recipient override")
 	skipBlob := flags.Bool("skip-blob", false, "Skip blob storage persistence")
 	_ = flags.Parse(args)
+	persistOpts, err := persist.options()
+	if err != nil {
+		fatal("oauth", err)
+	}
 
 	if *provider == "" {
 		oauthUsage()
@@ -201,7 +190,7 @@ func deviceCmd(args []string) {
 		fatal("oauth", err)
 	}
 
-	decl, err := lookupDeclaration(cfg, *provider)
+	decl, err := lookupDeclaration(cfg, *provider, *account)
 	if err != nil {
 		fatal("oauth", err)
 	}
@@ -215,7 +204,7 @@ func deviceCmd(args []string) {
 		fatal("oauth", fmt.Errorf("provider %q missing scope", decl.Provider))
 	}
 
-	bootstrapPath, err := resolveBootstrapPath(cfg, *provider, *bootstrapFile)
+	bootstrapPath, err := resolveBootstrapPath(cfg, *provider, *account, *bootstrapFile)
 	if err != nil {
 		fatal("oauth", err)
 	}
@@ -227,15 +216,12 @@ func deviceCmd(args []string) {
 	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
 
-	authResp, err := deviceAuthorize(ctx, decl.DeviceAuthURL, bootstrap.ClientID, decl.Scope)
+	authResp, err := oauthflow.DeviceAuthorize(ctx, decl, bootstrap)
 	if err != nil {
 		fatal("oauth", err)
 	}
 
-	verifyURL := authResp.VerificationURIComplete
-	if verifyURL == "" {
-		verifyURL = authResp.VerificationURI
-	}
+	verifyURL := authResp.VerifyURL()
 
 	lines := []string{"Open this URL to authorize:", verifyURL}
 	if authResp.UserCode != "" {
@@ -248,7 +234,7 @@ func deviceCmd(args []string) {
 		_ = openBrowser(verifyURL)
 	}
 
-	token, err := pollDeviceToken(ctx, decl.DeviceTokenURL, bootstrap, authResp)
+	token, err := oauthflow.PollDeviceToken(ctx, decl, bootstrap, authResp)
 	if err != nil {
 		fatal("oauth", err)
 	}
@@ -257,17 +243,14 @@ func deviceCmd(args []string) {
 	}
 
 	output, err := persistOAuthState(ctx, cfg, decl, bootstrap, token.RefreshToken, oauthRunOptions{
-		flow:             "device",
-		jsonOut:          *jsonOut,
-		printToken:       *printToken,
-		stateOut:         *stateOut,
-		statePath:        *statePath,
-		cleanup:          *cleanup,
-		persistAgenix:    *persistAgenix,
-		agenixRepo:       *agenixRepo,
-		agenixSecret:     *agenixSecret,
-		agenixRecipients: parseRecipients(*agenixRecipients),
-		skipBlob:         *skipBlob,
+		flow:       "device",
+		jsonOut:    *jsonOut,
+		printToken: *printToken,
+		stateOut:   *stateOut,
+		statePath:  *statePath,
+		cleanup:    *cleanup,
+		persist:    persistOpts,
+		skipBlob:   *skipBlob,
 	})
 	if err != nil {
 		fatal("oauth", err)
@@ -283,17 +266,19 @@ func deviceCmd(args []string) {
 func persistCmd(args []string) {
 	flags := flag.NewFlagSet("persist", flag.ExitOnError)
 	provider := flags.String("provider", "", "OAuth provider ID")
+	account := flags.String("account", "", "Named account (default: the provider's default account)")
 	statePath := flags.String("state", "", "Path to OAuth state file")
 	configPath := flags.String("config", config.DefaultPath, "Path to config.pbtxt")
 	cleanup := flags.Bool("cleanup", false, "Remove temp state file after successful persist")
 	jsonOut := flags.Bool("json", false, "Output JSON to stdout")
 	printToken := flags.Bool("print-token", false, "Include refresh token in output")
-	persistAgenix := flags.Bool("persist-agenix", true, "Persist bootstrap secret via agenix")
-	agenixRepo := flags.String("agenix-repo", defaultAgenixRepo(), "Path to nix-secrets repo")
-	agenixSecret := flags.String("agenix-secret", "", "Override agenix secret name")
-	agenixRecipients := flags.String("agenix-recipients", "", "Space-separated recipient override")
+	persist := addPersistFlags(flags)
 	skipBlob := flags.Bool("skip-blob", false, "Skip blob storage persistence")
 	_ = flags.Parse(args)
+	persistOpts, err := persist.options()
+	if err != nil {
+		fatal("oauth", err)
+	}
 
 	if *provider == "" || *statePath == "" {
 		oauthUsage()
@@ -305,7 +290,7 @@ func persistCmd(args []string) {
 		fatal("oauth", err)
 	}
 
-	decl, err := lookupDeclaration(cfg, *provider)
+	decl, err := lookupDeclaration(cfg, *provider, *account)
 	if err != nil {
 		fatal("oauth", err)
 	}
@@ -323,16 +308,13 @@ func persistCmd(args []string) {
 	}
 
 	output, err := persistLoadedState(context.Background(), cfg, decl, bootstrap, state, *statePath, false, oauthRunOptions{
-		flow:             "persist",
-		jsonOut:          *jsonOut,
-		printToken:       *printToken,
-		stateOut:         *statePath,
-		cleanup:          *cleanup,
-		persistAgenix:    *persistAgenix,
-		agenixRepo:       *agenixRepo,
-		agenixSecret:     *agenixSecret,
-		agenixRecipients: parseRecipients(*agenixRecipients),
-		skipBlob:         *skipBlob,
+		flow:       "persist",
+		jsonOut:    *jsonOut,
+		printToken: *printToken,
+		stateOut:   *statePath,
+		cleanup:    *cleanup,
+		persist:    persistOpts,
+		skipBlob:   *skipBlob,
 	})
 	if err != nil {
 		fatal("oauth", err)
@@ -341,7 +323,59 @@ func persistCmd(args []string) {
 	emitOAuthOutput(output, *jsonOut, *printToken)
 }
 
-func lookupDeclaration(cfg *configv1.Config, provider string) (oauth.Declaration, error) {
+// rekeyCmd re-encrypts every blob under a new key. Afterwards point
+// oauth.blob_key_file at the new key and list the old one in
+// oauth.blob_previous_key_files until the running service has been updated.
+func rekeyCmd(args []string) {
+	flags := flag.NewFlagSet("rekey", flag.ExitOnError)
+	newKeyFile := flags.String("new-key-file", "", "Secret file for the new blob key")
+	configPath := flags.String("config", config.DefaultPath, "Path to config.pbtxt")
+	jsonOut := flags.Bool("json", false, "Output JSON to stdout")
+	_ = flags.Parse(args)
+
+	if *newKeyFile == "" {
+		oauthUsage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fatal("oauth rekey", err)
+	}
+	newKey, err := oauth.LoadBlobKey(*newKeyFile)
+	if err != nil {
+		fatal("oauth rekey", err)
+	}
+
+	keys, err := oauth.Rekey(context.Background(), cfg.Oauth, newKey)
+	if err != nil {
+		if len(keys) > 0 {
+			fmt.Fprintf(os.Stderr, "oauth rekey: re-encrypted %d blobs before failing; rerun to finish\n", len(keys))
+		}
+		fatal("oauth rekey", err)
+	}
+
+	if *jsonOut {
+		payload, err := json.MarshalIndent(map[string]any{"key_id": newKey.ID(), "blobs": keys}, "", "  ")
+		if err != nil {
+			fatal("oauth rekey", err)
+		}
+		fmt.Println(string(payload))
+		return
+	}
+	for _, key := range keys {
+		fmt.Printf("re-encrypted %s\n", key)
+	}
+	fmt.Printf("%d blobs now use key %s\n", len(keys), newKey.ID())
+	fmt.Printf("Set oauth.blob_key_file to %s and move the old key to oauth.blob_previous_key_files.\n", *newKeyFile)
+}
+
+func lookupDeclaration(cfg *configv1.Config, provider, account string) (oauth.Declaration, error) {
+	if account != "" {
+		if err := oauth.ValidateAccount(account); err != nil {
+			return oauth.Declaration{}, err
+		}
+	}
 	available := make([]string, 0)
 	for _, plugin := range plugins.Compiled(cfg) {
 		decl := plugin.OAuthDeclaration()
@@ -349,7 +383,7 @@ func lookupDeclaration(cfg *configv1.Config, provider string) (oauth.Declaration
 			available = append(available, decl.Provider)
 		}
 		if decl.Provider == provider {
-			return decl, nil
+			return decl.ForAccount(account), nil
 		}
 	}
 
@@ -360,21 +394,21 @@ func lookupDeclaration(cfg *configv1.Config, provider string) (oauth.Declaration
 	return oauth.Declaration{}, fmt.Errorf("unknown provider %q (available: %s)", provider, strings.Join(available, ", "))
 }
 
-func resolveBootstrapPath(cfg *configv1.Config, provider, override string) (string, error) {
+func resolveBootstrapPath(cfg *configv1.Config, provider, account, override string) (string, error) {
 	if override != "" {
 		return override, nil
 	}
-	return config.BootstrapPathForProvider(cfg, provider)
+	return config.BootstrapPathForAccount(cfg, provider, account)
 }
 
-func waitForAuthCode(ctx context.Context, redirectURL, state string, jsonOut bool) (string, error) {
+func waitForAuthCode(ctx context.Context, session *oauthflow.AuthCodeSession, redirectURL string, jsonOut bool) (string, error) {
 	parsed, err := url.Parse(redirectURL)
 	if err != nil {
 		return "", fmt.Errorf("invalid redirect URL: %w", err)
 	}
 
-	if isLoopback(parsed.Hostname()) && parsed.Scheme == "http" && parsed.Host != "" {
-		code, err := listenForAuthCode(ctx, parsed, state)
+	if oauthflow.CanListen(parsed) {
+		code, err := session.ListenForAuthCode(ctx, parsed)
 		if err == nil {
 			return code, nil
 		}
@@ -389,58 +423,6 @@ func waitForAuthCode(ctx context.Context, redirectURL, state string, jsonOut boo
 	return readCodeFromStdin()
 }
 
-func listenForAuthCode(ctx context.Context, redirect *url.URL, state string) (string, error) {
-	codeCh := make(chan string, 1)
-	errCh := make(chan error, 1)
-
-	srv := &http.Server{
-		Addr: redirect.Host,
-		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if redirect.Path != "" && r.URL.Path != redirect.Path {
-				http.NotFound(w, r)
-				return
-			}
-			query := r.URL.Query()
-			if errStr := query.Get("error"); errStr != "" {
-				errCh <- fmt.Errorf("authorization error: %s", errStr)
-				_, _ = w.Write([]byte("Authorization failed. You can close this window."))
-				return
-			}
-			if got := query.Get("state"); got != "" && got != state {
-				errCh <- fmt.Errorf("state mismatch")
-				_, _ = w.Write([]byte("State mismatch. You can close this window."))
-				return
-			}
-			code := query.Get("code")
-			if code == "" {
-				errCh <- fmt.Errorf("missing code in callback")
-				_, _ = w.Write([]byte("Missing authorization code. You can close this window."))
-				return
-			}
-			codeCh <- code
-			_, _ = w.Write([]byte("Authorization received. You can close this window."))
-		}),
-	}
-
-	go func() {
-		if err := srv.ListenAndServe(); err != nil && !strings.Contains(err.Error(), "Server closed") {
-			errCh <- err
-		}
-	}()
-	defer func() {
-		_ = srv.Close()
-	}()
-
-	select {
-	case <-ctx.Done():
-		return "", fmt.Errorf("authorization timed out")
-	case err := <-errCh:
-		return "", err
-	case code := <-codeCh:
-		return code, nil
-	}
-}
-
 func readCodeFromStdin() (string, error) {
 	reader := bufio.NewReader(os.Stdin)
 	line, err := reader.ReadString('\n')
@@ -459,121 +441,6 @@ func readCodeFromStdin() (string, error) {
 	return line, nil
 }
 
-type deviceAuthResponse struct {
-	DeviceCode              string `json:"device_code"`
-	UserCode                string `json:"user_code"`
-	VerificationURI         string `json:"verification_uri"`
-	VerificationURIComplete string `json:"verification_uri_complete"`
-	ExpiresIn               int    `json:"expires_in"`
-	Interval                int    `json:"interval"`
-}
-
-type deviceTokenResponse struct {
-	AccessToken      string `json:"access_token"`
-	RefreshToken     string `json:"refresh_token"`
-	ExpiresIn        int    `json:"expires_in"`
-	TokenType        string `json:"token_type"`
-	Scope            string `json:"scope"`
-	Error            string `json:"error"`
-	ErrorDescription string `json:"error_description"`
-}
-
-func deviceAuthorize(ctx context.Context, url, clientID, scope string) (deviceAuthResponse, error) {
-	form := urlValues{
-		"client_id": {clientID},
-	}
-	if scope != "" {
-		form["scope"] = []string{scope}
-	}
-	var resp deviceAuthResponse
-	if err := postForm(ctx, url, form, &resp); err != nil {
-		return deviceAuthResponse{}, err
-	}
-	if resp.DeviceCode == "" {
-		return deviceAuthResponse{}, fmt.Errorf("device authorization missing device_code")
-	}
-	if resp.Interval == 0 {
-		resp.Interval = 5
-	}
-	if resp.ExpiresIn == 0 {
-		resp.ExpiresIn = 300
-	}
-	return resp, nil
-}
-
-func pollDeviceToken(ctx context.Context, url string, bootstrap oauth.Bootstrap, auth deviceAuthResponse) (deviceTokenResponse, error) {
-	start := time.Now()
-	for {
-		if time.Since(start) > time.Duration(auth.ExpiresIn)*time.Second {
-			return deviceTokenResponse{}, fmt.Errorf("device authorization timed out")
-		}
-
-		form := urlValues{
-			"client_id":   {bootstrap.ClientID},
-			"device_code": {auth.DeviceCode},
-			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
-		}
-		if bootstrap.ClientSecret != "" {
-			form["client_secret"] = []string{bootstrap.ClientSecret}
-		}
-
-		var token deviceTokenResponse
-		err := postForm(ctx, url, form, &token)
-		if err != nil {
-			return deviceTokenResponse{}, err
-		}
-		if token.Error == "" && token.RefreshToken != "" {
-			return token, nil
-		}
-		switch token.Error {
-		case "authorization_pending":
-			time.Sleep(time.Duration(auth.Interval) * time.Second)
-			continue
-		case "slow_down":
-			time.Sleep(time.Duration(auth.Interval+2) * time.Second)
-			continue
-		default:
-			if token.Error != "" {
-				return deviceTokenResponse{}, fmt.Errorf("device token error: %s", token.Error)
-			}
-			return deviceTokenResponse{}, fmt.Errorf("device token missing refresh_token")
-		}
-	}
-}
-
-type urlValues map[string][]string
-
-func postForm(ctx context.Context, endpoint string, values urlValues, out any) error {
-	form := url.Values(values)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 300 {
-		if _, ok := out.(*deviceTokenResponse); ok {
-			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
-				return fmt.Errorf("oauth http %d", resp.StatusCode)
-			}
-			return nil
-		}
-
-		var body deviceTokenResponse
-		if err := json.NewDecoder(resp.Body).Decode(&body); err == nil && body.Error != "" {
-			return fmt.Errorf("oauth error %d: %s", resp.StatusCode, body.Error)
-		}
-		return fmt.Errorf("oauth http %d", resp.StatusCode)
-	}
-	return json.NewDecoder(resp.Body).Decode(out)
-}
-
 func openBrowser(target string) error {
 	switch runtime.GOOS {
 	case "darwin":
@@ -585,47 +452,29 @@ func openBrowser(target string) error {
 	}
 }
 
-func randomState(length int) (string, error) {
-	buf := make([]byte, length)
-	if _, err := rand.Read(buf); err != nil {
-		return "", err
-	}
-	return hex.EncodeToString(buf), nil
-}
-
-func isLoopback(host string) bool {
-	if host == "localhost" {
-		return true
-	}
-	if ip := net.ParseIP(host); ip != nil {
-		return ip.IsLoopback()
-	}
-	return false
-}
-
 type oauthRunOptions struct {
-	flow             string
-	jsonOut          bool
-	printToken       bool
-	stateOut         string
-	statePath        string
-	cleanup          bool
-	persistAgenix    bool
-	skipBlob         bool
-	agenixRepo       string
-	agenixSecret     string
-	agenixRecipients []string
+	flow       string
+	jsonOut    bool
+	printToken bool
+	stateOut   string
+	statePath  string
+	cleanup    bool
+	persist    persistOptions
+	skipBlob   bool
 }
 
 type oauthOutput struct {
 	Provider        string `json:"provider"`
+	Account         string `json:"account"`
 	Flow            string `json:"flow"`
 	VerifyURL       string `json:"verify_url,omitempty"`
 	UserCode        string `json:"user_code,omitempty"`
 	StatePath       string `json:"state_path,omitempty"`
 	StateOut        string `json:"state_out,omitempty"`
 	BlobPersisted   bool   `json:"blob_persisted,omitempty"`
-	AgenixPersisted bool   `json:"agenix_persisted,omitempty"`
+	SecretBackend   string `json:"secret_backend,omitempty"`
+	SecretPath      string `json:"secret_path,omitempty"`
+	AgenixPersisted bool   `json:"agenix_persisted,omitempty"` // kept for scripts; see secret_backend
 	AgenixPath      string `json:"agenix_path,omitempty"`
 	RefreshToken    string `json:"refresh_token,omitempty"`
 }
@@ -635,20 +484,21 @@ func persistOAuthState(ctx context.Context, cfg *configv1.Config, decl oauth.Dec
 		bootstrap.Scope = decl.Scope
 	}
 	state := oauth.State{
-		SchemaVersion: oauth.SchemaVersion,
-		ClientID:      bootstrap.ClientID,
-		ClientSecret:  bootstrap.ClientSecret,
-		RefreshToken:  refreshToken,
-		Scope:         decl.Scope,
+		SchemaVersion:        oauth.SchemaVersion,
+		ClientID:             bootstrap.ClientID,
+		ClientSecret:         bootstrap.ClientSecret,
+		RefreshToken:         refreshToken,
+		Scope:                decl.Scope,
+		RefreshTokenIssuedAt: time.Now().UTC(),
 	}
 	return persistLoadedState(ctx, cfg, decl, bootstrap, state, opts.stateOut, true, opts)
 }
 
 func persistLoadedState(ctx context.Context, cfg *configv1.Config, decl oauth.Declaration, bootstrap oauth.Bootstrap, state oauth.State, tempPath string, writeTemp bool, opts oauthRunOptions) (oauthOutput, error) {
-	output := oauthOutput{Provider: decl.Provider, Flow: opts.flow}
+	output := oauthOutput{Provider: decl.Provider, Account: decl.AccountName(), Flow: opts.flow}
 	path := tempPath
 	if path == "" {
-		path = oauthflow.DefaultTempPath(decl.Provider)
+		path = oauthflow.DefaultTempPath(decl.Key())
 	}
 	if writeTemp {
 		if _, err := oauthflow.WriteTempState(path, state); err != nil {
@@ -659,7 +509,7 @@ func persistLoadedState(ctx context.Context, cfg *configv1.Config, decl oauth.De
 
 	var blobStore oauth.BlobStore
 	if !opts.skipBlob {
-		store, err := oauth.NewS3Store(cfg.Oauth)
+		store, err := oauth.NewBlobStore(cfg.Oauth)
 		if err != nil {
 			return output, err
 		}
@@ -675,13 +525,17 @@ func persistLoadedState(ctx context.Context, cfg *configv1.Config, decl oauth.De
 	output.StatePath = persistResult.StatePath
 	output.BlobPersisted = persistResult.BlobSaved
 
-	if opts.persistAgenix {
-		agenixPath, err := persistAgenixBootstrap(ctx, decl.Provider, bootstrap, opts)
+	if opts.persist.backend != secrets.BackendNone {
+		secretPath, err := persistBootstrapSecret(ctx, decl, bootstrap, opts.persist)
 		if err != nil {
 			return output, err
 		}
-		output.AgenixPersisted = true
-		output.AgenixPath = agenixPath
+		output.SecretBackend = opts.persist.backend
+		output.SecretPath = secretPath
+		if opts.persist.backend == secrets.BackendAgenix {
+			output.AgenixPersisted = true
+			output.AgenixPath = secretPath
+		}
 	}
 
 	if opts.printToken {
@@ -717,8 +571,8 @@ func emitOAuthOutput(output oauthOutput, jsonOut bool, printToken bool) {
 		fmt.Printf("Temp state file: %s\n", output.StateOut)
 	}
 	fmt.Printf("Blob persisted: %t\n", output.BlobPersisted)
-	if output.AgenixPersisted {
-		fmt.Printf("Agenix secret: %s\n", output.AgenixPath)
+	if output.SecretPath != "" {
+		fmt.Printf("Bootstrap secret (%s): %s\n", output.SecretBackend, output.SecretPath)
 	}
 	if printToken && output.RefreshToken != "" {
 		fmt.Printf("Refresh token: %s\n", output.RefreshToken)
@@ -739,7 +593,64 @@ func parseRecipients(raw string) []string {
 	return strings.Fields(raw)
 }
 
-func defaultAgenixRepo() string {
+// persistFlags choose where the bootstrap secret is written after a
+// successful authorization.
+type persistFlags struct {
+	backend          *string
+	persistAgenix    *bool
+	agenixRepo       *string
+	agenixSecret     *string
+	agenixRecipients *string
+	sopsRepo         *string
+	sopsFile         *string
+	sopsKey          *string
+}
+
+func addPersistFlags(flags *flag.FlagSet) persistFlags {
+	return persistFlags{
+		backend:          flags.String("persist", secrets.BackendAgenix, "Persist bootstrap secret via agenix, sops or none"),
+		persistAgenix:    flags.Bool("persist-agenix", true, "Deprecated: --persist-agenix=false is --persist none"),
+		agenixRepo:       flags.String("agenix-repo", defaultSecretsRepo(), "Path to nix-secrets repo (agenix)"),
+		agenixSecret:     flags.String("agenix-secret", "", "Override agenix secret name"),
+		agenixRecipients: flags.String("agenix-recipients", "", "Space-separated recipient override"),
+		sopsRepo:         flags.String("sops-repo", defaultSecretsRepo(), "Path to the repo holding .sops.yaml (sops)"),
+		sopsFile:         flags.String("sops-file", "", "Override sops secret file name (.yaml or .json)"),
+		sopsKey:          flags.String("sops-key", sops.DefaultKey, "Key the bootstrap JSON is stored under in the sops file"),
+	}
+}
+
+type persistOptions struct {
+	backend          string
+	agenixRepo       string
+	agenixSecret     string
+	agenixRecipients []string
+	sopsRepo         string
+	sopsFile         string
+	sopsKey          string
+}
+
+func (f persistFlags) options() (persistOptions, error) {
+	backend := strings.TrimSpace(*f.backend)
+	if !*f.persistAgenix {
+		backend = secrets.BackendNone
+	}
+	switch backend {
+	case secrets.BackendAgenix, secrets.BackendSops, secrets.BackendNone:
+	default:
+		return persistOptions{}, fmt.Errorf("unknown --persist backend %q (want %s, %s or %s)", backend, secrets.BackendAgenix, secrets.BackendSops, secrets.BackendNone)
+	}
+	return persistOptions{
+		backend:          backend,
+		agenixRepo:       strings.TrimSpace(*f.agenixRepo),
+		agenixSecret:     strings.TrimSpace(*f.agenixSecret),
+		agenixRecipients: parseRecipients(*f.agenixRecipients),
+		sopsRepo:         strings.TrimSpace(*f.sopsRepo),
+		sopsFile:         strings.TrimSpace(*f.sopsFile),
+		sopsKey:          strings.TrimSpace(*f.sopsKey),
+	}, nil
+}
+
+func defaultSecretsRepo() string {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return ""
@@ -752,28 +663,56 @@ func defaultAgenixRepo() string {
 	return repo
 }
 
-func defaultAgenixSecret(provider string) string {
-	return fmt.Sprintf("gohome-%s-bootstrap.age", provider)
+// defaultSecretName names the bootstrap secret: gohome-tado-bootstrap.age,
+// or gohome-tado-holiday-bootstrap.age for a named account. ext is the
+// backend's file extension.
+func defaultSecretName(decl oauth.Declaration, ext string) string {
+	return fmt.Sprintf("gohome-%s-bootstrap%s", strings.ReplaceAll(decl.Key(), "/", "-"), ext)
 }
 
-func persistAgenixBootstrap(ctx context.Context, provider string, bootstrap oauth.Bootstrap, opts oauthRunOptions) (string, error) {
-	repo := strings.TrimSpace(opts.agenixRepo)
-	if repo == "" {
-		return "", fmt.Errorf("agenix repo not configured")
+// secretWriter builds the backend selected by --persist.
+func secretWriter(decl oauth.Declaration, opts persistOptions) (secrets.SecretWriter, error) {
+	switch opts.backend {
+	case secrets.BackendAgenix:
+		if opts.agenixRepo == "" {
+			return nil, fmt.Errorf("agenix repo not configured")
+		}
+		name := opts.agenixSecret
+		if name == "" {
+			name = defaultSecretName(decl, ".age")
+		}
+		return agenix.Writer{
+			RepoPath:   opts.agenixRepo,
+			SecretName: name,
+			Recipients: opts.agenixRecipients,
+		}, nil
+	case secrets.BackendSops:
+		if opts.sopsRepo == "" {
+			return nil, fmt.Errorf("sops repo not configured")
+		}
+		name := opts.sopsFile
+		if name == "" {
+			name = defaultSecretName(decl, ".yaml")
+		}
+		return sops.Writer{
+			RepoPath:   opts.sopsRepo,
+			SecretName: name,
+			Key:        opts.sopsKey,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown --persist backend %q", opts.backend)
 	}
-	secret := strings.TrimSpace(opts.agenixSecret)
-	if secret == "" {
-		secret = defaultAgenixSecret(provider)
+}
+
+func persistBootstrapSecret(ctx context.Context, decl oauth.Declaration, bootstrap oauth.Bootstrap, opts persistOptions) (string, error) {
+	writer, err := secretWriter(decl, opts)
+	if err != nil {
+		return "", err
 	}
 	payload, err := json.MarshalIndent(bootstrap, "", "  ")
 	if err != nil {
 		return "", err
 	}
-	writer := agenix.Writer{
-		RepoPath:   repo,
-		SecretName: secret,
-		Recipients: opts.agenixRecipients,
-	}
 	return writer.Write(ctx, payload)
 }
 
@@ -9,8 +9,12 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/joshp123/gohome/internal/secrets"
 )
 
+var _ secrets.SecretWriter = Writer{}
+
 // Writer persists secrets into a nix-secrets repo via agenix.
 type Writer struct {
 	RepoPath   string
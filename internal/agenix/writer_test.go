@@ -0,0 +1,81 @@
+package agenix
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeAgenix writes a stand-in agenix that records its arguments and RULES
+// and copies stdin into the secret file, as EDITOR="cp /dev/stdin" would.
+func fakeAgenix(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	log := filepath.Join(dir, "agenix.log")
+	script := filepath.Join(dir, "agenix")
+	body := "#!/bin/sh\n" +
+		"echo \"args=$*\" >> " + log + "\n" +
+		"echo \"rules=$RULES\" >> " + log + "\n" +
+		"cat > \"$2\"\n"
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatalf("write fake agenix: %v", err)
+	}
+	return script, log
+}
+
+func TestWriterAddsEntryAndEncrypts(t *testing.T) {
+	repo := t.TempDir()
+	rules := filepath.Join(repo, "secrets.nix")
+	seed := "let\n  host = \"age1host\";\nin\n{\n  \"gohome-daikin-bootstrap.age\".publicKeys = [ host ];\n}\n"
+	if err := os.WriteFile(rules, []byte(seed), 0o644); err != nil {
+		t.Fatalf("write secrets.nix: %v", err)
+	}
+	execPath, log := fakeAgenix(t)
+
+	path, err := Writer{RepoPath: repo, SecretName: "gohome-tado-bootstrap", Exec: execPath}.Write(context.Background(), []byte(`{"client_id":"x"}`))
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if want := filepath.Join(repo, "gohome-tado-bootstrap.age"); path != want {
+		t.Fatalf("path = %s, want %s", path, want)
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"client_id":"x"}` {
+		t.Fatalf("secret = %q", data)
+	}
+
+	updated, _ := os.ReadFile(rules)
+	if !strings.Contains(string(updated), `"gohome-tado-bootstrap.age".publicKeys = [ host ];`) {
+		t.Fatalf("secrets.nix missing entry with default recipients:\n%s", updated)
+	}
+	calls, _ := os.ReadFile(log)
+	if !strings.Contains(string(calls), "args=-e "+path) || !strings.Contains(string(calls), "rules="+rules) {
+		t.Fatalf("agenix invoked with:\n%s", calls)
+	}
+
+	// A second write leaves the existing entry alone.
+	if _, err := (Writer{RepoPath: repo, SecretName: "gohome-tado-bootstrap.age", Exec: execPath}).Write(context.Background(), []byte("{}")); err != nil {
+		t.Fatalf("second Write: %v", err)
+	}
+	again, _ := os.ReadFile(rules)
+	if strings.Count(string(again), "gohome-tado-bootstrap.age") != 1 {
+		t.Fatalf("entry duplicated:\n%s", again)
+	}
+}
+
+func TestWriterReportsAgenixFailure(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "secrets.nix"), []byte("{\n}\n"), 0o644); err != nil {
+		t.Fatalf("write secrets.nix: %v", err)
+	}
+	script := filepath.Join(t.TempDir(), "agenix")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho 'no identity found' >&2\nexit 1\n"), 0o755); err != nil {
+		t.Fatalf("write fake agenix: %v", err)
+	}
+
+	_, err := Writer{RepoPath: repo, SecretName: "s", Recipients: []string{"age1x"}, Exec: script}.Write(context.Background(), []byte("x"))
+	if err == nil || !strings.Contains(err.Error(), "no identity found") {
+		t.Fatalf("Write = %v, want agenix stderr in the error", err)
+	}
+}
@@ -0,0 +1,216 @@
+// Package audit records mutating RPCs to an append-only JSONL file.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultQueryLimit bounds Query results when no limit is given.
+	DefaultQueryLimit = 50
+	// MaxQueryLimit is the largest accepted query limit.
+	MaxQueryLimit = 1000
+)
+
+// Entry is one audited call.
+type Entry struct {
+	Time      time.Time       `json:"time"`
+	Caller    string          `json:"caller"`
+	Method    string          `json:"method"`
+	Request   json.RawMessage `json:"request,omitempty"`
+	Code      string          `json:"code"`
+	Error     string          `json:"error,omitempty"`
+	LatencyMS float64         `json:"latency_ms"`
+}
+
+// Filter selects entries in Query. Zero fields match everything.
+type Filter struct {
+	Caller       string
+	Method       string // substring of the full method
+	Since        time.Time
+	FailuresOnly bool
+	Limit        int
+}
+
+func (f Filter) match(e Entry) bool {
+	if f.Caller != "" && e.Caller != f.Caller {
+		return false
+	}
+	if f.Method != "" && !strings.Contains(e.Method, f.Method) {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if f.FailuresOnly && e.Code == "OK" {
+		return false
+	}
+	return true
+}
+
+// Log appends entries to path, rotating it to path.1 ... path.N when it
+// grows past maxBytes.
+type Log struct {
+	path     string
+	maxBytes int64
+	maxFiles int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// Open opens (or creates) the audit log at path.
+func Open(path string, maxBytes int64, maxFiles int) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("create audit dir: %w", err)
+	}
+	l := &Log{path: path, maxBytes: maxBytes, maxFiles: maxFiles}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Record appends e as one JSON line.
+func (l *Log) Record(e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encode audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return fmt.Errorf("audit log closed")
+	}
+	if l.maxBytes > 0 && l.size > 0 && l.size+int64(len(line)) > l.maxBytes {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("write audit entry: %w", err)
+	}
+	return nil
+}
+
+// Query returns matching entries, newest first, across the current and
+// rotated files.
+func (l *Log) Query(f Filter) ([]Entry, error) {
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultQueryLimit
+	}
+	if limit > MaxQueryLimit {
+		limit = MaxQueryLimit
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var out []Entry
+	for i := 0; i <= l.maxFiles && len(out) < limit; i++ {
+		entries, err := readEntries(l.rotatedPath(i))
+		if errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		for j := len(entries) - 1; j >= 0 && len(out) < limit; j-- {
+			if f.match(entries[j]) {
+				out = append(out, entries[j])
+			}
+		}
+	}
+	return out, nil
+}
+
+// Close flushes and closes the current file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+func (l *Log) open() error {
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("stat audit log: %w", err)
+	}
+	l.file = file
+	l.size = info.Size()
+	return nil
+}
+
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("close audit log: %w", err)
+	}
+	l.file = nil
+	if l.maxFiles > 0 {
+		_ = os.Remove(l.rotatedPath(l.maxFiles))
+		for i := l.maxFiles - 1; i >= 0; i-- {
+			err := os.Rename(l.rotatedPath(i), l.rotatedPath(i+1))
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("rotate audit log: %w", err)
+			}
+		}
+	} else if err := os.Remove(l.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("rotate audit log: %w", err)
+	}
+	return l.open()
+}
+
+func (l *Log) rotatedPath(n int) string {
+	if n == 0 {
+		return l.path
+	}
+	return fmt.Sprintf("%s.%d", l.path, n)
+}
+
+func readEntries(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		// Skip torn or hand-edited lines rather than failing the whole query.
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,104 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLogRotatesAndQueriesNewestFirst(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := Open(path, 200, 2)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer log.Close()
+
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		code := "OK"
+		if i%3 == 0 {
+			code = "PermissionDenied"
+		}
+		entry := Entry{
+			Time:   base.Add(time.Duration(i) * time.Minute),
+			Caller: []string{"alice", "bob"}[i%2],
+			Method: "/gohome.plugins.tado.v1.TadoService/SetTemperature",
+			Code:   code,
+		}
+		if err := log.Record(entry); err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+	}
+
+	if _, err := os.Stat(path + ".2"); err != nil {
+		t.Fatalf("expected rotated file: %v", err)
+	}
+	if _, err := os.Stat(path + ".3"); err == nil {
+		t.Fatalf("kept more than max_files rotations")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("perm = %o, want 600", perm)
+	}
+
+	all, err := log.Query(Filter{})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if len(all) == 0 || !all[0].Time.Equal(base.Add(9*time.Minute)) {
+		t.Fatalf("expected newest entry first, got %+v", all)
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i].Time.After(all[i-1].Time) {
+			t.Fatalf("entries not newest first at %d", i)
+		}
+	}
+}
+
+func TestLogQueryFilters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := Open(path, 0, 0)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer log.Close()
+
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Time: base, Caller: "alice", Method: "/a.Svc/Set", Code: "OK"},
+		{Time: base.Add(time.Hour), Caller: "bob", Method: "/a.Svc/Set", Code: "PermissionDenied"},
+		{Time: base.Add(2 * time.Hour), Caller: "alice", Method: "/b.Svc/Clean", Code: "Unavailable"},
+	}
+	for _, entry := range entries {
+		if err := log.Record(entry); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   int
+	}{
+		{"all", Filter{}, 3},
+		{"caller", Filter{Caller: "alice"}, 2},
+		{"method", Filter{Method: "Clean"}, 1},
+		{"since", Filter{Since: base.Add(30 * time.Minute)}, 2},
+		{"failures", Filter{FailuresOnly: true}, 2},
+		{"limit", Filter{Limit: 1}, 1},
+	}
+	for _, tt := range tests {
+		got, err := log.Query(tt.filter)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(got) != tt.want {
+			t.Fatalf("%s: got %d entries, want %d", tt.name, len(got), tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package audit
+
+import (
+	"context"
+
+	auditv1 "github.com/joshp123/gohome/proto/gen/audit/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type service struct {
+	auditv1.UnimplementedAuditServiceServer
+	log *Log
+}
+
+// RegisterAuditService registers the audit query RPC on the gRPC server.
+func RegisterAuditService(server *grpc.Server, log *Log) {
+	auditv1.RegisterAuditServiceServer(server, &service{log: log})
+}
+
+func (s *service) Query(ctx context.Context, req *auditv1.QueryRequest) (*auditv1.QueryResponse, error) {
+	if s.log == nil {
+		return nil, status.Error(codes.Unavailable, "audit log is not configured")
+	}
+	filter := Filter{
+		Caller:       req.GetCaller(),
+		Method:       req.GetMethod(),
+		FailuresOnly: req.GetFailuresOnly(),
+		Limit:        int(req.GetLimit()),
+	}
+	if req.GetSince() != nil {
+		filter.Since = req.GetSince().AsTime()
+	}
+
+	entries, err := s.log.Query(filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "query audit log: %v", err)
+	}
+	resp := &auditv1.QueryResponse{Entries: make([]*auditv1.AuditEntry, 0, len(entries))}
+	for _, e := range entries {
+		resp.Entries = append(resp.Entries, &auditv1.AuditEntry{
+			Time:        timestamppb.New(e.Time),
+			Caller:      e.Caller,
+			Method:      e.Method,
+			RequestJson: string(e.Request),
+			Code:        e.Code,
+			Error:       e.Error,
+			LatencyMs:   e.LatencyMS,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,213 @@
+// Package auth authenticates gRPC and HTTP callers with bearer tokens.
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
+)
+
+// DefaultTokenName identifies callers using AuthConfig.token_file.
+const DefaultTokenName = "default"
+
+// healthService stays reachable without a token so probes keep working.
+const healthService = "/grpc.health.v1.Health/"
+
+// Identity is the authenticated caller.
+type Identity struct {
+	Name string
+}
+
+type identityKey struct{}
+
+// WithIdentity attaches the caller to ctx.
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext returns the caller attached by the interceptors or middleware.
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+type token struct {
+	name   string
+	secret []byte
+}
+
+// Tokens holds the accepted bearer tokens. It is safe for concurrent use and
+// can be reloaded in place.
+type Tokens struct {
+	mu     sync.RWMutex
+	tokens []token
+}
+
+// LoadTokens reads every token file referenced by cfg.
+func LoadTokens(cfg *configv1.AuthConfig) (*Tokens, error) {
+	t := &Tokens{}
+	if err := t.Reload(cfg); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Reload replaces the accepted tokens. On error the previous tokens stay active.
+func (t *Tokens) Reload(cfg *configv1.AuthConfig) error {
+	var tokens []token
+	if path := cfg.GetTokenFile(); path != "" {
+		secret, err := readToken(path)
+		if err != nil {
+			return err
+		}
+		tokens = append(tokens, token{name: DefaultTokenName, secret: secret})
+	}
+	seen := make(map[string]bool)
+	for _, named := range cfg.GetTokens() {
+		if named.GetName() == "" {
+			return fmt.Errorf("auth token name is required")
+		}
+		if named.GetName() == DefaultTokenName {
+			return fmt.Errorf("auth token name %q is reserved for token_file", DefaultTokenName)
+		}
+		if seen[named.GetName()] {
+			return fmt.Errorf("duplicate auth token name %q", named.GetName())
+		}
+		seen[named.GetName()] = true
+		secret, err := readToken(named.GetTokenFile())
+		if err != nil {
+			return fmt.Errorf("auth token %s: %w", named.GetName(), err)
+		}
+		tokens = append(tokens, token{name: named.GetName(), secret: secret})
+	}
+
+	t.mu.Lock()
+	t.tokens = tokens
+	t.mu.Unlock()
+	return nil
+}
+
+// Enabled reports whether any token is configured.
+func (t *Tokens) Enabled() bool {
+	if t == nil {
+		return false
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.tokens) > 0
+}
+
+// Authenticate checks an Authorization header value.
+func (t *Tokens) Authenticate(header string) (Identity, error) {
+	scheme, secret, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") || secret == "" {
+		return Identity{}, fmt.Errorf("missing bearer token")
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	// Compare against every token so timing does not reveal which one matched.
+	var match *token
+	for i := range t.tokens {
+		if subtle.ConstantTimeCompare(t.tokens[i].secret, []byte(secret)) == 1 {
+			match = &t.tokens[i]
+		}
+	}
+	if match == nil {
+		return Identity{}, fmt.Errorf("invalid bearer token")
+	}
+	return Identity{Name: match.name}, nil
+}
+
+// UnaryInterceptor rejects unauthenticated unary calls.
+func (t *Tokens) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := t.authenticateGRPC(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// StreamInterceptor rejects unauthenticated streaming calls.
+func (t *Tokens) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := t.authenticateGRPC(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &identityStream{ServerStream: ss, ctx: ctx})
+}
+
+func (t *Tokens) authenticateGRPC(ctx context.Context, fullMethod string) (context.Context, error) {
+	if !t.Enabled() || strings.HasPrefix(fullMethod, healthService) {
+		return ctx, nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	var header string
+	if values := md.Get("authorization"); len(values) > 0 {
+		header = values[0]
+	}
+	id, err := t.Authenticate(header)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return WithIdentity(ctx, id), nil
+}
+
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context {
+	return s.ctx
+}
+
+// Middleware rejects unauthenticated HTTP requests except for the paths in
+// open, which are matched exactly.
+func (t *Tokens) Middleware(next http.Handler, open ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !t.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		for _, path := range open {
+			if r.URL.Path == path {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		id, err := t.Authenticate(r.Header.Get("Authorization"))
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="gohome"`)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), id)))
+	})
+}
+
+func readToken(path string) ([]byte, error) {
+	if path == "" {
+		return nil, fmt.Errorf("token_file is required")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read token: %w", err)
+	}
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return nil, fmt.Errorf("token file %s is empty", path)
+	}
+	return []byte(secret), nil
+}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
+)
+
+func writeToken(t *testing.T, secret string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte(secret+"\n"), 0o600); err != nil {
+		t.Fatalf("write token: %v", err)
+	}
+	return path
+}
+
+func testTokens(t *testing.T) *Tokens {
+	t.Helper()
+	tokens, err := LoadTokens(&configv1.AuthConfig{
+		TokenFile: writeToken(t, "shared-secret"),
+		Tokens: []*configv1.NamedToken{
+			{Name: "laptop", TokenFile: writeToken(t, "laptop-secret")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("LoadTokens: %v", err)
+	}
+	return tokens
+}
+
+func TestAuthenticate(t *testing.T) {
+	tokens := testTokens(t)
+
+	cases := []struct {
+		header string
+		want   string
+		ok     bool
+	}{
+		{"Bearer shared-secret", DefaultTokenName, true},
+		{"bearer laptop-secret", "laptop", true},
+		{"Bearer wrong", "", false},
+		{"Basic laptop-secret", "", false},
+		{"", "", false},
+	}
+	for _, tc := range cases {
+		id, err := tokens.Authenticate(tc.header)
+		if (err == nil) != tc.ok {
+			t.Fatalf("Authenticate(%q) err = %v, want ok=%v", tc.header, err, tc.ok)
+		}
+		if id.Name != tc.want {
+			t.Fatalf("Authenticate(%q) = %q, want %q", tc.header, id.Name, tc.want)
+		}
+	}
+}
+
+func TestReloadRejectsReservedName(t *testing.T) {
+	tokens := testTokens(t)
+	err := tokens.Reload(&configv1.AuthConfig{
+		TokenFile: writeToken(t, "shared-secret"),
+		Tokens: []*configv1.NamedToken{
+			{Name: DefaultTokenName, TokenFile: writeToken(t, "other-secret")},
+		},
+	})
+	if err == nil {
+		t.Fatalf("expected a named token called %q to be rejected", DefaultTokenName)
+	}
+	if id, err := tokens.Authenticate("Bearer other-secret"); err == nil {
+		t.Fatalf("rejected reload took effect: authenticated as %q", id.Name)
+	}
+}
+
+func TestDisabledWithoutTokens(t *testing.T) {
+	tokens, err := LoadTokens(nil)
+	if err != nil {
+		t.Fatalf("LoadTokens: %v", err)
+	}
+	if tokens.Enabled() {
+		t.Fatalf("expected auth disabled without tokens")
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/gohome.registry.v1.Registry/ListPlugins"}
+	if _, err := tokens.UnaryInterceptor(context.Background(), nil, info, okHandler); err != nil {
+		t.Fatalf("unexpected error with auth disabled: %v", err)
+	}
+}
+
+func okHandler(ctx context.Context, _ interface{}) (interface{}, error) {
+	id, _ := FromContext(ctx)
+	return id.Name, nil
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	tokens := testTokens(t)
+	info := &grpc.UnaryServerInfo{FullMethod: "/gohome.plugins.tado.v1.TadoService/SetTemperature"}
+
+	_, err := tokens.UnaryInterceptor(context.Background(), nil, info, okHandler)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated, got %v", err)
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer laptop-secret"))
+	resp, err := tokens.UnaryInterceptor(ctx, nil, info, okHandler)
+	if err != nil || resp != "laptop" {
+		t.Fatalf("expected laptop identity, got %v, %v", resp, err)
+	}
+
+	health := &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}
+	if _, err := tokens.UnaryInterceptor(context.Background(), nil, health, okHandler); err != nil {
+		t.Fatalf("health check should not require a token: %v", err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	tokens := testTokens(t)
+	handler := tokens.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, _ := FromContext(r.Context())
+		_, _ = w.Write([]byte(id.Name))
+	}), "/health")
+
+	cases := []struct {
+		path   string
+		header string
+		code   int
+	}{
+		{"/health", "", http.StatusOK},
+		{"/metrics", "", http.StatusUnauthorized},
+		{"/roborock/map.png", "Bearer wrong", http.StatusUnauthorized},
+		{"/metrics", "Bearer shared-secret", http.StatusOK},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.header != "" {
+			req.Header.Set("Authorization", tc.header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tc.code {
+			t.Fatalf("%s with %q: got %d, want %d", tc.path, tc.header, rec.Code, tc.code)
+		}
+	}
+}
@@ -1,12 +1,15 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/joshp123/gohome/internal/auth"
+	"github.com/joshp123/gohome/internal/oauth"
 	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
 )
 
@@ -18,16 +21,28 @@ const (
 	DefaultDashboardDir                = "/var/lib/gohome/dashboards"
 	DefaultOAuthPrefix                 = "gohome/oauth"
 	DefaultOAuthRefreshIntervalSeconds = 600
+	DefaultAuditPath                   = "/var/lib/gohome/audit.jsonl"
+	DefaultAuditMaxSizeMB              = 10
+	DefaultAuditMaxFiles               = 5
+	DefaultRateStateDir                = "/var/lib/gohome"
+	DefaultRateSaveIntervalSeconds     = 60
 )
 
 // Load parses the textproto config file, applies defaults, and validates.
 func Load(path string) (*configv1.Config, error) {
-	var (
-		data []byte
-		err  error
-	)
+	cfg, err := Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	if err = Validate(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
 
-	data, err = os.ReadFile(path)
+// Parse reads the textproto config file and applies defaults without validating.
+func Parse(path string) (*configv1.Config, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read config: %w", err)
 	}
@@ -38,9 +53,6 @@ func Load(path string) (*configv1.Config, error) {
 	}
 
 	applyDefaults(cfg)
-	if err = Validate(cfg); err != nil {
-		return nil, err
-	}
 	return cfg, nil
 }
 
@@ -57,6 +69,26 @@ func applyDefaults(cfg *configv1.Config) {
 	if cfg.Core.DashboardDir == "" {
 		cfg.Core.DashboardDir = DefaultDashboardDir
 	}
+	if cfg.Core.Audit == nil {
+		cfg.Core.Audit = &configv1.AuditConfig{}
+	}
+	if cfg.Core.Audit.Path == "" {
+		cfg.Core.Audit.Path = DefaultAuditPath
+	}
+	if cfg.Core.Audit.MaxSizeMb == 0 {
+		cfg.Core.Audit.MaxSizeMb = DefaultAuditMaxSizeMB
+	}
+	if cfg.Core.Audit.MaxFiles == 0 {
+		cfg.Core.Audit.MaxFiles = DefaultAuditMaxFiles
+	}
+	if rs := cfg.Core.RateState; rs != nil {
+		if rs.Dir == "" {
+			rs.Dir = DefaultRateStateDir
+		}
+		if rs.SaveIntervalSeconds == 0 {
+			rs.SaveIntervalSeconds = DefaultRateSaveIntervalSeconds
+		}
+	}
 
 	if cfg.Oauth == nil {
 		cfg.Oauth = &configv1.OAuthConfig{}
@@ -72,59 +104,93 @@ func applyDefaults(cfg *configv1.Config) {
 	}
 }
 
-// Validate enforces required invariants beyond proto typing.
+// Validate enforces required invariants beyond proto typing. All violations
+// are joined into the returned error.
 func Validate(cfg *configv1.Config) error {
+	return errors.Join(ValidateAll(cfg)...)
+}
+
+// ValidateAll returns every invariant violation in cfg.
+func ValidateAll(cfg *configv1.Config) []error {
 	if cfg == nil {
-		return fmt.Errorf("config is required")
+		return []error{fmt.Errorf("config is required")}
 	}
+
+	var errs []error
 	if cfg.SchemaVersion != SchemaVersion {
-		return fmt.Errorf("schema_version must be %d", SchemaVersion)
+		errs = append(errs, fmt.Errorf("schema_version must be %d", SchemaVersion))
 	}
 
 	if cfg.Core == nil {
-		return fmt.Errorf("core config is required")
-	}
-	if cfg.Core.GrpcAddr == "" {
-		return fmt.Errorf("core.grpc_addr is required")
-	}
-	if cfg.Core.HttpAddr == "" {
-		return fmt.Errorf("core.http_addr is required")
-	}
-	if cfg.Core.DashboardDir == "" {
-		return fmt.Errorf("core.dashboard_dir is required")
+		errs = append(errs, fmt.Errorf("core config is required"))
+	} else {
+		if cfg.Core.GrpcAddr == "" {
+			errs = append(errs, fmt.Errorf("core.grpc_addr is required"))
+		}
+		if cfg.Core.HttpAddr == "" {
+			errs = append(errs, fmt.Errorf("core.http_addr is required"))
+		}
+		if cfg.Core.DashboardDir == "" {
+			errs = append(errs, fmt.Errorf("core.dashboard_dir is required"))
+		}
+		for i, token := range cfg.Core.GetAuth().GetTokens() {
+			switch token.Name {
+			case "":
+				errs = append(errs, fmt.Errorf("core.auth.tokens[%d].name is required", i))
+			case auth.DefaultTokenName:
+				errs = append(errs, fmt.Errorf("core.auth.tokens[%d].name %q is reserved for core.auth.token_file", i, token.Name))
+			}
+			if token.TokenFile == "" {
+				errs = append(errs, fmt.Errorf("core.auth.tokens[%d].token_file is required", i))
+			}
+		}
+		if tls := cfg.Core.GetTls(); tls != nil {
+			if (tls.CertFile == "") != (tls.KeyFile == "") {
+				errs = append(errs, fmt.Errorf("core.tls.cert_file and core.tls.key_file must be set together"))
+			}
+			if tls.ClientCaFile != "" && tls.CertFile == "" {
+				errs = append(errs, fmt.Errorf("core.tls.client_ca_file requires core.tls.cert_file"))
+			}
+		}
 	}
 
 	if cfg.Oauth == nil {
-		return fmt.Errorf("oauth config is required")
-	}
-	if cfg.Oauth.BlobEndpoint == "" {
-		return fmt.Errorf("oauth.blob_endpoint is required")
-	}
-	if cfg.Oauth.BlobBucket == "" {
-		return fmt.Errorf("oauth.blob_bucket is required")
-	}
-	if cfg.Oauth.BlobAccessKeyFile == "" {
-		return fmt.Errorf("oauth.blob_access_key_file is required")
-	}
-	if cfg.Oauth.BlobSecretKeyFile == "" {
-		return fmt.Errorf("oauth.blob_secret_key_file is required")
+		errs = append(errs, fmt.Errorf("oauth config is required"))
+	} else {
+		if cfg.Oauth.BlobEndpoint == "" {
+			errs = append(errs, fmt.Errorf("oauth.blob_endpoint is required"))
+		} else if _, isFile, err := oauth.FileEndpointDir(cfg.Oauth.BlobEndpoint); isFile {
+			if err != nil {
+				errs = append(errs, fmt.Errorf("oauth.blob_endpoint: %w", err))
+			}
+		} else {
+			if cfg.Oauth.BlobBucket == "" {
+				errs = append(errs, fmt.Errorf("oauth.blob_bucket is required"))
+			}
+			if cfg.Oauth.BlobAccessKeyFile == "" {
+				errs = append(errs, fmt.Errorf("oauth.blob_access_key_file is required"))
+			}
+			if cfg.Oauth.BlobSecretKeyFile == "" {
+				errs = append(errs, fmt.Errorf("oauth.blob_secret_key_file is required"))
+			}
+		}
 	}
 
-	if cfg.Tado != nil && cfg.Tado.BootstrapFile == "" {
-		return fmt.Errorf("tado.bootstrap_file is required")
+	if cfg.Tado != nil && cfg.Tado.BootstrapFile == "" && len(cfg.Tado.Accounts) == 0 {
+		errs = append(errs, fmt.Errorf("tado.bootstrap_file or tado.accounts is required"))
 	}
-	if cfg.Daikin != nil && cfg.Daikin.BootstrapFile == "" {
-		return fmt.Errorf("daikin.bootstrap_file is required")
+	if cfg.Daikin != nil && cfg.Daikin.BootstrapFile == "" && len(cfg.Daikin.Accounts) == 0 {
+		errs = append(errs, fmt.Errorf("daikin.bootstrap_file or daikin.accounts is required"))
 	}
 	if cfg.Growatt != nil && cfg.Growatt.TokenFile == "" {
-		return fmt.Errorf("growatt.token_file is required")
+		errs = append(errs, fmt.Errorf("growatt.token_file is required"))
 	}
 	if cfg.Roborock != nil && cfg.Roborock.BootstrapFile == "" {
-		return fmt.Errorf("roborock.bootstrap_file is required")
+		errs = append(errs, fmt.Errorf("roborock.bootstrap_file is required"))
 	}
 	// weheat uses hardcoded public client credentials; no bootstrap file needed.
 
-	return nil
+	return errs
 }
 
 // EnabledPlugins maps enabled plugin IDs based on config presence.
@@ -162,16 +228,35 @@ func EnabledPlugins(cfg *configv1.Config) map[string]bool {
 
 // BootstrapPathForProvider resolves the bootstrap file path from config.
 func BootstrapPathForProvider(cfg *configv1.Config, provider string) (string, error) {
+	return BootstrapPathForAccount(cfg, provider, "")
+}
+
+// BootstrapPathForAccount resolves the bootstrap file path of one of a
+// provider's accounts. "" and "default" select the default account; only
+// tado and daikin support named accounts.
+func BootstrapPathForAccount(cfg *configv1.Config, provider, account string) (string, error) {
 	if cfg == nil {
 		return "", fmt.Errorf("config is required")
 	}
+	if account == "default" {
+		account = ""
+	}
+	if account != "" && provider != "tado" && provider != "daikin" {
+		return "", fmt.Errorf("%s does not support named accounts", provider)
+	}
 	switch provider {
 	case "tado":
+		if account != "" {
+			return accountBootstrapPath(provider, account, cfg.GetTado().GetAccounts())
+		}
 		if cfg.Tado == nil || cfg.Tado.BootstrapFile == "" {
 			return "", fmt.Errorf("tado bootstrap_file is required")
 		}
 		return cfg.Tado.BootstrapFile, nil
 	case "daikin":
+		if account != "" {
+			return accountBootstrapPath(provider, account, cfg.GetDaikin().GetAccounts())
+		}
 		if cfg.Daikin == nil || cfg.Daikin.BootstrapFile == "" {
 			return "", fmt.Errorf("daikin bootstrap_file is required")
 		}
@@ -188,3 +273,20 @@ func BootstrapPathForProvider(cfg *configv1.Config, provider string) (string, er
 		return "", fmt.Errorf("unknown provider %q", provider)
 	}
 }
+
+type namedAccount interface {
+	GetName() string
+	GetBootstrapFile() string
+}
+
+func accountBootstrapPath[T namedAccount](provider, account string, accounts []T) (string, error) {
+	for _, acct := range accounts {
+		if acct.GetName() == account {
+			if acct.GetBootstrapFile() == "" {
+				return "", fmt.Errorf("%s account %q bootstrap_file is required", provider, account)
+			}
+			return acct.GetBootstrapFile(), nil
+		}
+	}
+	return "", fmt.Errorf("%s account %q not configured", provider, account)
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
+)
+
+func TestValidateOAuthBackends(t *testing.T) {
+	tests := []struct {
+		name  string
+		oauth *configv1.OAuthConfig
+		want  string
+	}{
+		{"s3", &configv1.OAuthConfig{BlobEndpoint: "https://s3.example.com", BlobBucket: "b", BlobAccessKeyFile: "/a", BlobSecretKeyFile: "/s"}, ""},
+		{"s3 missing bucket", &configv1.OAuthConfig{BlobEndpoint: "https://s3.example.com", BlobAccessKeyFile: "/a", BlobSecretKeyFile: "/s"}, "oauth.blob_bucket is required"},
+		{"file", &configv1.OAuthConfig{BlobEndpoint: "file:///var/lib/gohome/oauth-blobs"}, ""},
+		{"file relative", &configv1.OAuthConfig{BlobEndpoint: "file://oauth-blobs"}, "oauth.blob_endpoint"},
+		{"missing", &configv1.OAuthConfig{}, "oauth.blob_endpoint is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &configv1.Config{SchemaVersion: SchemaVersion, Oauth: tt.oauth}
+			applyDefaults(cfg)
+			var oauthErrs []string
+			for _, err := range ValidateAll(cfg) {
+				if strings.HasPrefix(err.Error(), "oauth") {
+					oauthErrs = append(oauthErrs, err.Error())
+				}
+			}
+			if tt.want == "" {
+				if len(oauthErrs) != 0 {
+					t.Fatalf("unexpected oauth errors: %v", oauthErrs)
+				}
+				return
+			}
+			if !strings.Contains(strings.Join(oauthErrs, "; "), tt.want) {
+				t.Fatalf("expected %q in %v", tt.want, oauthErrs)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsReservedTokenName(t *testing.T) {
+	cfg := &configv1.Config{
+		SchemaVersion: SchemaVersion,
+		Core: &configv1.CoreConfig{
+			GrpcAddr:     DefaultGRPCAddr,
+			HttpAddr:     DefaultHTTPAddr,
+			DashboardDir: DefaultDashboardDir,
+			Auth: &configv1.AuthConfig{
+				TokenFile: "/run/secrets/gohome-token",
+				Tokens:    []*configv1.NamedToken{{Name: "default", TokenFile: "/run/secrets/other"}},
+			},
+		},
+		Oauth: &configv1.OAuthConfig{BlobEndpoint: "file:///var/lib/gohome/oauth-blobs"},
+	}
+	var found bool
+	for _, err := range ValidateAll(cfg) {
+		if strings.Contains(err.Error(), `core.auth.tokens[0].name "default" is reserved`) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected reserved token name error, got %v", ValidateAll(cfg))
+	}
+}
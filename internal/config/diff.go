@@ -0,0 +1,31 @@
+package config
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
+)
+
+// PluginChanged reports whether the config a plugin is built from differs
+// between two configs. Plugin sections are Config fields named after the
+// plugin ID; the oauth section is shared by every plugin.
+func PluginChanged(prev, next *configv1.Config, pluginID string) bool {
+	if !proto.Equal(prev.GetOauth(), next.GetOauth()) {
+		return true
+	}
+
+	field := (&configv1.Config{}).ProtoReflect().Descriptor().Fields().ByName(protoreflect.Name(pluginID))
+	if field == nil || field.Message() == nil {
+		return true
+	}
+
+	prevMsg, nextMsg := prev.ProtoReflect(), next.ProtoReflect()
+	if prevMsg.Has(field) != nextMsg.Has(field) {
+		return true
+	}
+	if !prevMsg.Has(field) {
+		return false
+	}
+	return !proto.Equal(prevMsg.Get(field).Message().Interface(), nextMsg.Get(field).Message().Interface())
+}
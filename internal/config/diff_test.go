@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+
+	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
+	p1v1 "github.com/joshp123/gohome/proto/gen/plugins/p1_homewizard/v1"
+)
+
+func TestPluginChanged(t *testing.T) {
+	base := &configv1.Config{
+		P1Homewizard: &p1v1.P1HomewizardConfig{BaseUrl: "http://meter"},
+	}
+
+	if PluginChanged(base, base, "p1_homewizard") {
+		t.Fatalf("identical configs reported as changed")
+	}
+
+	tariffs := &configv1.Config{
+		P1Homewizard: &p1v1.P1HomewizardConfig{BaseUrl: "http://meter", TariffImportT1EurPerKwh: 0.3},
+	}
+	if !PluginChanged(base, tariffs, "p1_homewizard") {
+		t.Fatalf("tariff change not detected")
+	}
+	if PluginChanged(base, tariffs, "tado") {
+		t.Fatalf("unrelated plugin reported as changed")
+	}
+
+	oauth := &configv1.Config{
+		P1Homewizard: base.P1Homewizard,
+		Oauth:        &configv1.OAuthConfig{BlobBucket: "tokens"},
+	}
+	if !PluginChanged(base, oauth, "tado") {
+		t.Fatalf("oauth change should affect every plugin")
+	}
+}
@@ -51,3 +51,19 @@ func WriteDashboards(dir string, plugins []Plugin) error {
 
 	return nil
 }
+
+// RemoveDashboards deletes provisioned dashboards for plugins that were disabled.
+func RemoveDashboards(dir string, plugins []Plugin) error {
+	if dir == "" {
+		return nil
+	}
+
+	for _, plugin := range plugins {
+		pluginDir := filepath.Join(dir, plugin.Manifest().PluginID)
+		if err := os.RemoveAll(pluginDir); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove dashboard dir: %w", err)
+		}
+	}
+
+	return nil
+}
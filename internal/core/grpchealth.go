@@ -0,0 +1,162 @@
+package core
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// GRPCHealth serves grpc.health.v1 for plugin services. Each service reports
+// the health of the plugin that serves it: HEALTHY is SERVING, ERROR is
+// NOT_SERVING, and DEGRADED follows the configured rule.
+type GRPCHealth struct {
+	server   *health.Server
+	stopped  chan struct{}
+	stopOnce sync.Once
+
+	mu              sync.Mutex
+	degradedServing bool
+	services        map[string][]string // plugin ID -> served gRPC services
+	status          map[string]HealthStatus
+}
+
+// NewGRPCHealth builds a health server. degradedServing selects whether a
+// DEGRADED plugin reports SERVING or NOT_SERVING.
+func NewGRPCHealth(degradedServing bool) *GRPCHealth {
+	return &GRPCHealth{
+		server:          health.NewServer(),
+		stopped:         make(chan struct{}),
+		degradedServing: degradedServing,
+		services:        make(map[string][]string),
+		status:          make(map[string]HealthStatus),
+	}
+}
+
+// Register adds the grpc.health.v1.Health service to s.
+func (h *GRPCHealth) Register(s *grpc.Server) {
+	healthpb.RegisterHealthServer(s, healthService{Server: h.server, stopped: h.stopped})
+}
+
+// SetServices replaces the plugin ID to service mapping. Services that are no
+// longer served by any plugin report NOT_SERVING.
+func (h *GRPCHealth) SetServices(services map[string][]string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	served := make(map[string]bool)
+	for _, list := range services {
+		for _, service := range list {
+			served[service] = true
+		}
+	}
+	for _, list := range h.services {
+		for _, service := range list {
+			if !served[service] {
+				h.server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+			}
+		}
+	}
+
+	h.services = services
+	for id := range services {
+		h.applyLocked(id)
+	}
+}
+
+// SetDegradedServing changes how DEGRADED plugins are reported.
+func (h *GRPCHealth) SetDegradedServing(serving bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.degradedServing == serving {
+		return
+	}
+	h.degradedServing = serving
+	for id := range h.services {
+		h.applyLocked(id)
+	}
+}
+
+// Run mirrors health events from b until ctx is cancelled.
+func (h *GRPCHealth) Run(ctx context.Context, b *HealthBroadcaster) {
+	snapshot, events, cancel := b.Subscribe(DefaultHealthBuffer)
+	defer cancel()
+
+	for _, event := range snapshot {
+		h.observe(event)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			h.observe(event)
+		}
+	}
+}
+
+// Shutdown reports every service as NOT_SERVING so probes fail while the
+// server drains, and ends open Watch streams so they don't hold up
+// GracefulStop.
+func (h *GRPCHealth) Shutdown() {
+	h.server.Shutdown()
+	h.stopOnce.Do(func() { close(h.stopped) })
+}
+
+func (h *GRPCHealth) observe(event HealthEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if event.Removed {
+		delete(h.status, event.PluginID)
+	} else {
+		h.status[event.PluginID] = event.Status
+	}
+	h.applyLocked(event.PluginID)
+}
+
+func (h *GRPCHealth) applyLocked(pluginID string) {
+	serving := healthpb.HealthCheckResponse_NOT_SERVING
+	switch h.status[pluginID] {
+	case HealthHealthy:
+		serving = healthpb.HealthCheckResponse_SERVING
+	case HealthDegraded:
+		if h.degradedServing {
+			serving = healthpb.HealthCheckResponse_SERVING
+		}
+	}
+	for _, service := range h.services[pluginID] {
+		h.server.SetServingStatus(service, serving)
+	}
+}
+
+// healthService ends Watch streams when the daemon shuts down; the stock
+// server only returns when the client goes away.
+type healthService struct {
+	*health.Server
+	stopped <-chan struct{}
+}
+
+func (s healthService) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stopped:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return s.Server.Watch(req, &watchStream{Health_WatchServer: stream, ctx: ctx})
+}
+
+type watchStream struct {
+	healthpb.Health_WatchServer
+	ctx context.Context
+}
+
+func (w *watchStream) Context() context.Context { return w.ctx }
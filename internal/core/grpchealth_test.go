@@ -0,0 +1,116 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func checkServing(t *testing.T, h *GRPCHealth, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	resp, err := h.server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		t.Fatalf("Check(%s): %v", service, err)
+	}
+	return resp.Status
+}
+
+func TestGRPCHealthMapsPluginStatus(t *testing.T) {
+	const service = "gohome.plugins.tado.v1.TadoService"
+	h := NewGRPCHealth(true)
+	h.SetServices(map[string][]string{"tado": {service}})
+
+	h.observe(HealthEvent{PluginID: "tado", Status: HealthHealthy})
+	if got := checkServing(t, h, service); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("healthy: got %s", got)
+	}
+
+	h.observe(HealthEvent{PluginID: "tado", Status: HealthDegraded})
+	if got := checkServing(t, h, service); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("degraded (serving rule): got %s", got)
+	}
+	h.SetDegradedServing(false)
+	if got := checkServing(t, h, service); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("degraded (not serving rule): got %s", got)
+	}
+
+	h.observe(HealthEvent{PluginID: "tado", Status: HealthError})
+	if got := checkServing(t, h, service); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("error: got %s", got)
+	}
+
+	h.observe(HealthEvent{PluginID: "tado", Status: HealthHealthy})
+	h.SetServices(map[string][]string{})
+	if got := checkServing(t, h, service); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("unserved: got %s", got)
+	}
+}
+
+func TestGRPCHealthFollowsBroadcaster(t *testing.T) {
+	const service = "gohome.plugins.tado.v1.TadoService"
+	plugin := newMutablePlugin("tado")
+	b := NewHealthBroadcaster([]Plugin{plugin}, time.Hour)
+	h := NewGRPCHealth(true)
+	h.SetServices(map[string][]string{"tado": {service}})
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	go b.Run(ctx)
+	go h.Run(ctx, b)
+
+	waitFor := func(want healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if checkServing(t, h, service) == want {
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Fatalf("timed out waiting for %s", want)
+	}
+
+	waitFor(healthpb.HealthCheckResponse_SERVING)
+	plugin.set(HealthError, "token revoked")
+	waitFor(healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+type fakeHealthWatch struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent chan healthpb.HealthCheckResponse_ServingStatus
+}
+
+func (f *fakeHealthWatch) Context() context.Context { return f.ctx }
+
+func (f *fakeHealthWatch) Send(resp *healthpb.HealthCheckResponse) error {
+	f.sent <- resp.Status
+	return nil
+}
+
+func TestGRPCHealthShutdownEndsWatch(t *testing.T) {
+	const service = "gohome.plugins.tado.v1.TadoService"
+	h := NewGRPCHealth(true)
+	h.SetServices(map[string][]string{"tado": {service}})
+	h.observe(HealthEvent{PluginID: "tado", Status: HealthHealthy})
+
+	svc := healthService{Server: h.server, stopped: h.stopped}
+	stream := &fakeHealthWatch{ctx: context.Background(), sent: make(chan healthpb.HealthCheckResponse_ServingStatus, 4)}
+	done := make(chan error, 1)
+	go func() {
+		done <- svc.Watch(&healthpb.HealthCheckRequest{Service: service}, stream)
+	}()
+	if got := <-stream.sent; got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("initial status = %s", got)
+	}
+
+	h.Shutdown()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Watch still running after Shutdown")
+	}
+}
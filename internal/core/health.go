@@ -0,0 +1,234 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHealthPollInterval is how often plugin health is sampled.
+	DefaultHealthPollInterval = 15 * time.Second
+	// DefaultHealthBuffer is the per-subscriber event buffer size.
+	DefaultHealthBuffer = 16
+)
+
+// HealthEvent is a point-in-time health observation for one plugin.
+type HealthEvent struct {
+	PluginID string
+	Status   HealthStatus
+	Message  string
+	Removed  bool
+	At       time.Time
+}
+
+// HealthNotifier is implemented by plugins that can signal health changes
+// without waiting for the next poll.
+type HealthNotifier interface {
+	OnHealthChange(func())
+}
+
+// HealthBroadcaster samples plugin health and fans changes out to subscribers.
+type HealthBroadcaster struct {
+	interval time.Duration
+	notify   chan struct{}
+
+	mu      sync.Mutex
+	plugins []Plugin
+	last    map[string]HealthEvent
+	subs    map[*healthSubscriber]struct{}
+	closed  bool
+}
+
+type healthSubscriber struct {
+	ch chan HealthEvent
+}
+
+// NewHealthBroadcaster builds a broadcaster seeded with the current plugin health.
+func NewHealthBroadcaster(plugins []Plugin, interval time.Duration) *HealthBroadcaster {
+	if interval <= 0 {
+		interval = DefaultHealthPollInterval
+	}
+	b := &HealthBroadcaster{
+		interval: interval,
+		notify:   make(chan struct{}, 1),
+		plugins:  plugins,
+		last:     make(map[string]HealthEvent, len(plugins)),
+		subs:     make(map[*healthSubscriber]struct{}),
+	}
+	now := time.Now()
+	for _, plugin := range plugins {
+		b.last[plugin.ID()] = observe(plugin, now)
+		b.watch(plugin)
+	}
+	return b
+}
+
+// SetPlugins replaces the watched plugin set after a config reload. Added
+// plugins publish their current health; removed plugins publish a final
+// event with Removed set.
+func (b *HealthBroadcaster) SetPlugins(plugins []Plugin) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	keep := make(map[string]bool, len(plugins))
+	for _, plugin := range plugins {
+		keep[plugin.ID()] = true
+	}
+	for _, plugin := range b.plugins {
+		id := plugin.ID()
+		if keep[id] {
+			continue
+		}
+		event := b.last[id]
+		event.Removed = true
+		event.At = now
+		delete(b.last, id)
+		b.publishLocked(event)
+	}
+
+	b.plugins = plugins
+	for _, plugin := range plugins {
+		if _, ok := b.last[plugin.ID()]; ok {
+			continue
+		}
+		event := observe(plugin, now)
+		b.last[event.PluginID] = event
+		b.watch(plugin)
+		b.publishLocked(event)
+	}
+}
+
+// Run polls plugin health until ctx is cancelled.
+func (b *HealthBroadcaster) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.Poll()
+		case <-b.notify:
+			b.Poll()
+		}
+	}
+}
+
+// Notify requests an immediate poll; it never blocks.
+func (b *HealthBroadcaster) Notify() {
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Poll samples every plugin once and publishes any status or message change.
+func (b *HealthBroadcaster) Poll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for _, plugin := range b.plugins {
+		event := observe(plugin, now)
+		prev, ok := b.last[event.PluginID]
+		if ok && prev.Status == event.Status && prev.Message == event.Message {
+			continue
+		}
+		b.last[event.PluginID] = event
+		b.publishLocked(event)
+	}
+}
+
+// Snapshot returns the last observed health of every plugin in registration order.
+func (b *HealthBroadcaster) Snapshot() []HealthEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.snapshotLocked()
+}
+
+// Subscribe returns the current snapshot and a channel of subsequent changes.
+// When a subscriber falls behind, its oldest buffered events are dropped so the
+// latest state always gets through. cancel must be called to release it.
+func (b *HealthBroadcaster) Subscribe(buffer int) ([]HealthEvent, <-chan HealthEvent, func()) {
+	if buffer <= 0 {
+		buffer = DefaultHealthBuffer
+	}
+	sub := &healthSubscriber{ch: make(chan HealthEvent, buffer)}
+
+	b.mu.Lock()
+	snapshot := b.snapshotLocked()
+	if b.closed {
+		close(sub.ch)
+	} else {
+		b.subs[sub] = struct{}{}
+	}
+	b.mu.Unlock()
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[sub]; ok {
+			delete(b.subs, sub)
+			close(sub.ch)
+		}
+	}
+	return snapshot, sub.ch, cancel
+}
+
+// Close closes every subscriber's channel so watch streams return and the
+// gRPC server can drain; later subscribers get a closed channel.
+func (b *HealthBroadcaster) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
+
+func (b *HealthBroadcaster) snapshotLocked() []HealthEvent {
+	out := make([]HealthEvent, 0, len(b.plugins))
+	for _, plugin := range b.plugins {
+		if event, ok := b.last[plugin.ID()]; ok {
+			out = append(out, event)
+		}
+	}
+	return out
+}
+
+func (b *HealthBroadcaster) publishLocked(event HealthEvent) {
+	for sub := range b.subs {
+		select {
+		case sub.ch <- event:
+			continue
+		default:
+		}
+		// Buffer full: drop the oldest event to make room for the newest.
+		select {
+		case <-sub.ch:
+		default:
+		}
+		select {
+		case sub.ch <- event:
+		default:
+		}
+	}
+}
+
+func (b *HealthBroadcaster) watch(plugin Plugin) {
+	if notifier, ok := plugin.(HealthNotifier); ok {
+		notifier.OnHealthChange(b.Notify)
+	}
+}
+
+func observe(plugin Plugin, now time.Time) HealthEvent {
+	return HealthEvent{
+		PluginID: plugin.ID(),
+		Status:   plugin.Health(),
+		Message:  plugin.HealthMessage(),
+		At:       now,
+	}
+}
@@ -0,0 +1,231 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	registryv1 "github.com/joshp123/gohome/proto/gen/registry/v1"
+	"google.golang.org/grpc"
+)
+
+type mutablePlugin struct {
+	stubPlugin
+
+	mu       sync.Mutex
+	onChange func()
+}
+
+func newMutablePlugin(id string) *mutablePlugin {
+	return &mutablePlugin{stubPlugin: newStubPlugin(id)}
+}
+
+func (m *mutablePlugin) Health() HealthStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.health
+}
+
+func (m *mutablePlugin) HealthMessage() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.healthMessage
+}
+
+func (m *mutablePlugin) OnHealthChange(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onChange = fn
+}
+
+func (m *mutablePlugin) set(status HealthStatus, message string) {
+	m.mu.Lock()
+	m.health = status
+	m.healthMessage = message
+	notify := m.onChange
+	m.mu.Unlock()
+	if notify != nil {
+		notify()
+	}
+}
+
+type fakeWatchStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events chan *registryv1.PluginEvent
+}
+
+func (f *fakeWatchStream) Context() context.Context { return f.ctx }
+
+func (f *fakeWatchStream) Send(event *registryv1.PluginEvent) error {
+	f.events <- event
+	return nil
+}
+
+func TestHealthBroadcasterPublishesChanges(t *testing.T) {
+	plugin := newMutablePlugin("tado")
+	b := NewHealthBroadcaster([]Plugin{plugin, newStubPlugin("daikin")}, time.Hour)
+
+	snapshot, events, cancel := b.Subscribe(4)
+	defer cancel()
+	if len(snapshot) != 2 || snapshot[0].PluginID != "tado" || snapshot[1].PluginID != "daikin" {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+
+	b.Poll()
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event without change: %+v", event)
+	default:
+	}
+
+	plugin.set(HealthDegraded, "oauth refresh failed")
+	b.Poll()
+	select {
+	case event := <-events:
+		if event.PluginID != "tado" || event.Status != HealthDegraded || event.Message != "oauth refresh failed" {
+			t.Fatalf("unexpected event: %+v", event)
+		}
+	default:
+		t.Fatalf("expected health event")
+	}
+}
+
+func TestHealthBroadcasterDropsOldestForSlowSubscriber(t *testing.T) {
+	plugin := newMutablePlugin("tado")
+	b := NewHealthBroadcaster([]Plugin{plugin}, time.Hour)
+
+	_, events, cancel := b.Subscribe(1)
+	defer cancel()
+
+	plugin.set(HealthDegraded, "first")
+	b.Poll()
+	plugin.set(HealthError, "second")
+	b.Poll()
+
+	event := <-events
+	if event.Status != HealthError || event.Message != "second" {
+		t.Fatalf("expected latest event, got %+v", event)
+	}
+}
+
+func TestHealthBroadcasterNotify(t *testing.T) {
+	plugin := newMutablePlugin("tado")
+	b := NewHealthBroadcaster([]Plugin{plugin}, time.Hour)
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	go b.Run(ctx)
+
+	_, events, cancel := b.Subscribe(4)
+	defer cancel()
+
+	plugin.set(HealthError, "token revoked")
+	select {
+	case event := <-events:
+		if event.Status != HealthError {
+			t.Fatalf("unexpected event: %+v", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for notified event")
+	}
+}
+
+func TestRegistryWatchPlugins(t *testing.T) {
+	plugin := newMutablePlugin("tado")
+	b := NewHealthBroadcaster([]Plugin{plugin}, time.Hour)
+	svc := NewRegistryService([]Plugin{plugin}, b)
+
+	ctx, stop := context.WithCancel(context.Background())
+	stream := &fakeWatchStream{ctx: ctx, events: make(chan *registryv1.PluginEvent, 4)}
+	done := make(chan error, 1)
+	go func() {
+		done <- svc.WatchPlugins(&registryv1.WatchPluginsRequest{}, stream)
+	}()
+
+	first := <-stream.events
+	if first.PluginId != "tado" || first.Status != string(HealthHealthy) || !first.Snapshot {
+		t.Fatalf("unexpected snapshot event: %+v", first)
+	}
+
+	plugin.set(HealthDegraded, "refresh failed")
+	b.Poll()
+
+	select {
+	case event := <-stream.events:
+		if event.Status != string(HealthDegraded) || event.HealthMessage != "refresh failed" || event.Snapshot {
+			t.Fatalf("unexpected change event: %+v", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for change event")
+	}
+
+	stop()
+	if err := <-done; err != nil {
+		t.Fatalf("WatchPlugins error: %v", err)
+	}
+}
+
+func TestHealthBroadcasterSetPlugins(t *testing.T) {
+	tado := newMutablePlugin("tado")
+	b := NewHealthBroadcaster([]Plugin{tado}, time.Hour)
+
+	_, events, cancel := b.Subscribe(4)
+	defer cancel()
+
+	daikin := newMutablePlugin("daikin")
+	b.SetPlugins([]Plugin{daikin})
+
+	removed := <-events
+	if removed.PluginID != "tado" || !removed.Removed {
+		t.Fatalf("expected tado removal, got %+v", removed)
+	}
+	added := <-events
+	if added.PluginID != "daikin" || added.Removed || added.Status != HealthHealthy {
+		t.Fatalf("expected daikin addition, got %+v", added)
+	}
+
+	snapshot := b.Snapshot()
+	if len(snapshot) != 1 || snapshot[0].PluginID != "daikin" {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+
+	// Removed plugins are no longer polled.
+	tado.set(HealthError, "gone")
+	b.Poll()
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event after removal: %+v", event)
+	default:
+	}
+}
+
+func TestHealthBroadcasterCloseEndsWatchers(t *testing.T) {
+	plugin := newMutablePlugin("tado")
+	b := NewHealthBroadcaster([]Plugin{plugin}, time.Hour)
+	svc := NewRegistryService([]Plugin{plugin}, b)
+
+	stream := &fakeWatchStream{ctx: context.Background(), events: make(chan *registryv1.PluginEvent, 4)}
+	done := make(chan error, 1)
+	go func() {
+		done <- svc.WatchPlugins(&registryv1.WatchPluginsRequest{}, stream)
+	}()
+	<-stream.events
+
+	b.Close()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("WatchPlugins error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("WatchPlugins still running after Close")
+	}
+
+	_, events, cancel := b.Subscribe(1)
+	defer cancel()
+	if _, ok := <-events; ok {
+		t.Fatalf("subscribe after Close returned an open channel")
+	}
+}
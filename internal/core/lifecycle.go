@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// StartOrder sorts plugins so each one follows the plugins it depends on.
+// Plugins without dependencies keep their registration order.
+func StartOrder(plugins []Plugin) ([]Plugin, error) {
+	byID := make(map[string]Plugin, len(plugins))
+	for _, plugin := range plugins {
+		byID[plugin.ID()] = plugin
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	marks := make(map[string]int, len(plugins))
+	ordered := make([]Plugin, 0, len(plugins))
+
+	var visit func(plugin Plugin, path []string) error
+	visit = func(plugin Plugin, path []string) error {
+		id := plugin.ID()
+		switch marks[id] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("plugin dependency cycle: %v", append(path, id))
+		}
+		marks[id] = visiting
+		for _, dep := range plugin.Manifest().DependsOn {
+			next, ok := byID[dep]
+			if !ok {
+				return fmt.Errorf("plugin %q depends on %q, which is not enabled", id, dep)
+			}
+			if err := visit(next, append(path, id)); err != nil {
+				return err
+			}
+		}
+		marks[id] = visited
+		ordered = append(ordered, plugin)
+		return nil
+	}
+
+	for _, plugin := range plugins {
+		if err := visit(plugin, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// StartPlugins starts lifecycle plugins in order. If one fails, the plugins
+// already started are stopped again before the error is returned.
+func StartPlugins(ctx context.Context, plugins []Plugin) error {
+	started := make([]Plugin, 0, len(plugins))
+	for _, plugin := range plugins {
+		lc, ok := plugin.(Lifecycle)
+		if !ok {
+			continue
+		}
+		if err := lc.Start(ctx); err != nil {
+			stopErr := StopPlugins(context.WithoutCancel(ctx), started)
+			return errors.Join(fmt.Errorf("start %s: %w", plugin.ID(), err), stopErr)
+		}
+		started = append(started, plugin)
+	}
+	return nil
+}
+
+// StopPlugins stops lifecycle plugins in reverse order and reports every failure.
+func StopPlugins(ctx context.Context, plugins []Plugin) error {
+	var errs []error
+	for i := len(plugins) - 1; i >= 0; i-- {
+		lc, ok := plugins[i].(Lifecycle)
+		if !ok {
+			continue
+		}
+		if err := lc.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stop %s: %w", plugins[i].ID(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type lifecyclePlugin struct {
+	stubPlugin
+	log      *[]string
+	startErr error
+}
+
+func (l lifecyclePlugin) Start(context.Context) error {
+	*l.log = append(*l.log, "start "+l.id)
+	return l.startErr
+}
+
+func (l lifecyclePlugin) Stop(context.Context) error {
+	*l.log = append(*l.log, "stop "+l.id)
+	return nil
+}
+
+func TestStartOrderFollowsDependencies(t *testing.T) {
+	home := newStubPlugin("home")
+	home.dependsOn = []string{"tado", "daikin"}
+	tado := newStubPlugin("tado")
+	daikin := newStubPlugin("daikin")
+
+	ordered, err := StartOrder([]Plugin{home, tado, daikin})
+	if err != nil {
+		t.Fatalf("StartOrder error: %v", err)
+	}
+	var ids []string
+	for _, p := range ordered {
+		ids = append(ids, p.ID())
+	}
+	if want := []string{"tado", "daikin", "home"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("order = %v, want %v", ids, want)
+	}
+}
+
+func TestStartOrderRejectsCyclesAndMissing(t *testing.T) {
+	a := newStubPlugin("alpha")
+	a.dependsOn = []string{"beta"}
+	b := newStubPlugin("beta")
+	b.dependsOn = []string{"alpha"}
+	if _, err := StartOrder([]Plugin{a, b}); err == nil {
+		t.Fatalf("expected cycle error")
+	}
+
+	c := newStubPlugin("gamma")
+	c.dependsOn = []string{"missing"}
+	if _, err := StartOrder([]Plugin{c}); err == nil {
+		t.Fatalf("expected missing dependency error")
+	}
+}
+
+func TestStartPluginsRollsBackOnFailure(t *testing.T) {
+	var log []string
+	plugins := []Plugin{
+		lifecyclePlugin{stubPlugin: newStubPlugin("tado"), log: &log},
+		newStubPlugin("home"),
+		lifecyclePlugin{stubPlugin: newStubPlugin("roborock"), log: &log},
+		lifecyclePlugin{stubPlugin: newStubPlugin("daikin"), log: &log, startErr: errors.New("boom")},
+	}
+
+	if err := StartPlugins(context.Background(), plugins); err == nil {
+		t.Fatalf("expected start error")
+	}
+	want := []string{"start tado", "start roborock", "start daikin", "stop roborock", "stop tado"}
+	if !reflect.DeepEqual(log, want) {
+		t.Fatalf("log = %v, want %v", log, want)
+	}
+}
+
+func TestStopPluginsReverseOrder(t *testing.T) {
+	var log []string
+	plugins := []Plugin{
+		lifecyclePlugin{stubPlugin: newStubPlugin("tado"), log: &log},
+		lifecyclePlugin{stubPlugin: newStubPlugin("roborock"), log: &log},
+	}
+
+	if err := StopPlugins(context.Background(), plugins); err != nil {
+		t.Fatalf("StopPlugins error: %v", err)
+	}
+	if want := []string{"stop roborock", "stop tado"}; !reflect.DeepEqual(log, want) {
+		t.Fatalf("log = %v, want %v", log, want)
+	}
+}
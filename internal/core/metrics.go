@@ -1,6 +1,8 @@
 package core
 
 import (
+	"errors"
+
 	"github.com/joshp123/gohome/internal/oauth"
 	"github.com/joshp123/gohome/internal/rate"
 	"github.com/prometheus/client_golang/prometheus"
@@ -25,3 +27,30 @@ func MetricsRegistry(plugins []Plugin) *prometheus.Registry {
 
 	return registry
 }
+
+// RegisterCollectors adds plugin collectors to a live registry, e.g. after a
+// config reload enabled new plugins.
+func RegisterCollectors(registry *prometheus.Registry, plugins []Plugin) error {
+	var errs []error
+	for _, plugin := range plugins {
+		for _, collector := range plugin.Collectors() {
+			if err := registry.Register(collector); err != nil {
+				var already prometheus.AlreadyRegisteredError
+				if errors.As(err, &already) {
+					continue
+				}
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// UnregisterCollectors removes plugin collectors from a live registry.
+func UnregisterCollectors(registry *prometheus.Registry, plugins []Plugin) {
+	for _, plugin := range plugins {
+		for _, collector := range plugin.Collectors() {
+			registry.Unregister(collector)
+		}
+	}
+}
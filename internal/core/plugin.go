@@ -1,9 +1,11 @@
 package core
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/joshp123/gohome/internal/oauth"
+	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
 	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc"
 )
@@ -29,6 +31,7 @@ type Manifest struct {
 	DisplayName string
 	Version     string
 	Services    []string
+	DependsOn   []string
 }
 
 // Plugin is the compile-time contract for all GoHome plugins.
@@ -48,3 +51,19 @@ type Plugin interface {
 type HTTPRegistrant interface {
 	RegisterHTTP(*http.ServeMux)
 }
+
+// Lifecycle is implemented by plugins that own background work. Start is
+// called once after registration with a context that lives until shutdown;
+// Stop is called during shutdown and must return once that work has drained
+// or its context expires.
+type Lifecycle interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// Reconfigurable plugins accept a changed config without being rebuilt.
+// Reconfigure must validate before applying; on error the plugin keeps
+// running with its previous config.
+type Reconfigurable interface {
+	Reconfigure(cfg *configv1.Config) error
+}
@@ -5,6 +5,8 @@ import (
 	"sync"
 
 	registryv1 "github.com/joshp123/gohome/proto/gen/registry/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // RegistryService provides plugin discovery to clients.
@@ -12,11 +14,20 @@ type RegistryService struct {
 	registryv1.UnimplementedRegistryServer
 
 	plugins []Plugin
+	health  *HealthBroadcaster
 	mu      sync.RWMutex
 }
 
-func NewRegistryService(plugins []Plugin) *RegistryService {
-	return &RegistryService{plugins: plugins}
+// NewRegistryService serves plugin metadata; health streams from the broadcaster.
+func NewRegistryService(plugins []Plugin, health *HealthBroadcaster) *RegistryService {
+	return &RegistryService{plugins: plugins, health: health}
+}
+
+// SetPlugins replaces the plugin set served by the registry.
+func (r *RegistryService) SetPlugins(plugins []Plugin) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.plugins = plugins
 }
 
 func (r *RegistryService) ListPlugins(ctx context.Context, _ *registryv1.ListPluginsRequest) (*registryv1.ListPluginsResponse, error) {
@@ -74,7 +85,43 @@ func (r *RegistryService) DescribePlugin(ctx context.Context, req *registryv1.De
 	return &registryv1.DescribePluginResponse{}, nil
 }
 
-func (r *RegistryService) WatchPlugins(_ *registryv1.WatchPluginsRequest, _ registryv1.Registry_WatchPluginsServer) error {
-	// MVP: static plugin set. Streaming updates are deferred.
-	return nil
+// WatchPlugins sends a snapshot of every plugin's health, then one event per change.
+func (r *RegistryService) WatchPlugins(_ *registryv1.WatchPluginsRequest, stream registryv1.Registry_WatchPluginsServer) error {
+	if r.health == nil {
+		return status.Error(codes.Unavailable, "plugin health is not being watched")
+	}
+
+	snapshot, events, cancel := r.health.Subscribe(DefaultHealthBuffer)
+	defer cancel()
+
+	for _, event := range snapshot {
+		if err := stream.Send(pluginEvent(event, true)); err != nil {
+			return err
+		}
+	}
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(pluginEvent(event, false)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func pluginEvent(event HealthEvent, snapshot bool) *registryv1.PluginEvent {
+	return &registryv1.PluginEvent{
+		PluginId:      event.PluginID,
+		Status:        string(event.Status),
+		HealthMessage: event.Message,
+		Snapshot:      snapshot,
+		Removed:       event.Removed,
+	}
 }
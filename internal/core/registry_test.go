@@ -19,6 +19,7 @@ type stubPlugin struct {
 	agents        string
 	health        HealthStatus
 	healthMessage string
+	dependsOn     []string
 }
 
 func (s stubPlugin) ID() string { return s.id }
@@ -29,6 +30,7 @@ func (s stubPlugin) Manifest() Manifest {
 		DisplayName: s.name,
 		Version:     s.version,
 		Services:    s.services,
+		DependsOn:   s.dependsOn,
 	}
 }
 
@@ -67,7 +69,7 @@ func newStubPlugin(id string) stubPlugin {
 
 func TestRegistryListPlugins(t *testing.T) {
 	plugin := newStubPlugin("demo")
-	svc := NewRegistryService([]Plugin{plugin})
+	svc := NewRegistryService([]Plugin{plugin}, nil)
 
 	resp, err := svc.ListPlugins(context.Background(), &registryv1.ListPluginsRequest{})
 	if err != nil {
@@ -88,7 +90,7 @@ func TestRegistryListPlugins(t *testing.T) {
 
 func TestRegistryDescribePlugin(t *testing.T) {
 	plugin := newStubPlugin("demo")
-	svc := NewRegistryService([]Plugin{plugin})
+	svc := NewRegistryService([]Plugin{plugin}, nil)
 
 	resp, err := svc.DescribePlugin(context.Background(), &registryv1.DescribePluginRequest{PluginId: "demo"})
 	if err != nil {
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"sync"
+
+	"github.com/joshp123/gohome/internal/oauth"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+)
+
+// CorePluginID identifies the daemon itself in registry listings.
+const CorePluginID = "core"
+
+// CorePlugin reports the health of the GoHome daemon itself (for example a
+// failed config reload) through the same registry and health stream as the
+// real plugins. It serves no RPCs, metrics, or dashboards.
+type CorePlugin struct {
+	version string
+
+	mu       sync.Mutex
+	health   HealthStatus
+	message  string
+	onChange func()
+}
+
+var _ HealthNotifier = (*CorePlugin)(nil)
+
+// NewCorePlugin returns a healthy core plugin for the given build version.
+func NewCorePlugin(version string) *CorePlugin {
+	return &CorePlugin{version: version, health: HealthHealthy}
+}
+
+// SetHealth updates the daemon health and notifies the broadcaster.
+func (c *CorePlugin) SetHealth(status HealthStatus, message string) {
+	c.mu.Lock()
+	c.health = status
+	c.message = message
+	notify := c.onChange
+	c.mu.Unlock()
+	if notify != nil {
+		notify()
+	}
+}
+
+func (c *CorePlugin) ID() string {
+	return CorePluginID
+}
+
+func (c *CorePlugin) Manifest() Manifest {
+	return Manifest{
+		PluginID:    CorePluginID,
+		DisplayName: "GoHome Core",
+		Version:     c.version,
+	}
+}
+
+func (c *CorePlugin) AgentsMD() string {
+	return ""
+}
+
+func (c *CorePlugin) OAuthDeclaration() oauth.Declaration {
+	return oauth.Declaration{}
+}
+
+func (c *CorePlugin) Dashboards() []Dashboard {
+	return nil
+}
+
+func (c *CorePlugin) RegisterGRPC(*grpc.Server) {}
+
+func (c *CorePlugin) Collectors() []prometheus.Collector {
+	return nil
+}
+
+func (c *CorePlugin) Health() HealthStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.health
+}
+
+func (c *CorePlugin) HealthMessage() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.message
+}
+
+func (c *CorePlugin) OnHealthChange(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onChange = fn
+}
@@ -18,10 +18,69 @@ import (
 
 var ErrBlobNotFound = errors.New("oauth blob not found")
 
-// BlobStore handles state mirroring to object storage.
+// BlobStore handles state mirroring to object storage. Keys are
+// Declaration.Key(): the provider, or provider/account for named accounts.
 type BlobStore interface {
-	Load(ctx context.Context, provider string) ([]byte, error)
-	Save(ctx context.Context, provider string, data []byte) error
+	Load(ctx context.Context, key string) ([]byte, error)
+	Save(ctx context.Context, key string, data []byte) error
+}
+
+// KeyLister is implemented by stores that can enumerate their blobs.
+type KeyLister interface {
+	Keys(ctx context.Context) ([]string, error)
+}
+
+// NewBlobStore builds the store selected by oauth.blob_endpoint: a file://
+// URL keeps blobs on the local filesystem, anything else is S3-compatible
+// object storage. With oauth.blob_key_file set, blobs are encrypted.
+func NewBlobStore(cfg *configv1.OAuthConfig) (BlobStore, error) {
+	store, err := newRawBlobStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := LoadBlobKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return store, nil
+	}
+	return NewEncryptedStore(store, keys...)
+}
+
+func newRawBlobStore(cfg *configv1.OAuthConfig) (BlobStore, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("missing oauth config")
+	}
+	dir, ok, err := FileEndpointDir(cfg.BlobEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		keep := int(cfg.BlobVersions)
+		if keep == 0 {
+			keep = DefaultFileVersions
+		}
+		return NewFileStore(dir, keep), nil
+	}
+	return NewS3Store(cfg)
+}
+
+// FileEndpointDir returns the directory of a file:// blob endpoint. ok is
+// false for any other endpoint.
+func FileEndpointDir(endpoint string) (dir string, ok bool, err error) {
+	endpoint = strings.TrimSpace(endpoint)
+	if !strings.HasPrefix(endpoint, "file://") {
+		return "", false, nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", true, fmt.Errorf("parse endpoint: %w", err)
+	}
+	if u.Host != "" || !path.IsAbs(u.Path) {
+		return "", true, fmt.Errorf("file endpoint must be file:///absolute/path, got %q", endpoint)
+	}
+	return path.Clean(u.Path), true, nil
 }
 
 type S3Store struct {
@@ -76,8 +135,8 @@ func NewS3Store(cfg *configv1.OAuthConfig) (*S3Store, error) {
 	return &S3Store{client: client, bucket: bucket, prefix: prefix}, nil
 }
 
-func (s *S3Store) Load(ctx context.Context, provider string) ([]byte, error) {
-	obj, err := s.client.GetObject(ctx, s.bucket, s.key(provider), minio.GetObjectOptions{})
+func (s *S3Store) Load(ctx context.Context, key string) ([]byte, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, s.key(key), minio.GetObjectOptions{})
 	if err != nil {
 		return nil, s.wrapError(err)
 	}
@@ -94,9 +153,9 @@ func (s *S3Store) Load(ctx context.Context, provider string) ([]byte, error) {
 	return data, nil
 }
 
-func (s *S3Store) Save(ctx context.Context, provider string, data []byte) error {
+func (s *S3Store) Save(ctx context.Context, key string, data []byte) error {
 	reader := bytes.NewReader(data)
-	_, err := s.client.PutObject(ctx, s.bucket, s.key(provider), reader, int64(reader.Len()), minio.PutObjectOptions{
+	_, err := s.client.PutObject(ctx, s.bucket, s.key(key), reader, int64(reader.Len()), minio.PutObjectOptions{
 		ContentType: "application/json",
 	})
 	if err != nil {
@@ -105,8 +164,31 @@ func (s *S3Store) Save(ctx context.Context, provider string, data []byte) error
 	return nil
 }
 
-func (s *S3Store) key(provider string) string {
-	return path.Join(s.prefix, provider+".json")
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	if err := s.client.RemoveObject(ctx, s.bucket, s.key(key), minio.RemoveObjectOptions{}); err != nil {
+		return s.wrapError(err)
+	}
+	return nil
+}
+
+// Keys lists every blob under the store's prefix.
+func (s *S3Store) Keys(ctx context.Context) ([]string, error) {
+	prefix := strings.TrimSuffix(s.prefix, "/") + "/"
+	var keys []string
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return nil, s.wrapError(obj.Err)
+		}
+		name := strings.TrimPrefix(obj.Key, prefix)
+		if strings.HasSuffix(name, ".json") {
+			keys = append(keys, strings.TrimSuffix(name, ".json"))
+		}
+	}
+	return keys, nil
+}
+
+func (s *S3Store) key(key string) string {
+	return path.Join(s.prefix, key+".json")
 }
 
 func (s *S3Store) wrapError(err error) error {
@@ -1,10 +1,25 @@
 package oauth
 
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
 const (
 	FlowAuthCode = "auth_code"
 	FlowDevice   = "device"
 )
 
+// DefaultAccount names the account a provider uses when none is configured.
+// It keeps the provider's original state path and blob key.
+const DefaultAccount = "default"
+
+var accountPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
 // Declaration defines the OAuth contract a plugin must provide.
 type Declaration struct {
 	Provider       string
@@ -13,6 +28,93 @@ type Declaration struct {
 	TokenURL       string
 	DeviceAuthURL  string
 	DeviceTokenURL string
-	Scope          string
-	StatePath      string
+	// RevokeURL is the provider's RFC 7009 revocation endpoint, if any.
+	RevokeURL string
+	Scope     string
+	StatePath string
+	// PKCE sends an RFC 7636 S256 code challenge in the auth-code flow.
+	PKCE bool
+	// PublicClient marks a client with no secret: the bootstrap may omit
+	// client_secret and the client ID is sent in the request body.
+	PublicClient bool
+	// Account names one of several logins to the same provider; "" is
+	// DefaultAccount. Set it with ForAccount.
+	Account string
+}
+
+// ForAccount returns the declaration for a named account of the same
+// provider. Named accounts get their own state file (tado-token.json becomes
+// tado-token-holiday.json) and blob key (tado/holiday).
+func (d Declaration) ForAccount(account string) Declaration {
+	if account == DefaultAccount {
+		account = ""
+	}
+	d.Account = account
+	if account != "" && d.StatePath != "" {
+		ext := filepath.Ext(d.StatePath)
+		d.StatePath = strings.TrimSuffix(d.StatePath, ext) + "-" + account + ext
+	}
+	return d
+}
+
+// Key identifies the provider and account: "tado" or "tado/holiday". It is
+// the blob store key for the account's state.
+func (d Declaration) Key() string {
+	if d.Account == "" {
+		return d.Provider
+	}
+	return d.Provider + "/" + d.Account
+}
+
+// AccountName returns the account's name, DefaultAccount when unnamed.
+func (d Declaration) AccountName() string {
+	if d.Account == "" {
+		return DefaultAccount
+	}
+	return d.Account
+}
+
+// ValidateAccount checks that name can be used as an account name.
+func ValidateAccount(name string) error {
+	if !accountPattern.MatchString(name) {
+		return fmt.Errorf("invalid account name %q: use lowercase letters, digits, - or _", name)
+	}
+	return nil
+}
+
+// ValidateBootstrap checks bootstrap credentials against the declaration.
+// Auth-code clients need a client_secret unless declared public.
+func (d Declaration) ValidateBootstrap(b Bootstrap) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	if d.Flow == FlowAuthCode && !d.PublicClient && b.ClientSecret == "" {
+		return fmt.Errorf("bootstrap missing client_secret (provider %q is not a public client)", d.Provider)
+	}
+	return nil
+}
+
+// AuthStyle is how the client authenticates to the token endpoint. Public
+// clients send their ID in the body; others let oauth2 probe for the style.
+func (d Declaration) AuthStyle() oauth2.AuthStyle {
+	if d.PublicClient {
+		return oauth2.AuthStyleInParams
+	}
+	return oauth2.AuthStyleAutoDetect
+}
+
+// ReauthCommand is the gohome command that authorizes this provider's
+// account again.
+func (d Declaration) ReauthCommand() string {
+	var cmd string
+	switch d.Flow {
+	case FlowDevice:
+		cmd = "gohome oauth device --provider " + d.Provider
+	default:
+		cmd = "gohome oauth auth-code --provider " + d.Provider + " --redirect-url <url>"
+	}
+	if d.Account != "" {
+		cmd += " --account " + d.Account
+	}
+	return cmd
 }
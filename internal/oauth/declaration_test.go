@@ -0,0 +1,61 @@
+package oauth
+
+import "testing"
+
+func TestDeclarationForAccount(t *testing.T) {
+	base := Declaration{Provider: "tado", StatePath: "/var/lib/gohome/tado-token.json"}
+
+	tests := []struct {
+		account   string
+		key       string
+		statePath string
+		name      string
+	}{
+		{"", "tado", "/var/lib/gohome/tado-token.json", "default"},
+		{"default", "tado", "/var/lib/gohome/tado-token.json", "default"},
+		{"holiday", "tado/holiday", "/var/lib/gohome/tado-token-holiday.json", "holiday"},
+	}
+	for _, tt := range tests {
+		decl := base.ForAccount(tt.account)
+		if decl.Key() != tt.key {
+			t.Errorf("ForAccount(%q).Key() = %q, want %q", tt.account, decl.Key(), tt.key)
+		}
+		if decl.StatePath != tt.statePath {
+			t.Errorf("ForAccount(%q).StatePath = %q, want %q", tt.account, decl.StatePath, tt.statePath)
+		}
+		if decl.AccountName() != tt.name {
+			t.Errorf("ForAccount(%q).AccountName() = %q, want %q", tt.account, decl.AccountName(), tt.name)
+		}
+	}
+}
+
+func TestValidateAccount(t *testing.T) {
+	for _, name := range []string{"holiday", "flat-2", "mum_dad"} {
+		if err := ValidateAccount(name); err != nil {
+			t.Errorf("ValidateAccount(%q): %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "Holiday", "../etc", "a/b", "-x"} {
+		if err := ValidateAccount(name); err == nil {
+			t.Errorf("ValidateAccount(%q) accepted an invalid name", name)
+		}
+	}
+}
+
+func TestValidateBootstrapClientSecret(t *testing.T) {
+	confidential := Declaration{Provider: "p", Flow: FlowAuthCode}
+	if err := confidential.ValidateBootstrap(Bootstrap{ClientID: "id"}); err == nil {
+		t.Error("auth-code client without secret accepted")
+	}
+	if err := confidential.ValidateBootstrap(Bootstrap{ClientID: "id", ClientSecret: "s"}); err != nil {
+		t.Errorf("confidential client: %v", err)
+	}
+	public := Declaration{Provider: "p", Flow: FlowAuthCode, PKCE: true, PublicClient: true}
+	if err := public.ValidateBootstrap(Bootstrap{ClientID: "id"}); err != nil {
+		t.Errorf("public client: %v", err)
+	}
+	device := Declaration{Provider: "p", Flow: FlowDevice}
+	if err := device.ValidateBootstrap(Bootstrap{ClientID: "id"}); err != nil {
+		t.Errorf("device client: %v", err)
+	}
+}
@@ -0,0 +1,258 @@
+package oauth
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
+)
+
+// Encrypted blobs start with a fixed header:
+//
+//	magic "GHEB" | version (1 byte) | key id (4 bytes) | nonce (12 bytes)
+//
+// followed by the AES-256-GCM ciphertext. The header and the blob key are
+// authenticated as additional data, so a blob cannot be replayed under
+// another provider's key.
+const (
+	blobMagic        = "GHEB"
+	blobVersion1     = 1
+	blobKeyIDSize    = 4
+	blobHeaderSize   = len(blobMagic) + 1 + blobKeyIDSize + 12
+	minBlobKeySecret = 32
+)
+
+var ErrBlobKeyUnknown = errors.New("oauth blob encrypted with an unknown key")
+
+// BlobKey is an AES-256 key derived from a secret file.
+type BlobKey struct {
+	id   [blobKeyIDSize]byte
+	aead cipher.AEAD
+}
+
+// LoadBlobKey derives a blob key from the secret in path. The file holds at
+// least 32 bytes of secret material (e.g. `openssl rand -base64 32`);
+// surrounding whitespace is ignored.
+func LoadBlobKey(path string) (BlobKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return BlobKey{}, fmt.Errorf("read blob key: %w", err)
+	}
+	return NewBlobKey(bytes.TrimSpace(data))
+}
+
+// NewBlobKey derives a blob key from secret.
+func NewBlobKey(secret []byte) (BlobKey, error) {
+	if len(secret) < minBlobKeySecret {
+		return BlobKey{}, fmt.Errorf("blob key must be at least %d bytes, got %d", minBlobKeySecret, len(secret))
+	}
+	key, err := hkdf.Key(sha256.New, secret, nil, "gohome oauth blob v1", 32)
+	if err != nil {
+		return BlobKey{}, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return BlobKey{}, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return BlobKey{}, err
+	}
+	k := BlobKey{aead: aead}
+	sum := sha256.Sum256(key)
+	copy(k.id[:], sum[:])
+	return k, nil
+}
+
+// ID identifies the key in blob headers without revealing it.
+func (k BlobKey) ID() string {
+	return hex.EncodeToString(k.id[:])
+}
+
+// IsEncryptedBlob reports whether data carries the encrypted blob header.
+func IsEncryptedBlob(data []byte) bool {
+	return len(data) >= blobHeaderSize && string(data[:len(blobMagic)]) == blobMagic
+}
+
+// blobProtection identifies how a stored blob is protected: the key id of
+// an encrypted blob, or "" for plaintext.
+func blobProtection(data []byte) string {
+	if !IsEncryptedBlob(data) {
+		return ""
+	}
+	return hex.EncodeToString(data[len(blobMagic)+1 : len(blobMagic)+1+blobKeyIDSize])
+}
+
+// EncryptedStore encrypts blobs before handing them to the wrapped store.
+// Save always uses the first key; Load accepts any of the keys, and passes
+// plaintext blobs written before encryption was enabled through unchanged,
+// so they are encrypted on their next save.
+type EncryptedStore struct {
+	inner BlobStore
+	keys  []BlobKey
+}
+
+// NewEncryptedStore wraps inner. keys[0] encrypts; later keys are only used
+// to decrypt blobs not yet re-encrypted after a rekey.
+func NewEncryptedStore(inner BlobStore, keys ...BlobKey) (*EncryptedStore, error) {
+	if inner == nil {
+		return nil, fmt.Errorf("blob store is required")
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("at least one blob key is required")
+	}
+	return &EncryptedStore{inner: inner, keys: keys}, nil
+}
+
+func (s *EncryptedStore) Load(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.inner.Load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if !IsEncryptedBlob(data) {
+		return data, nil
+	}
+	return s.open(key, data)
+}
+
+func (s *EncryptedStore) Save(ctx context.Context, key string, data []byte) error {
+	sealed, err := s.seal(key, data)
+	if err != nil {
+		return err
+	}
+	return s.inner.Save(ctx, key, sealed)
+}
+
+// Delete removes a blob when the wrapped store supports it.
+func (s *EncryptedStore) Delete(ctx context.Context, key string) error {
+	deleter, ok := s.inner.(BlobDeleter)
+	if !ok {
+		return fmt.Errorf("%T cannot delete blobs", s.inner)
+	}
+	return deleter.Delete(ctx, key)
+}
+
+// Keys lists the wrapped store's keys when it supports listing.
+func (s *EncryptedStore) Keys(ctx context.Context) ([]string, error) {
+	lister, ok := s.inner.(KeyLister)
+	if !ok {
+		return nil, fmt.Errorf("%T cannot list blobs", s.inner)
+	}
+	return lister.Keys(ctx)
+}
+
+func (s *EncryptedStore) seal(key string, plaintext []byte) ([]byte, error) {
+	k := s.keys[0]
+	header := make([]byte, blobHeaderSize)
+	copy(header, blobMagic)
+	header[len(blobMagic)] = blobVersion1
+	copy(header[len(blobMagic)+1:], k.id[:])
+	if _, err := rand.Read(header[len(blobMagic)+1+blobKeyIDSize:]); err != nil {
+		return nil, fmt.Errorf("blob nonce: %w", err)
+	}
+	nonce := header[len(blobMagic)+1+blobKeyIDSize:]
+	return k.aead.Seal(header, nonce, plaintext, additionalData(header, key)), nil
+}
+
+func (s *EncryptedStore) open(key string, data []byte) ([]byte, error) {
+	header := data[:blobHeaderSize]
+	if version := header[len(blobMagic)]; version != blobVersion1 {
+		return nil, fmt.Errorf("blob %s: unsupported encryption version %d", key, version)
+	}
+	id := header[len(blobMagic)+1 : len(blobMagic)+1+blobKeyIDSize]
+	nonce := header[len(blobMagic)+1+blobKeyIDSize:]
+	for _, k := range s.keys {
+		if !bytes.Equal(k.id[:], id) {
+			continue
+		}
+		plaintext, err := k.aead.Open(nil, nonce, data[blobHeaderSize:], additionalData(header, key))
+		if err != nil {
+			return nil, fmt.Errorf("blob %s: decrypt: %w", key, err)
+		}
+		return plaintext, nil
+	}
+	return nil, fmt.Errorf("blob %s (key id %s): %w", key, hex.EncodeToString(id), ErrBlobKeyUnknown)
+}
+
+func additionalData(header []byte, key string) []byte {
+	return append(append([]byte{}, header...), key...)
+}
+
+// LoadBlobKeys loads oauth.blob_key_file followed by
+// oauth.blob_previous_key_files. It returns no keys when encryption is off.
+func LoadBlobKeys(cfg *configv1.OAuthConfig) ([]BlobKey, error) {
+	if strings.TrimSpace(cfg.GetBlobKeyFile()) == "" {
+		if len(cfg.GetBlobPreviousKeyFiles()) > 0 {
+			return nil, fmt.Errorf("blob_previous_key_files requires blob_key_file")
+		}
+		return nil, nil
+	}
+	paths := append([]string{cfg.GetBlobKeyFile()}, cfg.GetBlobPreviousKeyFiles()...)
+	keys := make([]BlobKey, 0, len(paths))
+	for _, path := range paths {
+		key, err := LoadBlobKey(strings.TrimSpace(path))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Rekey re-encrypts every blob in the configured store under newKey. Blobs
+// are read with the configured keys (plaintext blobs are accepted), so it
+// also encrypts a store for the first time. On a file:// store the rotated
+// versions under the old protection are deleted. It returns the rewritten
+// keys.
+func Rekey(ctx context.Context, cfg *configv1.OAuthConfig, newKey BlobKey) ([]string, error) {
+	raw, err := newRawBlobStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	lister, ok := raw.(KeyLister)
+	if !ok {
+		return nil, fmt.Errorf("%T cannot list blobs", raw)
+	}
+	keys, err := lister.Keys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list blobs: %w", err)
+	}
+
+	oldKeys, err := LoadBlobKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// Also accept blobs already under the new key, so an interrupted rekey
+	// can simply be rerun.
+	reader, err := NewEncryptedStore(raw, append(oldKeys, newKey)...)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := NewEncryptedStore(raw, newKey)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make([]string, 0, len(keys))
+	for _, key := range keys {
+		data, err := reader.Load(ctx, key)
+		if err != nil {
+			return done, fmt.Errorf("load %s: %w", key, err)
+		}
+		if err := writer.Save(ctx, key, data); err != nil {
+			return done, fmt.Errorf("save %s: %w", key, err)
+		}
+		done = append(done, key)
+	}
+	return done, nil
+}
@@ -0,0 +1,203 @@
+package oauth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
+)
+
+func testBlobKey(t *testing.T, seed string) BlobKey {
+	t.Helper()
+	key, err := NewBlobKey([]byte(strings.Repeat(seed, 32)))
+	if err != nil {
+		t.Fatalf("NewBlobKey: %v", err)
+	}
+	return key
+}
+
+func TestEncryptedStoreRoundTrip(t *testing.T) {
+	raw := NewFileStore(t.TempDir(), 0)
+	store, err := NewEncryptedStore(raw, testBlobKey(t, "a"))
+	if err != nil {
+		t.Fatalf("NewEncryptedStore: %v", err)
+	}
+	ctx := context.Background()
+	secret := []byte(`{"refresh_token":"very-secret"}`)
+
+	if err := store.Save(ctx, "tado", secret); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	stored, _ := raw.Load(ctx, "tado")
+	if !IsEncryptedBlob(stored) || bytes.Contains(stored, []byte("very-secret")) {
+		t.Fatalf("blob stored in plaintext: %q", stored)
+	}
+	got, err := store.Load(ctx, "tado")
+	if err != nil || !bytes.Equal(got, secret) {
+		t.Fatalf("Load = %q, %v", got, err)
+	}
+
+	// The blob key is authenticated: a blob copied to another key fails.
+	if err := raw.Save(ctx, "daikin", stored); err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	if _, err := store.Load(ctx, "daikin"); err == nil {
+		t.Fatal("expected swapped blob to fail authentication")
+	}
+
+	// A store with a different key reports the key as unknown.
+	other, _ := NewEncryptedStore(raw, testBlobKey(t, "b"))
+	if _, err := other.Load(ctx, "tado"); !errors.Is(err, ErrBlobKeyUnknown) {
+		t.Fatalf("Load with other key = %v, want ErrBlobKeyUnknown", err)
+	}
+}
+
+func TestEncryptedStoreReadsPlaintext(t *testing.T) {
+	raw := NewFileStore(t.TempDir(), 0)
+	ctx := context.Background()
+	if err := raw.Save(ctx, "tado", []byte(`{"refresh_token":"legacy"}`)); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	store, _ := NewEncryptedStore(raw, testBlobKey(t, "a"))
+	got, err := store.Load(ctx, "tado")
+	if err != nil || string(got) != `{"refresh_token":"legacy"}` {
+		t.Fatalf("Load = %q, %v", got, err)
+	}
+}
+
+func TestRekey(t *testing.T) {
+	dir := t.TempDir()
+	blobs := filepath.Join(dir, "blobs")
+	oldKeyFile := filepath.Join(dir, "old.key")
+	newKeyFile := filepath.Join(dir, "new.key")
+	if err := os.WriteFile(oldKeyFile, []byte(strings.Repeat("o", 44)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(newKeyFile, []byte(strings.Repeat("n", 44)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &configv1.OAuthConfig{BlobEndpoint: "file://" + blobs, BlobKeyFile: oldKeyFile}
+	ctx := context.Background()
+
+	store, err := NewBlobStore(cfg)
+	if err != nil {
+		t.Fatalf("NewBlobStore: %v", err)
+	}
+	for _, key := range []string{"tado", "tado/holiday", "daikin"} {
+		if err := store.Save(ctx, key, []byte(key+"-state")); err != nil {
+			t.Fatalf("Save %s: %v", key, err)
+		}
+	}
+
+	newKey, err := LoadBlobKey(newKeyFile)
+	if err != nil {
+		t.Fatalf("LoadBlobKey: %v", err)
+	}
+	done, err := Rekey(ctx, cfg, newKey)
+	if err != nil {
+		t.Fatalf("Rekey: %v", err)
+	}
+	sort.Strings(done)
+	if strings.Join(done, ",") != "daikin,tado,tado/holiday" {
+		t.Fatalf("rekeyed %v", done)
+	}
+
+	// Only the new key is needed afterwards.
+	rekeyed, err := NewBlobStore(&configv1.OAuthConfig{BlobEndpoint: cfg.BlobEndpoint, BlobKeyFile: newKeyFile})
+	if err != nil {
+		t.Fatalf("NewBlobStore: %v", err)
+	}
+	for _, key := range done {
+		got, err := rekeyed.Load(ctx, key)
+		if err != nil || string(got) != key+"-state" {
+			t.Fatalf("Load %s = %q, %v", key, got, err)
+		}
+	}
+
+	// Rerunning after the config switched to the new key is harmless.
+	cfg.BlobKeyFile = newKeyFile
+	cfg.BlobPreviousKeyFiles = []string{oldKeyFile}
+	if _, err := Rekey(ctx, cfg, newKey); err != nil {
+		t.Fatalf("second Rekey: %v", err)
+	}
+}
+
+func TestEnableEncryptionLeavesNoPlaintext(t *testing.T) {
+	dir := t.TempDir()
+	raw := NewFileStore(dir, 3)
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if err := raw.Save(ctx, "tado", []byte(`{"refresh_token":"plain-secret"}`)); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	if _, err := raw.Version("tado", 2); err != nil {
+		t.Fatalf("expected plaintext history before encryption: %v", err)
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "blob.key")
+	if err := os.WriteFile(keyFile, []byte(strings.Repeat("k", 44)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &configv1.OAuthConfig{BlobEndpoint: "file://" + dir, BlobVersions: 3}
+	key, err := LoadBlobKey(keyFile)
+	if err != nil {
+		t.Fatalf("LoadBlobKey: %v", err)
+	}
+	if _, err := Rekey(ctx, cfg, key); err != nil {
+		t.Fatalf("Rekey: %v", err)
+	}
+
+	assertNoSecret := func(when string) {
+		t.Helper()
+		err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if bytes.Contains(data, []byte("plain-secret")) || !IsEncryptedBlob(data) {
+				t.Errorf("%s: %s is not encrypted under the current key: %q", when, path, data)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("walk: %v", err)
+		}
+	}
+	assertNoSecret("after encrypting")
+
+	// Saves under the same key keep history again.
+	store, _ := NewEncryptedStore(raw, key)
+	if err := store.Save(ctx, "tado", []byte(`{"refresh_token":"plain-secret"}`)); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := raw.Version("tado", 1); err != nil {
+		t.Fatalf("encrypted saves should rotate: %v", err)
+	}
+	assertNoSecret("after an encrypted save")
+
+	// A rekey drops the versions under the retired key.
+	next := testBlobKey(t, "n")
+	cfg.BlobKeyFile = keyFile
+	if _, err := Rekey(ctx, cfg, next); err != nil {
+		t.Fatalf("second Rekey: %v", err)
+	}
+	if _, err := raw.Version("tado", 1); !errors.Is(err, ErrBlobNotFound) {
+		t.Fatalf("version under the retired key kept: %v", err)
+	}
+}
+
+func TestNewBlobKeyRejectsShortSecrets(t *testing.T) {
+	if _, err := NewBlobKey([]byte("short")); err == nil {
+		t.Fatal("expected short secret to be rejected")
+	}
+}
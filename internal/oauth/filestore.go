@@ -0,0 +1,183 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultFileVersions is how many rotated versions a file:// blob store keeps
+// when oauth.blob_versions is unset.
+const DefaultFileVersions = 3
+
+// FileStore keeps blobs as <dir>/<key>.json on the local filesystem. Writes
+// go to a temp file that is renamed into place, so readers never see a
+// partial blob. Before a key is overwritten its current contents are rotated
+// to <key>.json.1 … <key>.json.N, unless the new blob is protected
+// differently (plaintext versus encrypted, or another key): then the old
+// versions are deleted so no plaintext or retired-key copy is left behind.
+type FileStore struct {
+	dir  string
+	keep int
+}
+
+// NewFileStore stores blobs under dir, keeping keep rotated versions per
+// key (0 keeps none).
+func NewFileStore(dir string, keep int) *FileStore {
+	return &FileStore{dir: dir, keep: max(keep, 0)}
+}
+
+func (s *FileStore) Load(_ context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	return data, err
+}
+
+func (s *FileStore) Save(_ context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	if err := s.rotate(path, data); err != nil {
+		return fmt.Errorf("rotate %s: %w", key, err)
+	}
+	return writeFileAtomic(path, data)
+}
+
+// Delete removes a blob and its rotated versions.
+func (s *FileStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Join(err, removeVersions(path))
+	}
+	return removeVersions(path)
+}
+
+// removeVersions deletes every rotated version of path.
+func removeVersions(path string) error {
+	versions, err := filepath.Glob(path + ".[0-9]*")
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, p := range versions {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Version loads the n-th most recent rotated version of key (1 is the one
+// written before the current blob).
+func (s *FileStore) Version(key string, n int) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(fmt.Sprintf("%s.%d", path, n))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	return data, err
+}
+
+// Keys lists every current blob; rotated versions and temp files are skipped.
+func (s *FileStore) Keys(_ context.Context) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) && path == s.dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, filepath.ToSlash(strings.TrimSuffix(rel, ".json")))
+		return nil
+	})
+	return keys, err
+}
+
+// rotate shifts path.1 … path.N-1 up by one and copies the current blob to
+// path.1. The current blob stays in place until next replaces it. When next
+// is protected differently from the current blob, the versions are deleted
+// instead.
+func (s *FileStore) rotate(path string, next []byte) error {
+	current, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if blobProtection(current) != blobProtection(next) {
+		return removeVersions(path)
+	}
+	if s.keep == 0 {
+		return nil
+	}
+	for i := s.keep - 1; i >= 1; i-- {
+		err := os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return writeFileAtomic(path+".1", current)
+}
+
+func (s *FileStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, clean+".json"), nil
+}
+
+// writeFileAtomic writes data to a 0600 temp file next to path, syncs it and
+// renames it over path.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".blob-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,104 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
+)
+
+func TestFileStoreRotatesVersions(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(filepath.Join(dir, "oauth"), 2)
+	ctx := context.Background()
+
+	if _, err := store.Load(ctx, "tado"); !errors.Is(err, ErrBlobNotFound) {
+		t.Fatalf("Load missing = %v, want ErrBlobNotFound", err)
+	}
+	for i := 1; i <= 4; i++ {
+		if err := store.Save(ctx, "tado", []byte(fmt.Sprintf("v%d", i))); err != nil {
+			t.Fatalf("Save v%d: %v", i, err)
+		}
+	}
+
+	data, err := store.Load(ctx, "tado")
+	if err != nil || string(data) != "v4" {
+		t.Fatalf("Load = %q, %v; want v4", data, err)
+	}
+	for n, want := range map[int]string{1: "v3", 2: "v2"} {
+		data, err := store.Version("tado", n)
+		if err != nil || string(data) != want {
+			t.Fatalf("Version(%d) = %q, %v; want %s", n, data, err, want)
+		}
+	}
+	if _, err := store.Version("tado", 3); !errors.Is(err, ErrBlobNotFound) {
+		t.Fatalf("Version(3) = %v, want ErrBlobNotFound (only 2 kept)", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "oauth"))
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			t.Fatalf("stat %s: %v", entry.Name(), err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o600 {
+			t.Errorf("%s perm = %o, want 600", entry.Name(), perm)
+		}
+	}
+	if len(entries) != 3 {
+		t.Errorf("expected tado.json plus 2 versions and no temp files, got %d entries", len(entries))
+	}
+	info, err := os.Stat(filepath.Join(dir, "oauth"))
+	if err != nil || info.Mode().Perm() != 0o700 {
+		t.Fatalf("dir mode = %v, %v; want 0700", info.Mode().Perm(), err)
+	}
+}
+
+func TestFileStoreAccountKeys(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(dir, 0)
+	ctx := context.Background()
+
+	if err := store.Save(ctx, "tado/holiday", []byte("x")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tado", "holiday.json")); err != nil {
+		t.Fatalf("expected nested blob: %v", err)
+	}
+	if _, err := store.Version("tado/holiday", 1); !errors.Is(err, ErrBlobNotFound) {
+		t.Fatalf("keep=0 should not rotate, got %v", err)
+	}
+	for _, key := range []string{"", "../escape", "/etc/passwd"} {
+		if err := store.Save(ctx, key, []byte("x")); err == nil {
+			t.Errorf("Save(%q) accepted an invalid key", key)
+		}
+	}
+}
+
+func TestNewBlobStoreFileEndpoint(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewBlobStore(&configv1.OAuthConfig{BlobEndpoint: "file://" + dir})
+	if err != nil {
+		t.Fatalf("NewBlobStore: %v", err)
+	}
+	fs, ok := store.(*FileStore)
+	if !ok {
+		t.Fatalf("expected *FileStore, got %T", store)
+	}
+	if fs.dir != dir || fs.keep != DefaultFileVersions {
+		t.Fatalf("unexpected store %+v", fs)
+	}
+
+	for _, endpoint := range []string{"file://relative/path", "file://host/path"} {
+		if _, err := NewBlobStore(&configv1.OAuthConfig{BlobEndpoint: endpoint}); err == nil {
+			t.Errorf("NewBlobStore(%q) accepted an invalid endpoint", endpoint)
+		}
+	}
+}
@@ -18,6 +18,15 @@ import (
 
 var ErrScopeMismatch = errors.New("oauth scope mismatch")
 
+// ErrRevoked is returned by refreshes after Revoke; the provider must be
+// authorized again.
+var ErrRevoked = errors.New("oauth credentials revoked; authorize again")
+
+// ErrReauthRequired is returned when the provider rejects the refresh token
+// (invalid_grant): it expired, was revoked or was reused after rotation.
+// Only a new authorization fixes it.
+var ErrReauthRequired = errors.New("oauth refresh token rejected; authorize again")
+
 // Manager manages OAuth refresh tokens and access token caching.
 type Manager struct {
 	decl          Declaration
@@ -29,11 +38,20 @@ type Manager struct {
 	accessToken     string
 	expiresAt       time.Time
 	refreshToken    string
+	issuedAt        time.Time
+	rotations       int
 	scope           string
 	clientID        string
 	clientSecret    string
 	refreshInFlight bool
+	refreshDone     chan struct{}
+	lastRefresh     time.Time
+	lastErr         error
+	revoked         bool
+	reauth          bool
 	config          *oauth2.Config
+	loopCancel      context.CancelFunc
+	loopDone        chan struct{}
 }
 
 func NewManager(decl Declaration, bootstrapPath string, blobStore BlobStore) (*Manager, error) {
@@ -52,6 +70,11 @@ func NewManagerFromBootstrap(decl Declaration, bootstrap Bootstrap, blobStore Bl
 	if decl.Provider == "" {
 		return nil, fmt.Errorf("provider is required")
 	}
+	if decl.Account != "" {
+		if err := ValidateAccount(decl.Account); err != nil {
+			return nil, err
+		}
+	}
 	if decl.Scope == "" {
 		return nil, fmt.Errorf("scope is required")
 	}
@@ -67,7 +90,7 @@ func NewManagerFromBootstrap(decl Declaration, bootstrap Bootstrap, blobStore Bl
 	if blobStore == nil {
 		return nil, fmt.Errorf("blob store is required")
 	}
-	if err := bootstrap.Validate(); err != nil {
+	if err := decl.ValidateBootstrap(bootstrap); err != nil {
 		return nil, fmt.Errorf("bootstrap: %w", err)
 	}
 
@@ -81,8 +104,9 @@ func NewManagerFromBootstrap(decl Declaration, bootstrap Bootstrap, blobStore Bl
 			ClientID:     bootstrap.ClientID,
 			ClientSecret: bootstrap.ClientSecret,
 			Endpoint: oauth2.Endpoint{
-				AuthURL:  decl.AuthorizeURL,
-				TokenURL: decl.TokenURL,
+				AuthURL:   decl.AuthorizeURL,
+				TokenURL:  decl.TokenURL,
+				AuthStyle: decl.AuthStyle(),
 			},
 			Scopes: strings.Fields(decl.Scope),
 		},
@@ -95,7 +119,15 @@ func NewManagerFromBootstrap(decl Declaration, bootstrap Bootstrap, blobStore Bl
 
 	m.refreshToken = state.RefreshToken
 	m.scope = state.Scope
+	m.issuedAt = state.RefreshTokenIssuedAt
+	m.rotations = state.RefreshTokenRotations
+	if m.issuedAt.IsZero() {
+		// Older states don't record when the token was issued; count its
+		// age from now.
+		m.issuedAt = time.Now().UTC()
+	}
 
+	register(m)
 	return m, nil
 }
 
@@ -111,21 +143,56 @@ func (m *Manager) StartWithInterval(ctx context.Context, interval time.Duration)
 	if threshold < 30*time.Second {
 		threshold = 30 * time.Second
 	}
-	m.refreshIfNeeded(ctx, threshold)
+
+	// A second Start keeps the running loop; Stop only knows about one.
+	m.mu.Lock()
+	if m.loopCancel != nil {
+		m.mu.Unlock()
+		return
+	}
+	loopCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	m.loopCancel = cancel
+	m.loopDone = done
+	m.mu.Unlock()
+
+	m.refreshIfNeeded(loopCtx, threshold)
+
 	go func() {
+		defer close(done)
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 		for {
 			select {
-			case <-ctx.Done():
+			case <-loopCtx.Done():
 				return
 			case <-ticker.C:
-				m.refreshIfNeeded(ctx, threshold)
+				m.refreshIfNeeded(loopCtx, threshold)
 			}
 		}
 	}()
 }
 
+// Stop ends the background refresh loop and waits for it to exit.
+func (m *Manager) Stop(ctx context.Context) error {
+	unregister(m)
+	m.mu.Lock()
+	cancel, done := m.loopCancel, m.loopDone
+	m.loopCancel, m.loopDone = nil, nil
+	m.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (m *Manager) AccessToken(ctx context.Context) (string, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -134,55 +201,124 @@ func (m *Manager) AccessToken(ctx context.Context) (string, error) {
 		return m.accessToken, nil
 	}
 
-	tokenValid.WithLabelValues(m.decl.Provider).Set(0)
+	tokenValid.WithLabelValues(m.decl.Provider, m.decl.AccountName()).Set(0)
 	return "", fmt.Errorf("oauth token unavailable")
 }
 
 func (m *Manager) TriggerRefresh(ctx context.Context) {
-	m.mu.Lock()
-	if m.refreshInFlight {
-		m.mu.Unlock()
+	if !m.beginRefresh() {
 		return
 	}
-	m.refreshInFlight = true
-	m.mu.Unlock()
-
 	go func() {
-		defer func() {
-			m.mu.Lock()
-			m.refreshInFlight = false
-			m.mu.Unlock()
-		}()
+		defer m.endRefresh()
 		_ = m.refresh(ctx)
 	}()
 }
 
+// Refresh refreshes the access token now and waits for the result. If a
+// refresh is already in flight it waits for that one instead.
+func (m *Manager) Refresh(ctx context.Context) error {
+	if m.beginRefresh() {
+		defer m.endRefresh()
+		return m.refresh(ctx)
+	}
+	m.mu.Lock()
+	done := m.refreshDone
+	m.mu.Unlock()
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastErr
+}
+
 func (m *Manager) refreshIfNeeded(ctx context.Context, threshold time.Duration) {
 	m.mu.Lock()
 	need := m.accessToken == "" || time.Until(m.expiresAt) <= threshold
-	if !need || m.refreshInFlight {
-		m.mu.Unlock()
+	m.mu.Unlock()
+	if !need || !m.beginRefresh() {
 		return
 	}
+	defer m.endRefresh()
+	_ = m.refresh(ctx)
+}
+
+// beginRefresh marks a refresh as in flight. It returns false if one
+// already is.
+func (m *Manager) beginRefresh() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.refreshInFlight {
+		return false
+	}
 	m.refreshInFlight = true
-	m.mu.Unlock()
+	m.refreshDone = make(chan struct{})
+	return true
+}
+
+func (m *Manager) endRefresh() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refreshInFlight = false
+	close(m.refreshDone)
+	m.refreshDone = nil
+}
 
+// refresh exchanges the refresh token and records the outcome for Status.
+func (m *Manager) refresh(ctx context.Context) (err error) {
 	defer func() {
 		m.mu.Lock()
-		m.refreshInFlight = false
+		m.lastRefresh = time.Now()
+		m.lastErr = err
 		m.mu.Unlock()
 	}()
 
-	_ = m.refresh(ctx)
-}
+	m.mu.Lock()
+	refreshToken, revoked, reauth := m.refreshToken, m.revoked, m.reauth
+	m.mu.Unlock()
+	if revoked {
+		// Revoke wiped the state file and blob; wait for a new
+		// authorization to write them again.
+		if !m.adoptStoredToken(ctx, "") {
+			return ErrRevoked
+		}
+		m.mu.Lock()
+		refreshToken = m.refreshToken
+		m.mu.Unlock()
+	} else if reauth {
+		// Don't retry a rejected token; wait for a new authorization to
+		// show up in the state file or blob.
+		if !m.adoptStoredToken(ctx, refreshToken) {
+			return m.reauthError()
+		}
+		m.mu.Lock()
+		refreshToken = m.refreshToken
+		m.mu.Unlock()
+	}
 
-func (m *Manager) refresh(ctx context.Context) error {
-	ctx = context.WithValue(ctx, oauth2.HTTPClient, m.httpClient)
-	source := m.config.TokenSource(ctx, &oauth2.Token{RefreshToken: m.refreshToken})
-	token, err := source.Token()
+	token, err := m.exchange(ctx, refreshToken)
+	if isInvalidGrant(err) && m.adoptStoredToken(ctx, refreshToken) {
+		// Another gohome instance (or `gohome oauth`) rotated the token
+		// after we loaded it; retry with its copy.
+		m.mu.Lock()
+		refreshToken = m.refreshToken
+		m.mu.Unlock()
+		token, err = m.exchange(ctx, refreshToken)
+	}
 	if err != nil {
-		refreshFailure.WithLabelValues(m.decl.Provider).Inc()
-		tokenValid.WithLabelValues(m.decl.Provider).Set(0)
+		refreshFailure.WithLabelValues(m.decl.Provider, m.decl.AccountName()).Inc()
+		tokenValid.WithLabelValues(m.decl.Provider, m.decl.AccountName()).Set(0)
+		if isInvalidGrant(err) {
+			m.mu.Lock()
+			m.reauth = true
+			m.mu.Unlock()
+			return m.reauthError()
+		}
 		var retrieveErr *oauth2.RetrieveError
 		if errors.As(err, &retrieveErr) {
 			body := strings.TrimSpace(string(retrieveErr.Body))
@@ -194,36 +330,85 @@ func (m *Manager) refresh(ctx context.Context) error {
 	m.mu.Lock()
 	m.accessToken = token.AccessToken
 	m.expiresAt = token.Expiry
-	if token.RefreshToken != "" {
+	if token.RefreshToken != "" && token.RefreshToken != m.refreshToken {
 		m.refreshToken = token.RefreshToken
+		m.issuedAt = time.Now().UTC()
+		m.rotations++
 	}
-	m.mu.Unlock()
-
+	m.reauth = false
 	state := State{
-		SchemaVersion: SchemaVersion,
-		ClientID:      m.clientID,
-		ClientSecret:  m.clientSecret,
-		RefreshToken:  m.refreshToken,
-		Scope:         m.scope,
+		SchemaVersion:         SchemaVersion,
+		ClientID:              m.clientID,
+		ClientSecret:          m.clientSecret,
+		RefreshToken:          m.refreshToken,
+		Scope:                 m.scope,
+		RefreshTokenIssuedAt:  m.issuedAt,
+		RefreshTokenRotations: m.rotations,
 	}
+	m.mu.Unlock()
 
 	if err := WriteState(m.decl.StatePath, state); err != nil {
-		refreshFailure.WithLabelValues(m.decl.Provider).Inc()
+		refreshFailure.WithLabelValues(m.decl.Provider, m.decl.AccountName()).Inc()
 		return fmt.Errorf("persist state: %w", err)
 	}
 	if err := m.persistBlob(ctx, state); err != nil {
-		remotePersistOK.WithLabelValues(m.decl.Provider).Set(0)
-		refreshSuccess.WithLabelValues(m.decl.Provider).Inc()
-		tokenValid.WithLabelValues(m.decl.Provider).Set(1)
+		remotePersistOK.WithLabelValues(m.decl.Provider, m.decl.AccountName()).Set(0)
+		refreshSuccess.WithLabelValues(m.decl.Provider, m.decl.AccountName()).Inc()
+		tokenValid.WithLabelValues(m.decl.Provider, m.decl.AccountName()).Set(1)
 		return nil
 	}
 
-	remotePersistOK.WithLabelValues(m.decl.Provider).Set(1)
-	refreshSuccess.WithLabelValues(m.decl.Provider).Inc()
-	tokenValid.WithLabelValues(m.decl.Provider).Set(1)
+	remotePersistOK.WithLabelValues(m.decl.Provider, m.decl.AccountName()).Set(1)
+	refreshSuccess.WithLabelValues(m.decl.Provider, m.decl.AccountName()).Inc()
+	tokenValid.WithLabelValues(m.decl.Provider, m.decl.AccountName()).Set(1)
 	return nil
 }
 
+func (m *Manager) exchange(ctx context.Context, refreshToken string) (*oauth2.Token, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, m.httpClient)
+	return m.config.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+}
+
+func isInvalidGrant(err error) bool {
+	var retrieveErr *oauth2.RetrieveError
+	return errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant"
+}
+
+func (m *Manager) reauthError() error {
+	return fmt.Errorf("%w: run `%s`", ErrReauthRequired, m.decl.ReauthCommand())
+}
+
+// adoptStoredToken switches to a refresh token written to the state file or
+// blob since current was loaded, e.g. by another instance sharing the blob
+// or by a new `gohome oauth` authorization. It reports whether it did.
+func (m *Manager) adoptStoredToken(ctx context.Context, current string) bool {
+	candidates := []func() (State, error){
+		func() (State, error) { return LoadState(m.decl.StatePath) },
+		func() (State, error) { return m.loadFromBlob(ctx) },
+	}
+	for _, load := range candidates {
+		state, err := load()
+		if err != nil || state.RefreshToken == current {
+			continue
+		}
+		if state.Scope != "" && state.Scope != m.decl.Scope {
+			continue
+		}
+		m.mu.Lock()
+		m.refreshToken = state.RefreshToken
+		m.issuedAt = state.RefreshTokenIssuedAt
+		if m.issuedAt.IsZero() {
+			m.issuedAt = time.Now().UTC()
+		}
+		m.rotations = state.RefreshTokenRotations
+		m.reauth = false
+		m.revoked = false
+		m.mu.Unlock()
+		return true
+	}
+	return false
+}
+
 func (m *Manager) loadInitialState(bootstrap Bootstrap) (State, error) {
 	local, localErr := LoadState(m.decl.StatePath)
 	if localErr == nil {
@@ -231,7 +416,7 @@ func (m *Manager) loadInitialState(bootstrap Bootstrap) (State, error) {
 			return State{}, err
 		}
 		if local.Scope != "" && local.Scope != m.decl.Scope {
-			scopeMismatch.WithLabelValues(m.decl.Provider).Inc()
+			scopeMismatch.WithLabelValues(m.decl.Provider, m.decl.AccountName()).Inc()
 			return State{}, ErrScopeMismatch
 		}
 		if local.Scope == "" {
@@ -240,9 +425,9 @@ func (m *Manager) loadInitialState(bootstrap Bootstrap) (State, error) {
 		local.ClientID = bootstrap.ClientID
 		local.ClientSecret = bootstrap.ClientSecret
 		if err := m.persistBlob(context.Background(), local); err != nil {
-			remotePersistOK.WithLabelValues(m.decl.Provider).Set(0)
+			remotePersistOK.WithLabelValues(m.decl.Provider, m.decl.AccountName()).Set(0)
 		} else {
-			remotePersistOK.WithLabelValues(m.decl.Provider).Set(1)
+			remotePersistOK.WithLabelValues(m.decl.Provider, m.decl.AccountName()).Set(1)
 		}
 		return local, nil
 	}
@@ -255,16 +440,16 @@ func (m *Manager) loadInitialState(bootstrap Bootstrap) (State, error) {
 			blob.Scope = m.decl.Scope
 		}
 		if blob.Scope != "" && blob.Scope != m.decl.Scope {
-			scopeMismatch.WithLabelValues(m.decl.Provider).Inc()
+			scopeMismatch.WithLabelValues(m.decl.Provider, m.decl.AccountName()).Inc()
 			return State{}, ErrScopeMismatch
 		}
 		if err := WriteState(m.decl.StatePath, blob); err != nil {
 			return State{}, err
 		}
 		if err := m.persistBlob(context.Background(), blob); err != nil {
-			remotePersistOK.WithLabelValues(m.decl.Provider).Set(0)
+			remotePersistOK.WithLabelValues(m.decl.Provider, m.decl.AccountName()).Set(0)
 		} else {
-			remotePersistOK.WithLabelValues(m.decl.Provider).Set(1)
+			remotePersistOK.WithLabelValues(m.decl.Provider, m.decl.AccountName()).Set(1)
 		}
 		return blob, nil
 	}
@@ -291,7 +476,7 @@ func (m *Manager) loadInitialState(bootstrap Bootstrap) (State, error) {
 		state.Scope = m.decl.Scope
 	}
 	if state.Scope != "" && state.Scope != m.decl.Scope {
-		scopeMismatch.WithLabelValues(m.decl.Provider).Inc()
+		scopeMismatch.WithLabelValues(m.decl.Provider, m.decl.AccountName()).Inc()
 		return State{}, ErrScopeMismatch
 	}
 
@@ -299,19 +484,22 @@ func (m *Manager) loadInitialState(bootstrap Bootstrap) (State, error) {
 		return State{}, err
 	}
 	if err := m.persistBlob(context.Background(), state); err != nil {
-		remotePersistOK.WithLabelValues(m.decl.Provider).Set(0)
+		remotePersistOK.WithLabelValues(m.decl.Provider, m.decl.AccountName()).Set(0)
 	} else {
-		remotePersistOK.WithLabelValues(m.decl.Provider).Set(1)
+		remotePersistOK.WithLabelValues(m.decl.Provider, m.decl.AccountName()).Set(1)
 	}
 
 	return state, nil
 }
 
 func (m *Manager) loadFromBlob(ctx context.Context) (State, error) {
-	data, err := m.blobStore.Load(ctx, m.decl.Provider)
+	data, err := m.blobStore.Load(ctx, m.decl.Key())
 	if err != nil {
 		return State{}, err
 	}
+	if IsEncryptedBlob(data) {
+		return State{}, fmt.Errorf("blob %s is encrypted; set oauth.blob_key_file", m.decl.Key())
+	}
 	return DecodeState(data)
 }
 
@@ -320,7 +508,7 @@ func (m *Manager) persistBlob(ctx context.Context, state State) error {
 	if err != nil {
 		return err
 	}
-	return m.blobStore.Save(ctx, m.decl.Provider, data)
+	return m.blobStore.Save(ctx, m.decl.Key(), data)
 }
 
 func checkStateFile(path string) error {
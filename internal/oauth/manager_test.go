@@ -0,0 +1,236 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshp123/gohome/internal/oauth/oauthtest"
+)
+
+func testDeclaration(t *testing.T, server *oauthtest.Server) Declaration {
+	t.Helper()
+	return Declaration{
+		Provider:       "fake",
+		Flow:           FlowDevice,
+		TokenURL:       server.TokenURL(),
+		DeviceAuthURL:  server.DeviceAuthURL(),
+		DeviceTokenURL: server.TokenURL(),
+		RevokeURL:      server.RevokeURL(),
+		Scope:          "read write",
+		StatePath:      filepath.Join(t.TempDir(), "fake-token.json"),
+		PublicClient:   true,
+	}
+}
+
+func newTestManager(t *testing.T, decl Declaration, bootstrap Bootstrap, store BlobStore) *Manager {
+	t.Helper()
+	m, err := NewManagerFromBootstrap(decl, bootstrap, store)
+	if err != nil {
+		t.Fatalf("NewManagerFromBootstrap: %v", err)
+	}
+	t.Cleanup(func() { unregister(m) })
+	return m
+}
+
+func blobState(t *testing.T, store *MemoryStore, key string) State {
+	t.Helper()
+	data, err := store.Load(context.Background(), key)
+	if err != nil {
+		t.Fatalf("load blob %s: %v", key, err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatalf("decode blob: %v", err)
+	}
+	return state
+}
+
+func TestLoadInitialStatePrecedence(t *testing.T) {
+	server := oauthtest.New(t, "client", "")
+	ctx := context.Background()
+	bootstrap := Bootstrap{ClientID: "client", RefreshToken: "rt-bootstrap"}
+
+	t.Run("bootstrap seeds local and blob", func(t *testing.T) {
+		decl := testDeclaration(t, server)
+		store := NewMemoryStore()
+		m := newTestManager(t, decl, bootstrap, store)
+		if m.refreshToken != "rt-bootstrap" {
+			t.Fatalf("refresh token = %q, want bootstrap", m.refreshToken)
+		}
+		local, err := LoadState(decl.StatePath)
+		if err != nil || local.RefreshToken != "rt-bootstrap" || local.Scope != decl.Scope {
+			t.Fatalf("local state = %+v, %v", local, err)
+		}
+		if got := blobState(t, store, decl.Key()); got.RefreshToken != "rt-bootstrap" {
+			t.Fatalf("blob refresh token = %q", got.RefreshToken)
+		}
+	})
+
+	t.Run("blob beats bootstrap", func(t *testing.T) {
+		decl := testDeclaration(t, server)
+		store := NewMemoryStore()
+		seed, _ := json.Marshal(State{SchemaVersion: SchemaVersion, ClientID: "old-client", RefreshToken: "rt-blob", Scope: decl.Scope})
+		_ = store.Save(ctx, decl.Key(), seed)
+
+		m := newTestManager(t, decl, bootstrap, store)
+		if m.refreshToken != "rt-blob" {
+			t.Fatalf("refresh token = %q, want blob", m.refreshToken)
+		}
+		local, err := LoadState(decl.StatePath)
+		if err != nil || local.RefreshToken != "rt-blob" {
+			t.Fatalf("local state not rehydrated from blob: %+v, %v", local, err)
+		}
+		if local.ClientID != "client" {
+			t.Fatalf("client_id = %q; bootstrap credentials must win", local.ClientID)
+		}
+	})
+
+	t.Run("local beats blob", func(t *testing.T) {
+		decl := testDeclaration(t, server)
+		store := NewMemoryStore()
+		seed, _ := json.Marshal(State{SchemaVersion: SchemaVersion, ClientID: "client", RefreshToken: "rt-blob", Scope: decl.Scope})
+		_ = store.Save(ctx, decl.Key(), seed)
+		if err := WriteState(decl.StatePath, State{ClientID: "client", RefreshToken: "rt-local", Scope: decl.Scope}); err != nil {
+			t.Fatalf("WriteState: %v", err)
+		}
+
+		m := newTestManager(t, decl, bootstrap, store)
+		if m.refreshToken != "rt-local" {
+			t.Fatalf("refresh token = %q, want local", m.refreshToken)
+		}
+		if got := blobState(t, store, decl.Key()); got.RefreshToken != "rt-local" {
+			t.Fatalf("blob not updated from local state: %q", got.RefreshToken)
+		}
+	})
+
+	t.Run("no state anywhere", func(t *testing.T) {
+		decl := testDeclaration(t, server)
+		if _, err := NewManagerFromBootstrap(decl, Bootstrap{ClientID: "client"}, NewMemoryStore()); err == nil {
+			t.Fatal("expected an error without local, blob or bootstrap refresh token")
+		}
+	})
+}
+
+func TestLoadInitialStateScopeMismatch(t *testing.T) {
+	server := oauthtest.New(t, "client", "")
+	ctx := context.Background()
+
+	cases := map[string]func(decl Declaration, store *MemoryStore, bootstrap *Bootstrap){
+		"local": func(decl Declaration, _ *MemoryStore, _ *Bootstrap) {
+			_ = WriteState(decl.StatePath, State{ClientID: "client", RefreshToken: "rt", Scope: "read"})
+		},
+		"blob": func(decl Declaration, store *MemoryStore, _ *Bootstrap) {
+			seed, _ := json.Marshal(State{SchemaVersion: SchemaVersion, ClientID: "client", RefreshToken: "rt", Scope: "read"})
+			_ = store.Save(ctx, decl.Key(), seed)
+		},
+		"bootstrap": func(_ Declaration, _ *MemoryStore, bootstrap *Bootstrap) {
+			bootstrap.Scope = "read"
+		},
+	}
+	for name, seed := range cases {
+		t.Run(name, func(t *testing.T) {
+			decl := testDeclaration(t, server)
+			store := NewMemoryStore()
+			bootstrap := Bootstrap{ClientID: "client", RefreshToken: "rt"}
+			seed(decl, store, &bootstrap)
+			if _, err := NewManagerFromBootstrap(decl, bootstrap, store); !errors.Is(err, ErrScopeMismatch) {
+				t.Fatalf("err = %v, want ErrScopeMismatch", err)
+			}
+		})
+	}
+}
+
+func TestRefreshPersistsRotatedTokens(t *testing.T) {
+	server := oauthtest.New(t, "client", "")
+	server.SetRotation(true)
+	decl := testDeclaration(t, server)
+	store := NewMemoryStore()
+	first := server.IssueRefreshToken()
+	m := newTestManager(t, decl, Bootstrap{ClientID: "client", RefreshToken: first}, store)
+	ctx := context.Background()
+
+	for i := 1; i <= 2; i++ {
+		if err := m.Refresh(ctx); err != nil {
+			t.Fatalf("refresh %d: %v", i, err)
+		}
+	}
+	if server.Valid(first) {
+		t.Fatal("rotation should have invalidated the first refresh token")
+	}
+
+	local, err := LoadState(decl.StatePath)
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	if !server.Valid(local.RefreshToken) {
+		t.Fatalf("local state holds a stale refresh token")
+	}
+	if local.RefreshTokenRotations != 2 || local.RefreshTokenIssuedAt.IsZero() {
+		t.Fatalf("rotation bookkeeping = %d rotations, issued %v", local.RefreshTokenRotations, local.RefreshTokenIssuedAt)
+	}
+	if blob := blobState(t, store, decl.Key()); blob.RefreshToken != local.RefreshToken {
+		t.Fatalf("blob refresh token differs from local state")
+	}
+	if token, err := m.AccessToken(ctx); err != nil || token == "" {
+		t.Fatalf("AccessToken = %q, %v", token, err)
+	}
+
+	// A manager restarted from the persisted state keeps working.
+	unregister(m)
+	restarted := newTestManager(t, decl, Bootstrap{ClientID: "client", RefreshToken: first}, store)
+	if err := restarted.Refresh(ctx); err != nil {
+		t.Fatalf("refresh after restart: %v", err)
+	}
+	if restarted.rotations != 3 {
+		t.Fatalf("rotations after restart = %d, want 3", restarted.rotations)
+	}
+}
+
+func TestRefreshTransientErrorKeepsToken(t *testing.T) {
+	server := oauthtest.New(t, "client", "")
+	decl := testDeclaration(t, server)
+	m := newTestManager(t, decl, Bootstrap{ClientID: "client", RefreshToken: server.IssueRefreshToken()}, NewMemoryStore())
+	ctx := context.Background()
+
+	server.FailNext(1, http.StatusServiceUnavailable, "temporarily_unavailable")
+	err := m.Refresh(ctx)
+	if err == nil || errors.Is(err, ErrReauthRequired) {
+		t.Fatalf("Refresh = %v, want a transient error", err)
+	}
+	if err := m.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh after outage: %v", err)
+	}
+}
+
+func TestStartTwiceKeepsOneLoop(t *testing.T) {
+	server := oauthtest.New(t, "client", "")
+	decl := testDeclaration(t, server)
+	m := newTestManager(t, decl, Bootstrap{ClientID: "client", RefreshToken: server.IssueRefreshToken()}, NewMemoryStore())
+	ctx := context.Background()
+
+	m.StartWithInterval(ctx, time.Hour)
+	m.mu.Lock()
+	first := m.loopDone
+	m.mu.Unlock()
+	m.StartWithInterval(ctx, time.Hour)
+	m.mu.Lock()
+	second := m.loopDone
+	m.mu.Unlock()
+	if first != second {
+		t.Fatal("second Start replaced the running refresh loop")
+	}
+
+	if err := m.Stop(ctx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	select {
+	case <-first:
+	default:
+		t.Fatal("refresh loop still running after Stop")
+	}
+}
@@ -1,6 +1,10 @@
 package oauth
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 var (
 	refreshSuccess = prometheus.NewCounterVec(
@@ -8,35 +12,35 @@ var (
 			Name: "gohome_oauth_refresh_success_total",
 			Help: "Successful OAuth refreshes",
 		},
-		[]string{"provider"},
+		[]string{"provider", "account"},
 	)
 	refreshFailure = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "gohome_oauth_refresh_failure_total",
 			Help: "Failed OAuth refreshes",
 		},
-		[]string{"provider"},
+		[]string{"provider", "account"},
 	)
 	tokenValid = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "gohome_oauth_token_valid",
 			Help: "OAuth access token validity (1=valid, 0=invalid)",
 		},
-		[]string{"provider"},
+		[]string{"provider", "account"},
 	)
 	remotePersistOK = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "gohome_oauth_remote_persist_ok",
 			Help: "Remote blob persistence health (1=ok, 0=error)",
 		},
-		[]string{"provider"},
+		[]string{"provider", "account"},
 	)
 	scopeMismatch = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "gohome_oauth_scope_mismatch_total",
 			Help: "Scope mismatches between declaration and state",
 		},
-		[]string{"provider"},
+		[]string{"provider", "account"},
 	)
 )
 
@@ -48,5 +52,35 @@ func MetricsCollectors() []prometheus.Collector {
 		tokenValid,
 		remotePersistOK,
 		scopeMismatch,
+		refreshTokenAge{},
+	}
+}
+
+var refreshTokenAgeDesc = prometheus.NewDesc(
+	"gohome_oauth_refresh_token_age_seconds",
+	"Time since the provider issued the current refresh token",
+	[]string{"provider", "account"},
+	nil,
+)
+
+// refreshTokenAge reports each live manager's refresh-token age at scrape
+// time, so it keeps growing while a provider stops rotating tokens.
+type refreshTokenAge struct{}
+
+func (refreshTokenAge) Describe(ch chan<- *prometheus.Desc) {
+	ch <- refreshTokenAgeDesc
+}
+
+func (refreshTokenAge) Collect(ch chan<- prometheus.Metric) {
+	now := time.Now()
+	for _, m := range Managers() {
+		m.mu.Lock()
+		issuedAt, revoked := m.issuedAt, m.revoked
+		m.mu.Unlock()
+		if issuedAt.IsZero() || revoked {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(refreshTokenAgeDesc, prometheus.GaugeValue,
+			now.Sub(issuedAt).Seconds(), m.decl.Provider, m.decl.AccountName())
 	}
 }
@@ -0,0 +1,329 @@
+// Package oauthtest provides a fake OAuth2 provider for tests: authorize,
+// token, device-authorization, device-token and revocation endpoints with
+// configurable refresh-token rotation, token lifetime and error injection.
+package oauthtest
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+const deviceGrant = "urn:ietf:params:oauth:grant-type:device_code"
+
+// Server is a fake OAuth2 provider. The authorize endpoint approves every
+// request and redirects straight back with a code; device authorizations are
+// approved after the scripted device responses have been returned.
+type Server struct {
+	*httptest.Server
+
+	// ClientID and ClientSecret are the credentials the token endpoint
+	// accepts. An empty ClientSecret makes the client public.
+	ClientID     string
+	ClientSecret string
+
+	mu            sync.Mutex
+	rotate        bool
+	expiresIn     time.Duration
+	scope         string
+	refreshTokens map[string]bool
+	codes         map[string]authCode
+	devices       map[string][]string
+	deviceScript  []string
+	failures      []failure
+	requests      []url.Values
+}
+
+type authCode struct {
+	redirectURI string
+	challenge   string
+}
+
+type failure struct {
+	status int
+	code   string
+}
+
+// New starts a fake provider that is closed when the test ends.
+func New(t testing.TB, clientID, clientSecret string) *Server {
+	s := &Server{
+		ClientID:      clientID,
+		ClientSecret:  clientSecret,
+		expiresIn:     time.Hour,
+		refreshTokens: make(map[string]bool),
+		codes:         make(map[string]authCode),
+		devices:       make(map[string][]string),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/device", s.deviceAuthorize)
+	mux.HandleFunc("/revoke", s.revoke)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *Server) AuthorizeURL() string  { return s.URL + "/authorize" }
+func (s *Server) TokenURL() string      { return s.URL + "/token" }
+func (s *Server) DeviceAuthURL() string { return s.URL + "/device" }
+func (s *Server) RevokeURL() string     { return s.URL + "/revoke" }
+
+// SetRotation makes every refresh return a new refresh token and invalidate
+// the one it was given.
+func (s *Server) SetRotation(rotate bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rotate = rotate
+}
+
+// SetExpiresIn sets the access-token lifetime reported in token responses.
+func (s *Server) SetExpiresIn(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expiresIn = d
+}
+
+// SetScope sets the scope reported in token responses; empty omits it.
+func (s *Server) SetScope(scope string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scope = scope
+}
+
+// FailNext makes the next n token requests fail with status and an RFC 6749
+// error code, e.g. FailNext(1, http.StatusBadRequest, "invalid_grant").
+// oauth2 clients that auto-detect their auth style retry a failed request
+// once, so those need n=2 to see one failure.
+func (s *Server) FailNext(n, status int, code string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < n; i++ {
+		s.failures = append(s.failures, failure{status: status, code: code})
+	}
+}
+
+// ScriptDevice sets the errors (authorization_pending, slow_down, ...) that
+// device-token polls for new device authorizations return before the device
+// is approved.
+func (s *Server) ScriptDevice(errors ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deviceScript = append([]string(nil), errors...)
+}
+
+// IssueRefreshToken registers a new valid refresh token, as if the user had
+// authorized earlier.
+func (s *Server) IssueRefreshToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.issueLocked()
+}
+
+// Valid reports whether the provider would accept refreshToken.
+func (s *Server) Valid(refreshToken string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.refreshTokens[refreshToken]
+}
+
+// Requests returns the forms of all token-endpoint requests so far.
+func (s *Server) Requests() []url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]url.Values(nil), s.requests...)
+}
+
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != s.ClientID {
+		http.Error(w, "unknown client", http.StatusBadRequest)
+		return
+	}
+	challenge := q.Get("code_challenge")
+	if challenge != "" && q.Get("code_challenge_method") != "S256" {
+		http.Error(w, "only S256 code challenges are supported", http.StatusBadRequest)
+		return
+	}
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirect.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	code := randomToken("code")
+	s.codes[code] = authCode{redirectURI: q.Get("redirect_uri"), challenge: challenge}
+	s.mu.Unlock()
+
+	values := redirect.Query()
+	values.Set("code", code)
+	values.Set("state", q.Get("state"))
+	redirect.RawQuery = values.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (s *Server) deviceAuthorize(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+	if r.PostForm.Get("client_id") != s.ClientID {
+		writeError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+	s.mu.Lock()
+	deviceCode := randomToken("device")
+	s.devices[deviceCode] = append([]string(nil), s.deviceScript...)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"device_code":               deviceCode,
+		"user_code":                 "ABCD-EFGH",
+		"verification_uri":          s.URL + "/activate",
+		"verification_uri_complete": s.URL + "/activate?user_code=ABCD-EFGH",
+		"expires_in":                300,
+		"interval":                  1,
+	})
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.PostForm)
+
+	if len(s.failures) > 0 {
+		f := s.failures[0]
+		s.failures = s.failures[1:]
+		writeError(w, f.status, f.code)
+		return
+	}
+	if !s.authenticated(r) {
+		writeError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+
+	form := r.PostForm
+	switch form.Get("grant_type") {
+	case "refresh_token":
+		old := form.Get("refresh_token")
+		if !s.refreshTokens[old] {
+			writeError(w, http.StatusBadRequest, "invalid_grant")
+			return
+		}
+		refreshToken := old
+		if s.rotate {
+			delete(s.refreshTokens, old)
+			refreshToken = s.issueLocked()
+		}
+		s.writeTokenLocked(w, refreshToken)
+	case "authorization_code":
+		code, ok := s.codes[form.Get("code")]
+		delete(s.codes, form.Get("code"))
+		switch {
+		case !ok || code.redirectURI != form.Get("redirect_uri"):
+			writeError(w, http.StatusBadRequest, "invalid_grant")
+		case code.challenge != "" && code.challenge != s256(form.Get("code_verifier")):
+			writeError(w, http.StatusBadRequest, "invalid_grant")
+		case code.challenge == "" && s.ClientSecret == "":
+			// Public clients must use PKCE.
+			writeError(w, http.StatusBadRequest, "invalid_request")
+		default:
+			s.writeTokenLocked(w, s.issueLocked())
+		}
+	case deviceGrant:
+		script, ok := s.devices[form.Get("device_code")]
+		switch {
+		case !ok:
+			writeError(w, http.StatusBadRequest, "expired_token")
+		case len(script) > 0:
+			s.devices[form.Get("device_code")] = script[1:]
+			writeError(w, http.StatusBadRequest, script[0])
+		default:
+			delete(s.devices, form.Get("device_code"))
+			s.writeTokenLocked(w, s.issueLocked())
+		}
+	default:
+		writeError(w, http.StatusBadRequest, "unsupported_grant_type")
+	}
+}
+
+// revoke implements RFC 7009: the token stops working and the response is
+// 200 whether or not it was known.
+func (s *Server) revoke(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.authenticated(r) {
+		writeError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+	delete(s.refreshTokens, r.PostForm.Get("token"))
+	w.WriteHeader(http.StatusOK)
+}
+
+// authenticated checks client credentials sent with HTTP basic auth or in
+// the form. Public clients must not send a secret.
+func (s *Server) authenticated(r *http.Request) bool {
+	id, secret, basic := r.BasicAuth()
+	if !basic {
+		id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	return id == s.ClientID && secret == s.ClientSecret
+}
+
+func (s *Server) issueLocked() string {
+	token := randomToken("rt")
+	s.refreshTokens[token] = true
+	return token
+}
+
+func (s *Server) writeTokenLocked(w http.ResponseWriter, refreshToken string) {
+	body := map[string]any{
+		"access_token":  randomToken("at"),
+		"token_type":    "Bearer",
+		"expires_in":    int(s.expiresIn.Seconds()),
+		"refresh_token": refreshToken,
+	}
+	if s.scope != "" {
+		body["scope"] = s.scope
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+func writeError(w http.ResponseWriter, status int, code string) {
+	writeJSON(w, status, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func s256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomToken(prefix string) string {
+	buf := make([]byte, 12)
+	_, _ = rand.Read(buf)
+	return prefix + "-" + hex.EncodeToString(buf)
+}
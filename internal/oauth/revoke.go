@@ -0,0 +1,103 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// BlobDeleter is implemented by stores that can remove a blob.
+type BlobDeleter interface {
+	Delete(ctx context.Context, key string) error
+}
+
+// Revoke revokes the refresh token with the provider when the declaration
+// has a RevokeURL, then wipes the local state file and the blob copy. The
+// manager stops refreshing until the provider is authorized again. It reports
+// whether the provider was asked to revoke.
+func (m *Manager) Revoke(ctx context.Context) (bool, error) {
+	// Hold the refresh slot so a concurrent refresh cannot write the token
+	// back after it has been wiped.
+	for !m.beginRefresh() {
+		m.mu.Lock()
+		done := m.refreshDone
+		m.mu.Unlock()
+		if done == nil {
+			continue
+		}
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+	defer m.endRefresh()
+
+	m.mu.Lock()
+	refreshToken := m.refreshToken
+	m.mu.Unlock()
+
+	remote := false
+	if m.decl.RevokeURL != "" && refreshToken != "" {
+		if err := m.revokeRemote(ctx, refreshToken); err != nil {
+			return false, err
+		}
+		remote = true
+	}
+
+	m.mu.Lock()
+	m.accessToken = ""
+	m.expiresAt = time.Time{}
+	m.refreshToken = ""
+	m.revoked = true
+	m.lastErr = ErrRevoked
+	m.mu.Unlock()
+	tokenValid.WithLabelValues(m.decl.Provider, m.decl.AccountName()).Set(0)
+
+	var errs []error
+	if err := os.Remove(m.decl.StatePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		errs = append(errs, fmt.Errorf("remove state file: %w", err))
+	}
+	if deleter, ok := m.blobStore.(BlobDeleter); ok {
+		if err := deleter.Delete(ctx, m.decl.Key()); err != nil && !errors.Is(err, ErrBlobNotFound) {
+			errs = append(errs, fmt.Errorf("delete blob: %w", err))
+		}
+	} else {
+		errs = append(errs, fmt.Errorf("delete blob: %T cannot delete blobs", m.blobStore))
+	}
+	return remote, errors.Join(errs...)
+}
+
+// revokeRemote posts an RFC 7009 revocation request for the refresh token.
+func (m *Manager) revokeRemote(ctx context.Context, refreshToken string) error {
+	form := url.Values{
+		"token":           {refreshToken},
+		"token_type_hint": {"refresh_token"},
+		"client_id":       {m.clientID},
+	}
+	if m.clientSecret != "" {
+		form.Set("client_secret", m.clientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.decl.RevokeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("revoke: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("revoke failed %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
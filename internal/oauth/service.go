@@ -0,0 +1,95 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	oauthv1 "github.com/joshp123/gohome/proto/gen/oauth/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type service struct {
+	oauthv1.UnimplementedOAuthServiceServer
+}
+
+// RegisterOAuthService registers the OAuth inspection and control RPCs.
+func RegisterOAuthService(server *grpc.Server) {
+	oauthv1.RegisterOAuthServiceServer(server, &service{})
+}
+
+func (s *service) ListProviders(ctx context.Context, req *oauthv1.ListProvidersRequest) (*oauthv1.ListProvidersResponse, error) {
+	managers := Managers()
+	resp := &oauthv1.ListProvidersResponse{Providers: make([]*oauthv1.ProviderStatus, 0, len(managers))}
+	for _, m := range managers {
+		resp.Providers = append(resp.Providers, statusProto(m.Status(ctx)))
+	}
+	return resp, nil
+}
+
+func (s *service) Refresh(ctx context.Context, req *oauthv1.RefreshRequest) (*oauthv1.RefreshResponse, error) {
+	m, err := lookup(req.GetProvider(), req.GetAccount())
+	if err != nil {
+		return nil, err
+	}
+	if err := m.Refresh(ctx); err != nil {
+		if errors.Is(err, ErrRevoked) || errors.Is(err, ErrReauthRequired) {
+			return nil, status.Errorf(codes.FailedPrecondition, "refresh %s: %v", m.decl.Key(), err)
+		}
+		return nil, status.Errorf(codes.Unavailable, "refresh %s: %v", m.decl.Key(), err)
+	}
+	return &oauthv1.RefreshResponse{Provider: statusProto(m.Status(ctx))}, nil
+}
+
+func (s *service) Revoke(ctx context.Context, req *oauthv1.RevokeRequest) (*oauthv1.RevokeResponse, error) {
+	m, err := lookup(req.GetProvider(), req.GetAccount())
+	if err != nil {
+		return nil, err
+	}
+	remote, err := m.Revoke(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "revoke %s: %v", m.decl.Key(), err)
+	}
+	return &oauthv1.RevokeResponse{RevokedRemotely: remote, Provider: statusProto(m.Status(ctx))}, nil
+}
+
+func lookup(provider, account string) (*Manager, error) {
+	if provider == "" {
+		return nil, status.Error(codes.InvalidArgument, "provider is required")
+	}
+	m, ok := LookupManager(provider, account)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no oauth manager for %s", Declaration{Provider: provider}.ForAccount(account).Key())
+	}
+	return m, nil
+}
+
+func statusProto(st Status) *oauthv1.ProviderStatus {
+	return &oauthv1.ProviderStatus{
+		Provider:           st.Provider,
+		Account:            st.Account,
+		Flow:               st.Flow,
+		Scope:              st.Scope,
+		AccessTokenExpiry:  optionalTimestamp(st.AccessTokenExpiry),
+		LastRefresh:        optionalTimestamp(st.LastRefresh),
+		LastRefreshOutcome: st.Outcome(),
+		LastError:          st.LastError,
+		StateSync:          st.StateSync,
+		StateSyncDetail:    st.StateSyncDetail,
+		Revoked:            st.Revoked,
+
+		ReauthRequired:        st.ReauthRequired,
+		RefreshTokenIssuedAt:  optionalTimestamp(st.RefreshTokenIssuedAt),
+		RefreshTokenRotations: int32(st.RefreshTokenRotations),
+	}
+}
+
+func optionalTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
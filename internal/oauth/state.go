@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 const SchemaVersion = 1
@@ -19,6 +20,12 @@ type State struct {
 	ClientSecret  string `json:"client_secret"`
 	RefreshToken  string `json:"refresh_token"`
 	Scope         string `json:"scope"`
+	// RefreshTokenIssuedAt is when the provider issued RefreshToken; zero in
+	// states written before it was tracked.
+	RefreshTokenIssuedAt time.Time `json:"refresh_token_issued_at,omitzero"`
+	// RefreshTokenRotations counts refreshes that returned a new refresh
+	// token since the last authorization.
+	RefreshTokenRotations int `json:"refresh_token_rotations,omitempty"`
 }
 
 // Bootstrap holds immutable OAuth credentials seeded from Nix.
@@ -0,0 +1,175 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// registry tracks the live manager per provider and account so the OAuth
+// service can inspect and control them.
+var registry = struct {
+	mu       sync.Mutex
+	managers map[string]*Manager
+}{managers: make(map[string]*Manager)}
+
+// register makes m the live manager for its key; a manager built by a config
+// reload replaces the previous one.
+func register(m *Manager) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.managers[m.decl.Key()] = m
+}
+
+func unregister(m *Manager) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if registry.managers[m.decl.Key()] == m {
+		delete(registry.managers, m.decl.Key())
+	}
+}
+
+// Managers returns the live managers sorted by provider, then account.
+func Managers() []*Manager {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	out := make([]*Manager, 0, len(registry.managers))
+	for _, m := range registry.managers {
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].decl.Key() < out[j].decl.Key() })
+	return out
+}
+
+// LookupManager returns the live manager for a provider's account ("" or
+// "default" for the default account).
+func LookupManager(provider, account string) (*Manager, bool) {
+	key := Declaration{Provider: provider}.ForAccount(account).Key()
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	m, ok := registry.managers[key]
+	return m, ok
+}
+
+// ReauthMessage explains which of provider's accounts need a new
+// authorization, or returns "" if none do. Plugins surface it as ERROR
+// health.
+func ReauthMessage(provider string) string {
+	var cmds []string
+	for _, m := range Managers() {
+		if m.decl.Provider != provider {
+			continue
+		}
+		m.mu.Lock()
+		reauth := m.reauth
+		m.mu.Unlock()
+		if reauth {
+			cmds = append(cmds, m.decl.ReauthCommand())
+		}
+	}
+	if len(cmds) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s refresh token rejected; run `%s`", provider, strings.Join(cmds, "` and `"))
+}
+
+// StateSync values compare the local state file with the blob copy.
+const (
+	StateInSync       = "in_sync"
+	StateDiffers      = "differs"
+	StateLocalMissing = "local_missing"
+	StateBlobMissing  = "blob_missing"
+	StateSyncError    = "error"
+)
+
+// Status is a snapshot of a manager for introspection.
+type Status struct {
+	Provider          string
+	Account           string
+	Flow              string
+	Scope             string
+	AccessTokenExpiry time.Time // zero without a current access token
+	LastRefresh       time.Time // zero if no refresh has been attempted
+	LastError         string
+	Revoked           bool
+	StateSync         string
+	StateSyncDetail   string
+	// ReauthRequired is set once the provider rejected the refresh token.
+	ReauthRequired        bool
+	RefreshTokenIssuedAt  time.Time
+	RefreshTokenRotations int
+}
+
+// Outcome is "ok" or "error" for the last refresh, "" if none has run.
+func (s Status) Outcome() string {
+	switch {
+	case s.LastRefresh.IsZero():
+		return ""
+	case s.LastError != "":
+		return "error"
+	default:
+		return "ok"
+	}
+}
+
+// Decl returns the manager's declaration.
+func (m *Manager) Decl() Declaration {
+	return m.decl
+}
+
+// Status reports the manager's token state. It reads the local state file
+// and the blob copy to check that they agree.
+func (m *Manager) Status(ctx context.Context) Status {
+	m.mu.Lock()
+	st := Status{
+		Provider:    m.decl.Provider,
+		Account:     m.decl.AccountName(),
+		Flow:        m.decl.Flow,
+		Scope:       m.scope,
+		LastRefresh: m.lastRefresh,
+		Revoked:     m.revoked,
+
+		ReauthRequired:        m.reauth,
+		RefreshTokenIssuedAt:  m.issuedAt,
+		RefreshTokenRotations: m.rotations,
+	}
+	if m.accessToken != "" {
+		st.AccessTokenExpiry = m.expiresAt
+	}
+	if m.lastErr != nil {
+		st.LastError = m.lastErr.Error()
+	}
+	m.mu.Unlock()
+
+	st.StateSync, st.StateSyncDetail = m.stateSync(ctx)
+	return st
+}
+
+func (m *Manager) stateSync(ctx context.Context) (string, string) {
+	local, err := LoadState(m.decl.StatePath)
+	if errors.Is(err, ErrStateNotFound) {
+		return StateLocalMissing, ""
+	}
+	if err != nil {
+		return StateSyncError, "local: " + err.Error()
+	}
+	blob, err := m.loadFromBlob(ctx)
+	if errors.Is(err, ErrBlobNotFound) {
+		return StateBlobMissing, ""
+	}
+	if err != nil {
+		return StateSyncError, "blob: " + err.Error()
+	}
+	switch {
+	case local.RefreshToken != blob.RefreshToken:
+		return StateDiffers, "refresh tokens differ"
+	case local.Scope != blob.Scope:
+		return StateDiffers, "scopes differ"
+	default:
+		return StateInSync, ""
+	}
+}
@@ -0,0 +1,148 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joshp123/gohome/internal/oauth/oauthtest"
+)
+
+func TestManagerStatusRefreshRevoke(t *testing.T) {
+	server := oauthtest.New(t, "client", "")
+	server.SetRotation(true)
+	decl := testDeclaration(t, server)
+	store := NewMemoryStore()
+	m := newTestManager(t, decl, Bootstrap{ClientID: "client", RefreshToken: server.IssueRefreshToken()}, store)
+	ctx := context.Background()
+
+	if got, ok := LookupManager("fake", ""); !ok || got != m {
+		t.Fatalf("LookupManager did not return the registered manager")
+	}
+	st := m.Status(ctx)
+	if st.Outcome() != "" || !st.AccessTokenExpiry.IsZero() {
+		t.Fatalf("status before refresh = %+v", st)
+	}
+
+	if err := m.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	st = m.Status(ctx)
+	if st.Outcome() != "ok" || st.AccessTokenExpiry.IsZero() || st.StateSync != StateInSync {
+		t.Fatalf("status after refresh = %+v", st)
+	}
+
+	if err := WriteState(decl.StatePath, State{ClientID: "client", RefreshToken: "other", Scope: decl.Scope}); err != nil {
+		t.Fatalf("write state: %v", err)
+	}
+	if st := m.Status(ctx); st.StateSync != StateDiffers {
+		t.Fatalf("StateSync = %s, want %s", st.StateSync, StateDiffers)
+	}
+
+	current := m.refreshToken
+	remote, err := m.Revoke(ctx)
+	if err != nil || !remote {
+		t.Fatalf("Revoke = %v, %v; want remote revocation", remote, err)
+	}
+	if server.Valid(current) {
+		t.Fatal("provider still accepts the revoked refresh token")
+	}
+	if _, err := os.Stat(decl.StatePath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("state file not removed: %v", err)
+	}
+	if _, err := store.Load(ctx, decl.Key()); !errors.Is(err, ErrBlobNotFound) {
+		t.Fatalf("blob not deleted: %v", err)
+	}
+	if err := m.Refresh(ctx); !errors.Is(err, ErrRevoked) {
+		t.Fatalf("Refresh after revoke = %v, want ErrRevoked", err)
+	}
+	if st := m.Status(ctx); !st.Revoked || st.StateSync != StateLocalMissing {
+		t.Fatalf("status after revoke = %+v", st)
+	}
+
+	// The operator authorizes again with `gohome oauth device`, which
+	// writes a new state file.
+	fresh := server.IssueRefreshToken()
+	if err := WriteState(decl.StatePath, State{ClientID: "client", RefreshToken: fresh, Scope: decl.Scope}); err != nil {
+		t.Fatalf("write state: %v", err)
+	}
+	if err := m.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh after reauthorization: %v", err)
+	}
+	if st := m.Status(ctx); st.Revoked || st.Outcome() != "ok" {
+		t.Fatalf("status after reauthorization = %+v", st)
+	}
+}
+
+func TestManagerInvalidGrantRequiresReauth(t *testing.T) {
+	server := oauthtest.New(t, "client", "")
+	server.SetRotation(true)
+	decl := testDeclaration(t, server)
+	store := NewMemoryStore()
+	m := newTestManager(t, decl, Bootstrap{ClientID: "client", RefreshToken: "rt-expired"}, store)
+	ctx := context.Background()
+
+	if err := m.Refresh(ctx); !errors.Is(err, ErrReauthRequired) {
+		t.Fatalf("Refresh = %v, want ErrReauthRequired", err)
+	}
+	want := "run `gohome oauth device --provider fake`"
+	if msg := ReauthMessage("fake"); !strings.Contains(msg, want) {
+		t.Fatalf("ReauthMessage = %q, want it to contain %q", msg, want)
+	}
+	before := len(server.Requests())
+	if err := m.Refresh(ctx); !errors.Is(err, ErrReauthRequired) || len(server.Requests()) != before {
+		t.Fatalf("second Refresh = %v with %d new requests; a rejected token must not be retried", err, len(server.Requests())-before)
+	}
+
+	// A new authorization lands in the blob (another host ran gohome oauth).
+	fresh := server.IssueRefreshToken()
+	if err := m.persistBlob(ctx, State{SchemaVersion: SchemaVersion, ClientID: "client", RefreshToken: fresh, Scope: decl.Scope}); err != nil {
+		t.Fatalf("persistBlob: %v", err)
+	}
+	if err := m.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh after reauthorization: %v", err)
+	}
+	if msg := ReauthMessage("fake"); msg != "" {
+		t.Fatalf("ReauthMessage after recovery = %q", msg)
+	}
+	state, err := LoadState(decl.StatePath)
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	if !server.Valid(state.RefreshToken) || state.RefreshTokenRotations != 1 {
+		t.Fatalf("state after rotation = %+v", state)
+	}
+}
+
+func TestManagerAdoptsTokenRotatedElsewhere(t *testing.T) {
+	server := oauthtest.New(t, "client", "")
+	server.SetRotation(true)
+	decl := testDeclaration(t, server)
+	store := NewMemoryStore()
+	m := newTestManager(t, decl, Bootstrap{ClientID: "client", RefreshToken: server.IssueRefreshToken()}, store)
+	ctx := context.Background()
+
+	// Another host sharing the blob refreshes first, invalidating the token
+	// this manager holds.
+	otherDecl := decl
+	otherDecl.StatePath = filepath.Join(t.TempDir(), "other-token.json")
+	other, err := NewManagerFromBootstrap(otherDecl, Bootstrap{ClientID: "client"}, store)
+	if err != nil {
+		t.Fatalf("second manager: %v", err)
+	}
+	unregister(other)
+	register(m)
+	if err := other.Refresh(ctx); err != nil {
+		t.Fatalf("other Refresh: %v", err)
+	}
+
+	if err := m.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh after rotation elsewhere = %v; want the blob token adopted", err)
+	}
+	if msg := ReauthMessage("fake"); msg != "" {
+		t.Fatalf("adopted token should not require reauthorization: %q", msg)
+	}
+}
@@ -0,0 +1,180 @@
+package oauthflow
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/joshp123/gohome/internal/oauth"
+	"golang.org/x/oauth2"
+)
+
+// AuthCodeSession is one authorization-code attempt: the state that guards
+// the callback and, for PKCE providers, the S256 code verifier.
+type AuthCodeSession struct {
+	Config   *oauth2.Config
+	State    string
+	Verifier string // empty unless the declaration enables PKCE
+}
+
+// NewAuthCodeSession prepares an auth-code flow for decl with the given
+// bootstrap credentials and redirect URL.
+func NewAuthCodeSession(decl oauth.Declaration, bootstrap oauth.Bootstrap, redirectURL string) (*AuthCodeSession, error) {
+	if err := decl.ValidateBootstrap(bootstrap); err != nil {
+		return nil, fmt.Errorf("bootstrap: %w", err)
+	}
+	if decl.PublicClient && !decl.PKCE {
+		return nil, fmt.Errorf("provider %q is a public client and must use PKCE", decl.Provider)
+	}
+	state, err := randomState(16)
+	if err != nil {
+		return nil, err
+	}
+	session := &AuthCodeSession{
+		Config: &oauth2.Config{
+			ClientID:     bootstrap.ClientID,
+			ClientSecret: bootstrap.ClientSecret,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:   decl.AuthorizeURL,
+				TokenURL:  decl.TokenURL,
+				AuthStyle: decl.AuthStyle(),
+			},
+			RedirectURL: redirectURL,
+			Scopes:      strings.Fields(decl.Scope),
+		},
+		State: state,
+	}
+	if decl.PKCE {
+		session.Verifier = oauth2.GenerateVerifier()
+	}
+	return session, nil
+}
+
+// AuthURL is the URL the user opens to authorize.
+func (s *AuthCodeSession) AuthURL() string {
+	opts := []oauth2.AuthCodeOption{oauth2.AccessTypeOffline}
+	if s.Verifier != "" {
+		opts = append(opts, oauth2.S256ChallengeOption(s.Verifier))
+	}
+	return s.Config.AuthCodeURL(s.State, opts...)
+}
+
+// Exchange trades the authorization code for a token, sending the code
+// verifier when PKCE is in use.
+func (s *AuthCodeSession) Exchange(ctx context.Context, code string) (*oauth2.Token, error) {
+	var opts []oauth2.AuthCodeOption
+	if s.Verifier != "" {
+		opts = append(opts, oauth2.VerifierOption(s.Verifier))
+	}
+	token, err := s.Config.Exchange(ctx, code, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if token.RefreshToken == "" {
+		return nil, fmt.Errorf("no refresh_token returned; check scope and redirect URL")
+	}
+	return token, nil
+}
+
+// CanListen reports whether the redirect URL is a plain-http loopback
+// address the CLI can receive the callback on.
+func CanListen(redirect *url.URL) bool {
+	return redirect.Scheme == "http" && redirect.Host != "" && isLoopback(redirect.Hostname())
+}
+
+// ListenForAuthCode serves the loopback redirect URL until the provider
+// calls back with a code for this session's state, or ctx is done.
+func (s *AuthCodeSession) ListenForAuthCode(ctx context.Context, redirect *url.URL) (string, error) {
+	listener, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return "", err
+	}
+	return s.serveAuthCode(ctx, listener, redirect.Path)
+}
+
+func (s *AuthCodeSession) serveAuthCode(ctx context.Context, listener net.Listener, path string) (string, error) {
+	codeCh := make(chan string, 1)
+	errCh := make(chan error, 1)
+
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if path != "" && r.URL.Path != path {
+				http.NotFound(w, r)
+				return
+			}
+			query := r.URL.Query()
+			if errStr := query.Get("error"); errStr != "" {
+				sendErr(errCh, fmt.Errorf("authorization error: %s", errStr))
+				_, _ = w.Write([]byte("Authorization failed. You can close this window."))
+				return
+			}
+			// A callback without state is rejected too: it cannot be tied to
+			// this session.
+			if query.Get("state") != s.State {
+				sendErr(errCh, fmt.Errorf("state mismatch"))
+				_, _ = w.Write([]byte("State mismatch. You can close this window."))
+				return
+			}
+			code := query.Get("code")
+			if code == "" {
+				sendErr(errCh, fmt.Errorf("missing code in callback"))
+				_, _ = w.Write([]byte("Missing authorization code. You can close this window."))
+				return
+			}
+			select {
+			case codeCh <- code:
+			default:
+			}
+			_, _ = w.Write([]byte("Authorization received. You can close this window."))
+		}),
+	}
+
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			sendErr(errCh, err)
+		}
+	}()
+	defer func() {
+		_ = srv.Close()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", fmt.Errorf("authorization timed out")
+	case err := <-errCh:
+		return "", err
+	case code := <-codeCh:
+		return code, nil
+	}
+}
+
+func sendErr(ch chan error, err error) {
+	select {
+	case ch <- err:
+	default:
+	}
+}
+
+func randomState(length int) (string, error) {
+	buf := make([]byte, length)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsLoopback()
+	}
+	return false
+}
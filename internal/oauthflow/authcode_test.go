@@ -0,0 +1,152 @@
+package oauthflow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshp123/gohome/internal/oauth"
+	"github.com/joshp123/gohome/internal/oauth/oauthtest"
+)
+
+func fakeDeclaration(server *oauthtest.Server, pkce, public bool) oauth.Declaration {
+	return oauth.Declaration{
+		Provider:     "fake",
+		Flow:         oauth.FlowAuthCode,
+		AuthorizeURL: server.AuthorizeURL(),
+		TokenURL:     server.TokenURL(),
+		Scope:        "offline_access",
+		PKCE:         pkce,
+		PublicClient: public,
+	}
+}
+
+func s256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// authorize starts a session on a loopback port, follows the authorize
+// redirect back to it and returns the code the callback received.
+func authorize(t *testing.T, decl oauth.Declaration, bootstrap oauth.Bootstrap) (*AuthCodeSession, string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	session, err := NewAuthCodeSession(decl, bootstrap, "http://"+listener.Addr().String()+"/callback")
+	if err != nil {
+		listener.Close()
+		t.Fatalf("NewAuthCodeSession: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	type result struct {
+		code string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		code, err := session.serveAuthCode(ctx, listener, "/callback")
+		done <- result{code, err}
+	}()
+
+	resp, err := http.Get(session.AuthURL())
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	resp.Body.Close()
+
+	res := <-done
+	if res.err != nil {
+		t.Fatalf("callback: %v", res.err)
+	}
+	return session, res.code
+}
+
+func TestAuthCodePKCEPublicClient(t *testing.T) {
+	server := oauthtest.New(t, "client", "")
+	session, code := authorize(t, fakeDeclaration(server, true, true), oauth.Bootstrap{ClientID: "client"})
+	if _, err := session.Exchange(context.Background(), code); err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	if session.Verifier == "" {
+		t.Fatal("expected a PKCE verifier")
+	}
+	authURL, _ := url.Parse(session.AuthURL())
+	if got := authURL.Query().Get("code_challenge"); got != s256(session.Verifier) {
+		t.Fatalf("code_challenge = %q, want S256 of verifier", got)
+	}
+	requests := server.Requests()
+	if form := requests[len(requests)-1]; form.Get("client_id") != "client" || form.Has("client_secret") {
+		t.Fatalf("public client should send only client_id in the body, got %v", form)
+	}
+}
+
+func TestAuthCodeConfidentialWithoutPKCE(t *testing.T) {
+	server := oauthtest.New(t, "client", "s3cret")
+	session, code := authorize(t, fakeDeclaration(server, false, false), oauth.Bootstrap{ClientID: "client", ClientSecret: "s3cret"})
+	if _, err := session.Exchange(context.Background(), code); err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	if session.Verifier != "" || strings.Contains(session.AuthURL(), "code_challenge") {
+		t.Fatal("PKCE parameters sent for a provider that did not declare PKCE")
+	}
+}
+
+func TestAuthCodePKCERejectsWrongVerifier(t *testing.T) {
+	server := oauthtest.New(t, "client", "s3cret")
+	session, code := authorize(t, fakeDeclaration(server, true, false), oauth.Bootstrap{ClientID: "client", ClientSecret: "s3cret"})
+	session.Verifier = strings.Repeat("x", 43)
+	if _, err := session.Exchange(context.Background(), code); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("Exchange with wrong verifier = %v, want invalid_grant", err)
+	}
+}
+
+func TestNewAuthCodeSessionValidatesClient(t *testing.T) {
+	server := oauthtest.New(t, "client", "")
+	if _, err := NewAuthCodeSession(fakeDeclaration(server, true, false), oauth.Bootstrap{ClientID: "client"}, "http://127.0.0.1/cb"); err == nil {
+		t.Error("confidential client without client_secret accepted")
+	}
+	if _, err := NewAuthCodeSession(fakeDeclaration(server, false, true), oauth.Bootstrap{ClientID: "client"}, "http://127.0.0.1/cb"); err == nil {
+		t.Error("public client without PKCE accepted")
+	}
+}
+
+func TestServeAuthCodeRejectsStateMismatch(t *testing.T) {
+	for name, query := range map[string]string{
+		"forged":  "code=x&state=forged",
+		"missing": "code=x",
+		"empty":   "code=x&state=",
+	} {
+		t.Run(name, func(t *testing.T) {
+			session := &AuthCodeSession{State: "expected"}
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("listen: %v", err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			errCh := make(chan error, 1)
+			go func() {
+				_, err := session.serveAuthCode(ctx, listener, "/callback")
+				errCh <- err
+			}()
+			resp, err := http.Get("http://" + listener.Addr().String() + "/callback?" + query)
+			if err != nil {
+				t.Fatalf("callback: %v", err)
+			}
+			resp.Body.Close()
+			if err := <-errCh; err == nil || !strings.Contains(err.Error(), "state mismatch") {
+				t.Fatalf("serveAuthCode = %v, want state mismatch", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package oauthflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/joshp123/gohome/internal/oauth"
+)
+
+// slowDownStep is how much a slow_down response adds to the polling
+// interval for the rest of the flow (RFC 8628 section 3.5).
+var slowDownStep = 5 * time.Second
+
+// DeviceAuthorization is a pending RFC 8628 device authorization.
+type DeviceAuthorization struct {
+	DeviceCode              string
+	UserCode                string
+	VerificationURI         string
+	VerificationURIComplete string
+	ExpiresIn               time.Duration
+	Interval                time.Duration
+}
+
+// VerifyURL is the URL the user opens, with the user code filled in when the
+// provider supports it.
+func (a DeviceAuthorization) VerifyURL() string {
+	if a.VerificationURIComplete != "" {
+		return a.VerificationURIComplete
+	}
+	return a.VerificationURI
+}
+
+// DeviceToken is the token response that ends a device flow.
+type DeviceToken struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int
+	Scope        string
+}
+
+type deviceAuthResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+type deviceTokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int    `json:"expires_in"`
+	TokenType        string `json:"token_type"`
+	Scope            string `json:"scope"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// DeviceAuthorize starts a device flow at decl.DeviceAuthURL.
+func DeviceAuthorize(ctx context.Context, decl oauth.Declaration, bootstrap oauth.Bootstrap) (DeviceAuthorization, error) {
+	form := url.Values{
+		"client_id": {bootstrap.ClientID},
+	}
+	if decl.Scope != "" {
+		form.Set("scope", decl.Scope)
+	}
+	var resp deviceAuthResponse
+	if err := postForm(ctx, decl.DeviceAuthURL, form, &resp); err != nil {
+		return DeviceAuthorization{}, err
+	}
+	if resp.DeviceCode == "" {
+		return DeviceAuthorization{}, fmt.Errorf("device authorization missing device_code")
+	}
+	if resp.Interval == 0 {
+		resp.Interval = 5
+	}
+	if resp.ExpiresIn == 0 {
+		resp.ExpiresIn = 300
+	}
+	return DeviceAuthorization{
+		DeviceCode:              resp.DeviceCode,
+		UserCode:                resp.UserCode,
+		VerificationURI:         resp.VerificationURI,
+		VerificationURIComplete: resp.VerificationURIComplete,
+		ExpiresIn:               time.Duration(resp.ExpiresIn) * time.Second,
+		Interval:                time.Duration(resp.Interval) * time.Second,
+	}, nil
+}
+
+// PollDeviceToken polls decl.DeviceTokenURL until the user approves the
+// device, waiting auth.Interval between attempts and backing off on
+// slow_down.
+func PollDeviceToken(ctx context.Context, decl oauth.Declaration, bootstrap oauth.Bootstrap, auth DeviceAuthorization) (DeviceToken, error) {
+	deadline := time.Now().Add(auth.ExpiresIn)
+	interval := auth.Interval
+	for {
+		if time.Now().After(deadline) {
+			return DeviceToken{}, fmt.Errorf("device authorization timed out")
+		}
+
+		form := url.Values{
+			"client_id":   {bootstrap.ClientID},
+			"device_code": {auth.DeviceCode},
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		}
+		if bootstrap.ClientSecret != "" {
+			form.Set("client_secret", bootstrap.ClientSecret)
+		}
+
+		var token deviceTokenResponse
+		if err := postForm(ctx, decl.DeviceTokenURL, form, &token); err != nil {
+			return DeviceToken{}, err
+		}
+		if token.Error == "" && token.RefreshToken != "" {
+			return DeviceToken{
+				AccessToken:  token.AccessToken,
+				RefreshToken: token.RefreshToken,
+				ExpiresIn:    token.ExpiresIn,
+				Scope:        token.Scope,
+			}, nil
+		}
+		switch token.Error {
+		case "authorization_pending":
+		case "slow_down":
+			interval += slowDownStep
+		case "":
+			return DeviceToken{}, fmt.Errorf("device token missing refresh_token")
+		default:
+			return DeviceToken{}, fmt.Errorf("device token error: %s", token.Error)
+		}
+
+		select {
+		case <-ctx.Done():
+			return DeviceToken{}, fmt.Errorf("device authorization timed out")
+		case <-time.After(interval):
+		}
+	}
+}
+
+func postForm(ctx context.Context, endpoint string, form url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		if _, ok := out.(*deviceTokenResponse); ok {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return fmt.Errorf("oauth http %d", resp.StatusCode)
+			}
+			return nil
+		}
+
+		var body deviceTokenResponse
+		if err := json.NewDecoder(resp.Body).Decode(&body); err == nil && body.Error != "" {
+			return fmt.Errorf("oauth error %d: %s", resp.StatusCode, body.Error)
+		}
+		return fmt.Errorf("oauth http %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,89 @@
+package oauthflow
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshp123/gohome/internal/oauth"
+	"github.com/joshp123/gohome/internal/oauth/oauthtest"
+)
+
+func deviceDeclaration(server *oauthtest.Server) oauth.Declaration {
+	return oauth.Declaration{
+		Provider:       "fake",
+		Flow:           oauth.FlowDevice,
+		TokenURL:       server.TokenURL(),
+		DeviceAuthURL:  server.DeviceAuthURL(),
+		DeviceTokenURL: server.TokenURL(),
+		Scope:          "offline_access",
+	}
+}
+
+// startDevice runs the device authorization and shortens the polling
+// interval so tests don't wait on the provider's seconds.
+func startDevice(t *testing.T, server *oauthtest.Server) (oauth.Declaration, DeviceAuthorization) {
+	t.Helper()
+	decl := deviceDeclaration(server)
+	auth, err := DeviceAuthorize(context.Background(), decl, oauth.Bootstrap{ClientID: "client"})
+	if err != nil {
+		t.Fatalf("DeviceAuthorize: %v", err)
+	}
+	if auth.Interval != time.Second || auth.UserCode == "" || !strings.Contains(auth.VerifyURL(), "user_code=") {
+		t.Fatalf("authorization = %+v", auth)
+	}
+	auth.Interval = time.Millisecond
+	return decl, auth
+}
+
+func TestPollDeviceTokenPendingAndSlowDown(t *testing.T) {
+	old := slowDownStep
+	slowDownStep = 20 * time.Millisecond
+	defer func() { slowDownStep = old }()
+
+	server := oauthtest.New(t, "client", "")
+	server.ScriptDevice("authorization_pending", "slow_down", "authorization_pending", "slow_down")
+	decl, auth := startDevice(t, server)
+
+	start := time.Now()
+	token, err := PollDeviceToken(context.Background(), decl, oauth.Bootstrap{ClientID: "client"}, auth)
+	if err != nil {
+		t.Fatalf("PollDeviceToken: %v", err)
+	}
+	if !server.Valid(token.RefreshToken) {
+		t.Fatalf("refresh token %q not issued by the provider", token.RefreshToken)
+	}
+	if polls := len(server.Requests()); polls != 5 {
+		t.Fatalf("polls = %d, want 4 refusals plus the approval", polls)
+	}
+	// Each slow_down permanently adds a step: 1+1+21+21+41 ms at least.
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Fatalf("polled in %v; slow_down did not back off", elapsed)
+	}
+}
+
+func TestPollDeviceTokenDenied(t *testing.T) {
+	server := oauthtest.New(t, "client", "")
+	server.ScriptDevice("authorization_pending", "access_denied")
+	decl, auth := startDevice(t, server)
+
+	_, err := PollDeviceToken(context.Background(), decl, oauth.Bootstrap{ClientID: "client"}, auth)
+	if err == nil || !strings.Contains(err.Error(), "access_denied") {
+		t.Fatalf("PollDeviceToken = %v, want access_denied", err)
+	}
+}
+
+func TestPollDeviceTokenExpires(t *testing.T) {
+	server := oauthtest.New(t, "client", "")
+	server.ScriptDevice("authorization_pending", "authorization_pending", "authorization_pending")
+	decl, auth := startDevice(t, server)
+	auth.ExpiresIn = -time.Second
+
+	if _, err := PollDeviceToken(context.Background(), decl, oauth.Bootstrap{ClientID: "client"}, auth); err == nil {
+		t.Fatal("expected an expired device authorization to time out")
+	}
+	if polls := len(server.Requests()); polls != 0 {
+		t.Fatalf("polled %d times after expiry", polls)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/joshp123/gohome/internal/oauth"
@@ -26,10 +27,11 @@ type PersistOptions struct {
 	SkipBlob          bool
 }
 
-// DefaultTempPath returns a deterministic temp path for OAuth state.
-func DefaultTempPath(provider string) string {
+// DefaultTempPath returns a deterministic temp path for OAuth state. key is
+// a provider or provider/account (see oauth.Declaration.Key).
+func DefaultTempPath(key string) string {
 	timestamp := time.Now().UTC().Format("20060102-150405")
-	name := fmt.Sprintf("gohome-oauth-%s-%s.json", provider, timestamp)
+	name := fmt.Sprintf("gohome-oauth-%s-%s.json", strings.ReplaceAll(key, "/", "-"), timestamp)
 	return filepath.Join(os.TempDir(), name)
 }
 
@@ -70,7 +72,7 @@ func PersistState(ctx context.Context, decl oauth.Declaration, state oauth.State
 	if err != nil {
 		return result, err
 	}
-	if err := blob.Save(ctx, decl.Provider, payload); err != nil {
+	if err := blob.Save(ctx, decl.Key(), payload); err != nil {
 		return result, err
 	}
 	result.BlobSaved = true
@@ -9,7 +9,10 @@ import (
 )
 
 func init() {
-	Register(func(cfg *configv1.Config) (core.Plugin, bool) {
+	Register("airgradient", func(cfg *configv1.Config) (core.Plugin, bool) {
 		return airgradient.NewPlugin(cfg.GetAirgradient(), cfg.GetOauth())
+	}, func(cfg *configv1.Config) error {
+		_, err := airgradient.ConfigFromProto(cfg.GetAirgradient())
+		return err
 	})
 }
@@ -9,7 +9,10 @@ import (
 )
 
 func init() {
-	Register(func(cfg *configv1.Config) (core.Plugin, bool) {
+	Register("daikin", func(cfg *configv1.Config) (core.Plugin, bool) {
 		return daikin.NewPlugin(cfg.GetDaikin(), cfg.GetOauth())
+	}, func(cfg *configv1.Config) error {
+		_, err := daikin.ConfigsFromProto(cfg.GetDaikin())
+		return err
 	})
 }
@@ -9,7 +9,10 @@ import (
 )
 
 func init() {
-	Register(func(cfg *configv1.Config) (core.Plugin, bool) {
+	Register("growatt", func(cfg *configv1.Config) (core.Plugin, bool) {
 		return growatt.NewPlugin(cfg.GetGrowatt(), cfg.GetOauth())
+	}, func(cfg *configv1.Config) error {
+		_, err := growatt.ConfigFromProto(cfg.GetGrowatt())
+		return err
 	})
 }
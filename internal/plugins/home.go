@@ -9,7 +9,7 @@ import (
 )
 
 func init() {
-	Register(func(cfg *configv1.Config) (core.Plugin, bool) {
+	Register("home", func(cfg *configv1.Config) (core.Plugin, bool) {
 		return home.NewPlugin(cfg.GetHome())
-	})
+	}, nil)
 }
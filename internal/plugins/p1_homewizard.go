@@ -9,7 +9,10 @@ import (
 )
 
 func init() {
-	Register(func(cfg *configv1.Config) (core.Plugin, bool) {
+	Register("p1_homewizard", func(cfg *configv1.Config) (core.Plugin, bool) {
 		return p1_homewizard.NewPlugin(cfg.GetP1Homewizard(), cfg.GetOauth())
+	}, func(cfg *configv1.Config) error {
+		_, err := p1_homewizard.ConfigFromProto(cfg.GetP1Homewizard())
+		return err
 	})
 }
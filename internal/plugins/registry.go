@@ -1,6 +1,8 @@
 package plugins
 
 import (
+	"fmt"
+
 	"github.com/joshp123/gohome/internal/core"
 	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
 )
@@ -8,21 +10,48 @@ import (
 // Factory builds a plugin instance from the loaded config.
 type Factory func(*configv1.Config) (core.Plugin, bool)
 
-var compiled []Factory
+// ConfigCheck validates a plugin's config section without side effects.
+type ConfigCheck func(*configv1.Config) error
 
-// Register adds a compiled-in plugin factory to the registry.
-func Register(factory Factory) {
-	compiled = append(compiled, factory)
+type registration struct {
+	id      string
+	factory Factory
+	check   ConfigCheck
+}
+
+var compiled []registration
+
+// Register adds a compiled-in plugin factory to the registry. check may be nil
+// for plugins without config to validate.
+func Register(id string, factory Factory, check ConfigCheck) {
+	compiled = append(compiled, registration{id: id, factory: factory, check: check})
+}
+
+// IDs returns the plugin IDs compiled into this build, in registration order.
+func IDs() []string {
+	out := make([]string, 0, len(compiled))
+	for _, reg := range compiled {
+		out = append(out, reg.id)
+	}
+	return out
 }
 
 // Compiled returns the configured plugin instances for this build.
 func Compiled(cfg *configv1.Config) []core.Plugin {
+	return Build(cfg, nil)
+}
+
+// Build constructs only the plugins whose IDs are in want; a nil want builds all.
+func Build(cfg *configv1.Config, want map[string]bool) []core.Plugin {
 	if cfg == nil {
 		return nil
 	}
 	out := make([]core.Plugin, 0, len(compiled))
-	for _, factory := range compiled {
-		plugin, ok := factory(cfg)
+	for _, reg := range compiled {
+		if want != nil && !want[reg.id] {
+			continue
+		}
+		plugin, ok := reg.factory(cfg)
 		if !ok {
 			continue
 		}
@@ -30,3 +59,18 @@ func Compiled(cfg *configv1.Config) []core.Plugin {
 	}
 	return out
 }
+
+// CheckConfig runs the config check of every compiled plugin in enabled and
+// returns all failures.
+func CheckConfig(cfg *configv1.Config, enabled map[string]bool) []error {
+	var errs []error
+	for _, reg := range compiled {
+		if !enabled[reg.id] || reg.check == nil {
+			continue
+		}
+		if err := reg.check(cfg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", reg.id, err))
+		}
+	}
+	return errs
+}
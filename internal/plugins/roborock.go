@@ -9,7 +9,10 @@ import (
 )
 
 func init() {
-	Register(func(cfg *configv1.Config) (core.Plugin, bool) {
+	Register("roborock", func(cfg *configv1.Config) (core.Plugin, bool) {
 		return roborock.NewPlugin(cfg.GetRoborock(), cfg.GetOauth())
+	}, func(cfg *configv1.Config) error {
+		_, err := roborock.ConfigFromProto(cfg.GetRoborock())
+		return err
 	})
 }
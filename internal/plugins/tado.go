@@ -9,7 +9,10 @@ import (
 )
 
 func init() {
-	Register(func(cfg *configv1.Config) (core.Plugin, bool) {
+	Register("tado", func(cfg *configv1.Config) (core.Plugin, bool) {
 		return tado.NewPlugin(cfg.GetTado(), cfg.GetOauth())
+	}, func(cfg *configv1.Config) error {
+		_, err := tado.ConfigsFromProto(cfg.GetTado())
+		return err
 	})
 }
@@ -9,7 +9,10 @@ import (
 )
 
 func init() {
-	Register(func(cfg *configv1.Config) (core.Plugin, bool) {
+	Register("weheat", func(cfg *configv1.Config) (core.Plugin, bool) {
 		return weheat.NewPlugin(cfg.GetWeheat(), cfg.GetOauth())
+	}, func(cfg *configv1.Config) error {
+		_, err := weheat.ConfigFromProto(cfg.GetWeheat())
+		return err
 	})
 }
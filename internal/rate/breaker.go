@@ -0,0 +1,174 @@
+package rate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// BreakerPolicy opens a provider's circuit after Failures consecutive failed
+// calls (transport errors, timeouts or 5xx responses). While open, calls fail
+// fast with CircuitOpenError instead of waiting out the HTTP timeout. After
+// CoolOff a single probe is let through: success closes the circuit, failure
+// re-opens it for another CoolOff.
+type BreakerPolicy struct {
+	Failures int
+	CoolOff  time.Duration
+}
+
+// DefaultBreaker opens after 5 consecutive failures and probes every 30s.
+func DefaultBreaker() BreakerPolicy {
+	return BreakerPolicy{Failures: 5, CoolOff: 30 * time.Second}
+}
+
+func (p BreakerPolicy) enabled() bool {
+	return p.Failures > 0
+}
+
+// BreakerState is the state of a provider's circuit.
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitOpenError is returned while a provider's circuit is open.
+type CircuitOpenError struct {
+	Provider  string
+	Failures  int
+	LastError string
+	RetryAt   time.Time
+}
+
+func (e CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s circuit open after %d consecutive failures (retry at %s): %s", e.Provider, e.Failures, e.RetryAt.UTC().Format(time.RFC3339), e.LastError)
+}
+
+// CircuitStatus describes a provider's circuit.
+type CircuitStatus struct {
+	State     BreakerState
+	Failures  int // consecutive failures so far
+	LastError string
+	RetryAt   time.Time // when an open circuit lets a probe through
+}
+
+// Message is a one-line description for health messages, or "" when closed.
+func (c CircuitStatus) Message() string {
+	switch c.State {
+	case BreakerOpen:
+		return fmt.Sprintf("circuit open after %d consecutive failures until %s: %s", c.Failures, c.RetryAt.UTC().Format(time.RFC3339), c.LastError)
+	case BreakerHalfOpen:
+		return fmt.Sprintf("circuit half-open, probing after %d consecutive failures: %s", c.Failures, c.LastError)
+	default:
+		return ""
+	}
+}
+
+// Circuit reports the circuit of provider's live guard. Providers without a
+// guard or breaker are always closed.
+func Circuit(provider string) CircuitStatus {
+	registry.mu.Lock()
+	g := registry.guards[provider]
+	registry.mu.Unlock()
+	if g == nil {
+		return CircuitStatus{}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.breaker.status()
+}
+
+// breaker is mutated under Guard.mu.
+type breaker struct {
+	state     BreakerState
+	failures  int
+	lastError string
+	openUntil time.Time
+	probing   bool
+}
+
+func (b *breaker) status() CircuitStatus {
+	return CircuitStatus{State: b.state, Failures: b.failures, LastError: b.lastError, RetryAt: b.openUntil}
+}
+
+// admit reports whether a call may go out now. An open circuit past its
+// cool-off becomes half-open and admits exactly one probe.
+func (g *Guard) admit(now time.Time) error {
+	policy := g.decl.BreakerPolicy()
+	if !policy.enabled() {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	b := &g.breaker
+	if b.state == BreakerOpen && !now.Before(b.openUntil) {
+		g.setBreakerState(BreakerHalfOpen)
+	}
+	switch {
+	case b.state == BreakerClosed:
+		return nil
+	case b.state == BreakerHalfOpen && !b.probing:
+		b.probing = true
+		return nil
+	default:
+		return CircuitOpenError{Provider: g.decl.ProviderName(), Failures: b.failures, LastError: b.lastError, RetryAt: b.openUntil}
+	}
+}
+
+// recordOutcome feeds the result of an admitted call to the breaker. Calls
+// that never reached the provider, or that the caller cancelled, release a
+// probe without counting either way.
+func (g *Guard) recordOutcome(now time.Time, status int, err error) {
+	policy := g.decl.BreakerPolicy()
+	if !policy.enabled() {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	b := &g.breaker
+
+	var limited RateLimitError
+	if errors.As(err, &limited) || errors.Is(err, context.Canceled) {
+		b.probing = false
+		return
+	}
+	if err == nil && status < 500 {
+		b.failures = 0
+		b.probing = false
+		g.setBreakerState(BreakerClosed)
+		return
+	}
+
+	b.failures++
+	if err != nil {
+		b.lastError = err.Error()
+	} else {
+		b.lastError = fmt.Sprintf("HTTP %d", status)
+	}
+	if b.state == BreakerHalfOpen || b.failures >= policy.Failures {
+		b.openUntil = now.Add(policy.CoolOff)
+		b.probing = false
+		g.setBreakerState(BreakerOpen)
+	}
+}
+
+func (g *Guard) setBreakerState(state BreakerState) {
+	g.breaker.state = state
+	breakerGauge.WithLabelValues(g.decl.ProviderName()).Set(float64(state))
+}
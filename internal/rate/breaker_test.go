@@ -0,0 +1,110 @@
+package rate
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	clock := newFakeClock()
+	provider := newFakeProvider(t)
+	decl := Provider("breaker-test").Unmetered().Breaker(BreakerPolicy{Failures: 3, CoolOff: 30 * time.Second}).WithClock(clock)
+	client := WrapHTTP(decl, nil)
+	t.Cleanup(func() {
+		registry.mu.Lock()
+		delete(registry.guards, "breaker-test")
+		registry.mu.Unlock()
+	})
+
+	call := func() error {
+		resp, err := client.Get(provider.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	provider.respond(http.StatusServiceUnavailable, nil)
+	for i := 0; i < 3; i++ {
+		if err := call(); err != nil {
+			t.Fatalf("failure %d: %v", i+1, err)
+		}
+	}
+	if c := Circuit("breaker-test"); c.State != BreakerOpen || c.Failures != 3 || c.LastError != "HTTP 503" {
+		t.Fatalf("circuit = %+v, want open after 3 failures", c)
+	}
+
+	// Open: fail fast without reaching the provider.
+	var open CircuitOpenError
+	if err := call(); !errors.As(err, &open) || !open.RetryAt.Equal(clock.Now().Add(30*time.Second)) {
+		t.Fatalf("call while open = %v, want CircuitOpenError", err)
+	}
+	if provider.Hits() != 3 {
+		t.Fatalf("provider hits = %d, want 3", provider.Hits())
+	}
+
+	// Half-open probe fails: open again for another cool-off.
+	clock.Advance(30 * time.Second)
+	if err := call(); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if c := Circuit("breaker-test"); c.State != BreakerOpen || c.Failures != 4 {
+		t.Fatalf("circuit after failed probe = %+v", c)
+	}
+
+	// Only one probe at a time.
+	clock.Advance(30 * time.Second)
+	g := client.Transport.(*roundTripper).guard
+	if err := g.admit(clock.Now()); err != nil {
+		t.Fatalf("probe not admitted: %v", err)
+	}
+	if err := g.admit(clock.Now()); !errors.As(err, &open) {
+		t.Fatalf("second caller during probe = %v, want CircuitOpenError", err)
+	}
+	if c := Circuit("breaker-test"); c.State != BreakerHalfOpen || c.Message() == "" {
+		t.Fatalf("circuit during probe = %+v", c)
+	}
+
+	// A refused or cancelled probe releases the slot; a successful one closes.
+	g.recordOutcome(clock.Now(), 0, RateLimitError{})
+	provider.respond(http.StatusOK, nil)
+	if err := call(); err != nil {
+		t.Fatalf("successful probe: %v", err)
+	}
+	if c := Circuit("breaker-test"); c.State != BreakerClosed || c.Failures != 0 || c.Message() != "" {
+		t.Fatalf("circuit after success = %+v, want closed", c)
+	}
+}
+
+func TestCircuitBreakerCountsTransportErrorsAndServesCache(t *testing.T) {
+	clock := newFakeClock()
+	fail := true
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if fail {
+			return nil, errors.New("dial tcp: connection refused")
+		}
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: http.NoBody, Request: req}, nil
+	})
+	g := newGuard(Provider("breaker-cache").Unmetered().CacheFor(time.Hour).Breaker(BreakerPolicy{Failures: 1, CoolOff: time.Minute}).WithClock(clock))
+	client := &http.Client{Transport: &roundTripper{base: base, guard: g}}
+
+	fail = false
+	resp, err := client.Get("http://provider.invalid/a")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+
+	fail = true
+	if _, err := client.Get("http://provider.invalid/b"); err == nil {
+		t.Fatalf("expected transport error")
+	}
+	if g.Status(clock.Now()).Circuit.State != BreakerOpen {
+		t.Fatalf("circuit not open after transport error")
+	}
+	if resp, err := client.Get("http://provider.invalid/a"); err != nil || resp.StatusCode != 200 {
+		t.Fatalf("open circuit should serve cached response: %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package rate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// isRead reports whether req is safe to coalesce and serve from cache.
+func isRead(req *http.Request) bool {
+	return req.Method == http.MethodGet || req.Method == http.MethodHead
+}
+
+// flightGroup tracks in-flight calls by cache key.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+type flight struct {
+	done    chan struct{}
+	waiters int // callers sharing the leader's result; guarded by flightGroup.mu
+	entry   cacheEntry
+	err     error
+}
+
+// join returns the in-flight call for key, starting one (leader true) if
+// there is none.
+func (f *flightGroup) join(key string) (fl *flight, leader bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if fl := f.calls[key]; fl != nil {
+		fl.waiters++
+		return fl, false
+	}
+	if f.calls == nil {
+		f.calls = make(map[string]*flight)
+	}
+	fl = &flight{done: make(chan struct{})}
+	f.calls[key] = fl
+	return fl, true
+}
+
+func (f *flightGroup) finish(key string, fl *flight) {
+	f.mu.Lock()
+	delete(f.calls, key)
+	f.mu.Unlock()
+	close(fl.done)
+}
+
+// coalesced makes a guarded call, sharing it with identical in-flight calls
+// so only the first spends budget. Callers joining a call that was refused,
+// or whose leader gave up, make their own call: the refusal was for the
+// leader's priority, not theirs.
+func (rt *roundTripper) coalesced(req *http.Request, bodyBytes []byte, key string) (*http.Response, error) {
+	fl, leader := rt.guard.flights.join(key)
+	if leader {
+		fl.entry, fl.err = rt.buffered(req, bodyBytes)
+		rt.guard.flights.finish(key, fl)
+	} else {
+		select {
+		case <-fl.done:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		var limited RateLimitError
+		if errors.As(fl.err, &limited) || errors.Is(fl.err, context.Canceled) || errors.Is(fl.err, context.DeadlineExceeded) {
+			return rt.guarded(req, bodyBytes)
+		}
+		coalescedCounter.WithLabelValues(rt.guard.decl.ProviderName()).Inc()
+	}
+	if fl.err != nil {
+		return nil, fl.err
+	}
+	return cloneResponse(req, fl.entry.status, fl.entry.header, fl.entry.body), nil
+}
+
+// buffered makes a guarded call and reads the whole response so it can be
+// handed to every caller sharing it.
+func (rt *roundTripper) buffered(req *http.Request, bodyBytes []byte) (cacheEntry, error) {
+	resp, err := rt.guarded(req, bodyBytes)
+	if err != nil {
+		return cacheEntry{}, err
+	}
+	defer resp.Body.Close()
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return cacheEntry{}, err
+	}
+	return cacheEntry{status: resp.StatusCode, header: resp.Header, body: buf}, nil
+}
+
+// revalidate refreshes key in the background at Background priority unless a
+// refresh is already running. The caller has been served the cached entry.
+func (rt *roundTripper) revalidate(req *http.Request, bodyBytes []byte, key string) {
+	g := rt.guard
+	g.mu.Lock()
+	if g.refreshing[key] {
+		g.mu.Unlock()
+		return
+	}
+	g.refreshing[key] = true
+	g.mu.Unlock()
+
+	ctx := WithPriority(context.WithoutCancel(req.Context()), Background)
+	refresh := req.Clone(ctx)
+	refresh.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+	go func() {
+		defer func() {
+			g.mu.Lock()
+			delete(g.refreshing, key)
+			g.mu.Unlock()
+		}()
+		if resp, err := rt.coalesced(refresh, bodyBytes, key); err == nil {
+			resp.Body.Close()
+		}
+	}()
+}
+
+// freshResponse returns the unexpired successful cache entry for key, and
+// whether it is past half its TTL and due for revalidation.
+func (g *Guard) freshResponse(req *http.Request, key string) (*http.Response, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.clock.Now()
+	entry, ok := g.cache[key]
+	if !ok || now.After(entry.expires) || entry.status < 200 || entry.status >= 300 {
+		g.stats.cacheMisses++
+		return nil, false
+	}
+	g.stats.cacheHits++
+	stale := now.Sub(entry.stored) >= g.decl.CacheTTL()/2
+	return cloneResponse(req, entry.status, entry.header, entry.body), stale
+}
+
+func (g *Guard) clearCache() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	clear(g.cache)
+}
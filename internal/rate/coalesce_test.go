@@ -0,0 +1,202 @@
+package rate
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCoalesceIdenticalReads(t *testing.T) {
+	clock := newFakeClock()
+	provider := newFakeProvider(t)
+	client := guardedClient(Provider("coalesce").MaxRequestsPer(Minute, 10).WithClock(clock))
+	g := client.Transport.(*roundTripper).guard
+	release := provider.hold()
+
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = get(t, client, provider.URL, Interactive)
+		}()
+	}
+	waitFor(t, func() bool { return flightWaiters(g, provider.URL) == 4 })
+	release()
+	wg.Wait()
+
+	for i, got := range results {
+		if got != "hit 1" {
+			t.Fatalf("caller %d got %q, want hit 1", i, got)
+		}
+	}
+	if hits := provider.Hits(); hits != 1 {
+		t.Fatalf("provider hits = %d, want 1", hits)
+	}
+	if st := g.Status(clock.Now()); st.Windows[0].Remaining != 9 {
+		t.Fatalf("remaining = %d, want 9 (one call charged)", st.Windows[0].Remaining)
+	}
+}
+
+func TestCoalesceFollowerRetriesAfterRefusal(t *testing.T) {
+	provider := newFakeProvider(t)
+	client := guardedClient(Provider("coalesce-refused").MaxRequestsPer(Minute, 10).WithClock(newFakeClock()))
+	g := client.Transport.(*roundTripper).guard
+
+	// Stand in for a background leader that is refused.
+	key := "GET " + provider.URL + " " + emptyBodyHash
+	fl, leader := g.flights.join(key)
+	if !leader {
+		t.Fatalf("expected to lead the flight")
+	}
+	done := make(chan string)
+	go func() { done <- get(t, client, provider.URL, Interactive) }()
+	waitFor(t, func() bool { return flightWaiters(g, provider.URL) == 1 })
+	fl.err = RateLimitError{Provider: "coalesce-refused", Reason: "budget", Priority: Background}
+	g.flights.finish(key, fl)
+
+	if got := <-done; got != "hit 1" {
+		t.Fatalf("follower got %q, want its own call", got)
+	}
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	clock := newFakeClock()
+	provider := newFakeProvider(t)
+	client := guardedClient(Provider("swr").
+		MaxRequestsPer(Minute, 100).
+		CacheFor(time.Minute).
+		StaleWhileRevalidate().
+		WithClock(clock))
+	g := client.Transport.(*roundTripper).guard
+
+	if got := get(t, client, provider.URL, Interactive); got != "hit 1" {
+		t.Fatalf("first call = %q", got)
+	}
+	clock.Advance(10 * time.Second)
+	if got := get(t, client, provider.URL, Interactive); got != "hit 1" || provider.Hits() != 1 {
+		t.Fatalf("fresh hit = %q with %d provider hits, want cached hit 1", got, provider.Hits())
+	}
+
+	// Past half the TTL: still served, refreshed in the background.
+	clock.Advance(25 * time.Second)
+	if got := get(t, client, provider.URL, Interactive); got != "hit 1" {
+		t.Fatalf("stale hit = %q, want hit 1", got)
+	}
+	waitFor(t, func() bool {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		return provider.Hits() == 2 && len(g.refreshing) == 0
+	})
+	if got := get(t, client, provider.URL, Interactive); got != "hit 2" {
+		t.Fatalf("after refresh = %q, want hit 2", got)
+	}
+
+	// A successful write clears the cache.
+	req, _ := http.NewRequest(http.MethodPost, provider.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	resp.Body.Close()
+	if got := get(t, client, provider.URL, Interactive); got != "hit 4" {
+		t.Fatalf("after write = %q, want hit 4", got)
+	}
+
+	st := g.Status(clock.Now())
+	if st.CacheHits != 3 || st.CacheMisses != 2 {
+		t.Fatalf("cache hits=%d misses=%d, want 3/2", st.CacheHits, st.CacheMisses)
+	}
+}
+
+func TestCoalesceKeepsCredentialsApart(t *testing.T) {
+	gate := make(chan struct{})
+	var mu sync.Mutex
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits++
+		mu.Unlock()
+		<-gate
+		fmt.Fprintf(w, "homes of %s", r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+	client := guardedClient(Provider("coalesce-auth").
+		MaxRequestsPer(Minute, 10).
+		CacheFor(time.Minute).
+		StaleWhileRevalidate().
+		WithClock(newFakeClock()))
+
+	getAs := func(token string) string {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Errorf("get as %s: %v", token, err)
+			return ""
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	tokens := []string{"alice", "bob"}
+	results := make([]string, len(tokens))
+	var wg sync.WaitGroup
+	for i, token := range tokens {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = getAs(token)
+		}()
+	}
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return hits == 2
+	})
+	close(gate)
+	wg.Wait()
+
+	for i, token := range tokens {
+		if want := "homes of Bearer " + token; results[i] != want {
+			t.Fatalf("%s got %q, want %q", token, results[i], want)
+		}
+	}
+	// Cached responses stay per credential too.
+	if got := getAs("bob"); got != "homes of Bearer bob" {
+		t.Fatalf("cached read as bob = %q", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if hits != 2 {
+		t.Fatalf("provider hits = %d, want 2", hits)
+	}
+}
+
+const emptyBodyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func flightWaiters(g *Guard, url string) int {
+	g.flights.mu.Lock()
+	defer g.flights.mu.Unlock()
+	if fl := g.flights.calls["GET "+url+" "+emptyBodyHash]; fl != nil {
+		return fl.waiters
+	}
+	return 0
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -2,6 +2,7 @@ package rate
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -15,14 +16,15 @@ import (
 type RateLimitError struct {
 	Provider string
 	Reason   string
+	Priority Priority
 	RetryAt  time.Time
 }
 
 func (e RateLimitError) Error() string {
 	if e.RetryAt.IsZero() {
-		return fmt.Sprintf("%s rate limited: %s", e.Provider, e.Reason)
+		return fmt.Sprintf("%s rate limited: %s for %s requests", e.Provider, e.Reason, e.Priority)
 	}
-	return fmt.Sprintf("%s rate limited: %s (retry at %s)", e.Provider, e.Reason, e.RetryAt.UTC().Format(time.RFC3339))
+	return fmt.Sprintf("%s rate limited: %s for %s requests (retry at %s)", e.Provider, e.Reason, e.Priority, e.RetryAt.UTC().Format(time.RFC3339))
 }
 
 type Decision struct {
@@ -41,28 +43,38 @@ type cacheEntry struct {
 	status   int
 	header   http.Header
 	body     []byte
+	stored   time.Time
 	expires  time.Time
 	provider string
 }
 
 // State tracks observed limits.
 type State struct {
-	remaining   map[Window]int
-	limits      map[Window]int
-	budgetFloor map[Window]int
-	buckets     map[Window]*bucket
-	hasHeaders  map[Window]bool
-	cooldown    time.Time
-	lastStatus  int
+	remaining  map[Window]int
+	limits     map[Window]int
+	buckets    map[Window]*bucket
+	hasHeaders map[Window]bool
+	cooldown   time.Time
+	lastStatus int
 }
 
 // Guard enforces rate limits for a provider.
 type Guard struct {
-	decl Declaration
-	mu   sync.Mutex
+	decl  Declaration
+	clock Clock
+	mu    sync.Mutex
 	// state is mutated under mu
 	state State
 	cache map[string]cacheEntry
+	// stats is mutated under mu
+	stats stats
+	// refreshing marks cache keys with a background revalidation running
+	refreshing map[string]bool
+	flights    flightGroup
+	// breaker is mutated under mu
+	breaker breaker
+	// sleep waits between retries; tests replace it
+	sleep func(context.Context, time.Duration) error
 }
 
 // WrapHTTP wraps an http.Client with rate-limit enforcement.
@@ -76,6 +88,7 @@ func WrapHTTP(decl Declaration, base *http.Client) *http.Client {
 		transport = http.DefaultTransport
 	}
 	guard := newGuard(decl)
+	register(guard)
 	client.Transport = &roundTripper{
 		base:  transport,
 		guard: guard,
@@ -84,12 +97,15 @@ func WrapHTTP(decl Declaration, base *http.Client) *http.Client {
 }
 
 func newGuard(decl Declaration) *Guard {
+	clock := decl.clock
+	if clock == nil {
+		clock = systemClock{}
+	}
 	state := State{
-		remaining:   make(map[Window]int),
-		limits:      make(map[Window]int),
-		budgetFloor: make(map[Window]int),
-		buckets:     make(map[Window]*bucket),
-		hasHeaders:  make(map[Window]bool),
+		remaining:  make(map[Window]int),
+		limits:     make(map[Window]int),
+		buckets:    make(map[Window]*bucket),
+		hasHeaders: make(map[Window]bool),
 	}
 	for window, limit := range decl.Limits() {
 		state.limits[window] = limit
@@ -97,17 +113,17 @@ func newGuard(decl Declaration) *Guard {
 		state.buckets[window] = &bucket{
 			capacity: limit,
 			tokens:   float64(limit),
-			last:     time.Now(),
+			last:     clock.Now(),
 		}
 	}
-	for window, floor := range decl.BudgetFloors() {
-		state.budgetFloor[window] = floor
-	}
 
 	return &Guard{
-		decl:  decl,
-		state: state,
-		cache: make(map[string]cacheEntry),
+		decl:       decl,
+		clock:      clock,
+		state:      state,
+		cache:      make(map[string]cacheEntry),
+		refreshing: make(map[string]bool),
+		sleep:      sleepContext,
 	}
 }
 
@@ -121,25 +137,100 @@ func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 	if err != nil {
 		return nil, err
 	}
+	if !isRead(req) {
+		return rt.guarded(req, bodyBytes)
+	}
 
-	decision := rt.guard.ShouldCall(time.Now())
-	if !decision.Allowed {
+	key := cacheKey(req, bodyBytes)
+	if rt.guard.decl.StaleWhileRevalidates() {
+		if resp, stale := rt.guard.freshResponse(req, key); resp != nil {
+			if stale {
+				rt.revalidate(req, bodyBytes, key)
+			}
+			return resp, nil
+		}
+	}
+	return rt.coalesced(req, bodyBytes, key)
+}
+
+// guarded makes a budgeted call, retrying it as the declared RetryPolicy
+// allows.
+func (rt *roundTripper) guarded(req *http.Request, bodyBytes []byte) (*http.Response, error) {
+	provider := rt.guard.decl.ProviderName()
+	policy := rt.guard.decl.RetryPolicy()
+	for n := 1; ; n++ {
+		attempt := req
+		if n > 1 {
+			attempt = req.Clone(req.Context())
+			attempt.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+		}
+		resp, err := rt.attempt(attempt, bodyBytes)
+		var respBody []byte
+		if err == nil && policy.RetryOnResponse != nil {
+			if respBody, err = bufferBody(resp); err != nil {
+				resp = nil
+			}
+		}
+		delay, reason, ok := rt.guard.retryDelay(req, n, resp, respBody, err)
+		if !ok {
+			if n > 1 && (err != nil || policy.retryableResponse(resp, respBody)) {
+				retriesExhaustedCounter.WithLabelValues(provider).Inc()
+			}
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		retryCounter.WithLabelValues(provider, reason).Inc()
+		if sleepErr := rt.guard.sleep(req.Context(), delay); sleepErr != nil {
+			return nil, sleepErr
+		}
+	}
+}
+
+// attempt makes one budgeted call, falling back to the cache when refused or
+// when the provider's circuit is open.
+func (rt *roundTripper) attempt(req *http.Request, bodyBytes []byte) (*http.Response, error) {
+	now := rt.guard.clock.Now()
+	if err := rt.guard.admit(now); err != nil {
 		if cached := rt.guard.cachedResponse(req, bodyBytes); cached != nil {
 			return cached, nil
 		}
-		return nil, RateLimitError{
+		return nil, err
+	}
+
+	priority := PriorityFrom(req.Context())
+	decision := rt.guard.ShouldCall(now, priority)
+	if !decision.Allowed {
+		refused := RateLimitError{
 			Provider: rt.guard.decl.ProviderName(),
 			Reason:   decision.Reason,
+			Priority: priority,
 			RetryAt:  decision.RetryAt,
 		}
+		rt.guard.recordOutcome(now, 0, refused)
+		refusedCounter.WithLabelValues(rt.guard.decl.ProviderName(), priority.String(), decision.Reason).Inc()
+		cached := rt.guard.cachedResponse(req, bodyBytes)
+		rt.guard.recordDenial(now, priority, decision, cached != nil)
+		if cached != nil {
+			return cached, nil
+		}
+		return nil, refused
 	}
 
 	resp, err := rt.base.RoundTrip(req)
 	if err != nil {
+		rt.guard.recordOutcome(rt.guard.clock.Now(), 0, err)
 		return resp, err
 	}
+	rt.guard.recordOutcome(rt.guard.clock.Now(), resp.StatusCode, nil)
 
 	rt.guard.RecordResponse(resp.StatusCode, resp.Header)
+	if !isRead(req) && rt.guard.decl.StaleWhileRevalidates() && resp.StatusCode < 300 {
+		// A successful write makes served-while-fresh reads wrong.
+		rt.guard.clearCache()
+	}
 	resp, err = rt.guard.maybeCacheResponse(req, bodyBytes, resp)
 	if err != nil {
 		return nil, err
@@ -148,7 +239,10 @@ func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 	return resp, nil
 }
 
-func (g *Guard) ShouldCall(now time.Time) Decision {
+// ShouldCall decides whether a request of the given priority may be made at
+// now and, if so, charges it against every window. Each priority must leave
+// its floor (see Declaration.FloorFor) untouched.
+func (g *Guard) ShouldCall(now time.Time, priority Priority) Decision {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
@@ -157,6 +251,9 @@ func (g *Guard) ShouldCall(now time.Time) Decision {
 	}
 
 	if !g.decl.HasLimits() {
+		if g.decl.unmetered {
+			return Decision{Allowed: true}
+		}
 		return Decision{Allowed: false, Reason: "disabled"}
 	}
 
@@ -164,23 +261,36 @@ func (g *Guard) ShouldCall(now time.Time) Decision {
 		return Decision{Allowed: false, Reason: "cooldown", RetryAt: g.state.cooldown}
 	}
 
+	// Check every window before charging any, so a refusal spends nothing.
 	for window, limit := range g.state.limits {
-		floor := g.state.budgetFloor[window]
+		floor := g.decl.FloorFor(priority, window)
 		if g.state.hasHeaders[window] {
 			if g.state.remaining[window] <= floor {
 				return Decision{Allowed: false, Reason: "budget", RetryAt: g.state.cooldown}
 			}
-			g.state.remaining[window]--
 			continue
 		}
 		if limit <= 0 {
 			return Decision{Allowed: false, Reason: "disabled"}
 		}
-		if !consumeToken(g.state.buckets[window], windowDuration(window)) {
-			retryAt := g.state.buckets[window].last.Add(windowDuration(window) / time.Duration(limit))
+		b := g.state.buckets[window]
+		if b == nil {
+			continue
+		}
+		refill(b, windowDuration(window), now)
+		if need := float64(floor) + 1 - b.tokens; need > 0 {
+			perSecond := float64(b.capacity) / windowDuration(window).Seconds()
+			retryAt := now.Add(time.Duration(need / perSecond * float64(time.Second)))
 			return Decision{Allowed: false, Reason: "budget", RetryAt: retryAt}
 		}
 	}
+	for window := range g.state.limits {
+		if g.state.hasHeaders[window] {
+			g.state.remaining[window]--
+		} else if b := g.state.buckets[window]; b != nil {
+			b.tokens--
+		}
+	}
 
 	return Decision{Allowed: true}
 }
@@ -193,13 +303,13 @@ func (g *Guard) RecordResponse(status int, headers http.Header) {
 	lastStatusGauge.WithLabelValues(g.decl.ProviderName()).Set(float64(status))
 
 	parsed := parseHeaders(headers, g.decl.Headers())
-	now := time.Now()
+	now := g.clock.Now()
 
 	if parsed.retryAfter > 0 {
 		g.state.cooldown = now.Add(time.Duration(parsed.retryAfter) * time.Second)
 		retryAfterGauge.WithLabelValues(g.decl.ProviderName()).Set(float64(parsed.retryAfter))
 	}
-	if parsed.resetAfter > 0 && g.state.cooldown.IsZero() {
+	if parsed.resetAfter > 0 && !now.Before(g.state.cooldown) {
 		g.state.cooldown = now.Add(time.Duration(parsed.resetAfter) * time.Second)
 		retryAfterGauge.WithLabelValues(g.decl.ProviderName()).Set(float64(parsed.resetAfter))
 	}
@@ -226,7 +336,7 @@ func (g *Guard) cachedResponse(req *http.Request, body []byte) *http.Response {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 	entry, ok := g.cache[key]
-	if !ok || time.Now().After(entry.expires) {
+	if !ok || g.clock.Now().After(entry.expires) {
 		return nil
 	}
 	return cloneResponse(req, entry.status, entry.header, entry.body)
@@ -246,11 +356,13 @@ func (g *Guard) maybeCacheResponse(req *http.Request, body []byte, resp *http.Re
 	key := cacheKey(req, body)
 
 	g.mu.Lock()
+	now := g.clock.Now()
 	g.cache[key] = cacheEntry{
 		status:  resp.StatusCode,
 		header:  clone.Header.Clone(),
 		body:    buf,
-		expires: time.Now().Add(g.decl.CacheTTL()),
+		stored:  now,
+		expires: now.Add(g.decl.CacheTTL()),
 	}
 	g.mu.Unlock()
 
@@ -303,8 +415,8 @@ func windowDuration(window Window) time.Duration {
 	}
 }
 
-func consumeToken(b *bucket, window time.Duration) bool {
-	now := time.Now()
+// refill adds the tokens earned since the bucket was last touched.
+func refill(b *bucket, window time.Duration, now time.Time) {
 	if b.last.IsZero() {
 		b.last = now
 	}
@@ -312,11 +424,6 @@ func consumeToken(b *bucket, window time.Duration) bool {
 	refillRate := float64(b.capacity) / window.Seconds()
 	b.tokens = minFloat(float64(b.capacity), b.tokens+elapsed*refillRate)
 	b.last = now
-	if b.tokens >= 1 {
-		b.tokens -= 1
-		return true
-	}
-	return false
 }
 
 func minFloat(a, b float64) float64 {
@@ -339,9 +446,17 @@ func drainBody(req *http.Request) ([]byte, error) {
 	return data, nil
 }
 
+// cacheKey identifies identical requests for coalescing and the SWR cache.
+// Requests with different credentials never share a response: accounts of
+// one provider share a guard but not their data.
 func cacheKey(req *http.Request, body []byte) string {
 	hash := sha256.Sum256(body)
-	return req.Method + " " + req.URL.String() + " " + hex.EncodeToString(hash[:])
+	key := req.Method + " " + req.URL.String() + " " + hex.EncodeToString(hash[:])
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		authHash := sha256.Sum256([]byte(auth))
+		key += " " + hex.EncodeToString(authHash[:])
+	}
+	return key
 }
 
 func cloneResponse(req *http.Request, status int, header http.Header, body []byte) *http.Response {
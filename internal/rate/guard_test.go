@@ -0,0 +1,277 @@
+package rate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is advanced by hand so refills and cooldowns are deterministic.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// fakeProvider is an upstream API that answers with the configured status and
+// rate-limit headers and numbers its responses ("hit 1", "hit 2", ...).
+type fakeProvider struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	hits   int
+	status int
+	header map[string]string
+	gate   chan struct{} // when set, responses wait until it is closed
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	t.Helper()
+	p := &fakeProvider{}
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		p.hits++
+		hits, status, header, gate := p.hits, p.status, p.header, p.gate
+		p.mu.Unlock()
+		if gate != nil {
+			<-gate
+		}
+		for key, value := range header {
+			w.Header().Set(key, value)
+		}
+		if status == 0 {
+			status = http.StatusOK
+		}
+		w.WriteHeader(status)
+		fmt.Fprintf(w, "hit %d", hits)
+	}))
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *fakeProvider) respond(status int, header map[string]string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status = status
+	p.header = header
+}
+
+// hold makes responses wait until the returned func is called.
+func (p *fakeProvider) hold() (release func()) {
+	gate := make(chan struct{})
+	p.mu.Lock()
+	p.gate = gate
+	p.mu.Unlock()
+	return func() { close(gate) }
+}
+
+func (p *fakeProvider) Hits() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.hits
+}
+
+func guardedClient(decl Declaration) *http.Client {
+	return &http.Client{Transport: &roundTripper{base: http.DefaultTransport, guard: newGuard(decl)}}
+}
+
+// get returns the response body, or "refused:<reason>" for a RateLimitError.
+func get(t *testing.T, client *http.Client, url string, priority Priority) string {
+	t.Helper()
+	req, err := http.NewRequestWithContext(WithPriority(context.Background(), priority), http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := client.Do(req)
+	var limited RateLimitError
+	if errors.As(err, &limited) {
+		return "refused:" + limited.Reason
+	}
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return string(body)
+}
+
+func TestGuard(t *testing.T) {
+	std := StandardHeaders()
+
+	type call struct {
+		advance time.Duration
+		status  int
+		header  map[string]string // sent by the provider if the call reaches it
+		want    string
+	}
+	cases := []struct {
+		name  string
+		decl  Declaration
+		calls []call
+	}{
+		{
+			name: "minute bucket refills over time",
+			decl: Provider("minute").MaxRequestsPer(Minute, 3),
+			calls: []call{
+				{want: "hit 1"},
+				{want: "hit 2"},
+				{want: "hit 3"},
+				{want: "refused:budget"},
+				{advance: 19 * time.Second, want: "refused:budget"},
+				{advance: time.Second, want: "hit 4"},
+				{want: "refused:budget"},
+			},
+		},
+		{
+			name: "day window outlasts minute refills",
+			decl: Provider("day").MaxRequestsPer(Minute, 60).MaxRequestsPer(Day, 2),
+			calls: []call{
+				{want: "hit 1"},
+				{want: "hit 2"},
+				{advance: time.Minute, want: "refused:budget"},
+				{advance: time.Hour, want: "refused:budget"},
+				{advance: 11 * time.Hour, want: "hit 3"},
+			},
+		},
+		{
+			name: "minute headers replace the bucket",
+			decl: Provider("minute-headers").MaxRequestsPer(Minute, 100).ReadHeaders(std),
+			calls: []call{
+				{header: map[string]string{std.LimitMinute: "100", std.RemainingMinute: "1"}, want: "hit 1"},
+				{header: map[string]string{std.LimitMinute: "100", std.RemainingMinute: "0"}, want: "hit 2"},
+				{want: "refused:budget"},
+			},
+		},
+		{
+			name: "day headers respect the budget floor",
+			decl: Provider("day-headers").MaxRequestsPer(Day, 200).BudgetFloor(Day, 5).ReadHeaders(std),
+			calls: []call{
+				{header: map[string]string{std.LimitDay: "200", std.RemainingDay: "7"}, want: "hit 1"},
+				{header: map[string]string{std.LimitDay: "200", std.RemainingDay: "5"}, want: "hit 2"},
+				{want: "refused:budget"},
+			},
+		},
+		{
+			name: "retry-after starts a cooldown",
+			decl: Provider("retry-after").MaxRequestsPer(Minute, 100).ReadHeaders(std),
+			calls: []call{
+				{status: http.StatusTooManyRequests, header: map[string]string{std.RetryAfter: "30"}, want: "hit 1"},
+				{advance: 29 * time.Second, want: "refused:cooldown"},
+				{advance: time.Second, want: "hit 2"},
+			},
+		},
+		{
+			name: "ratelimit-reset starts a cooldown each time",
+			decl: Provider("reset").MaxRequestsPer(Minute, 100).ReadHeaders(std),
+			calls: []call{
+				{status: http.StatusTooManyRequests, header: map[string]string{std.ResetAfter: "60"}, want: "hit 1"},
+				{advance: 59 * time.Second, want: "refused:cooldown"},
+				{advance: time.Second, status: http.StatusTooManyRequests, header: map[string]string{std.ResetAfter: "10"}, want: "hit 2"},
+				{advance: 9 * time.Second, want: "refused:cooldown"},
+				{advance: time.Second, want: "hit 3"},
+			},
+		},
+		{
+			name: "no limits means disabled",
+			decl: Provider("disabled"),
+			calls: []call{
+				{want: "refused:disabled"},
+			},
+		},
+		{
+			name: "custom policy decides",
+			decl: Provider("custom").Custom(func(_ *State, now time.Time) Decision {
+				if now.Hour() >= 22 {
+					return Decision{Reason: "quiet hours", RetryAt: now.Add(9 * time.Hour)}
+				}
+				return Decision{Allowed: true}
+			}),
+			calls: []call{
+				{want: "hit 1"},
+				{advance: 10 * time.Hour, want: "refused:quiet hours"},
+				{advance: 12 * time.Hour, want: "hit 2"},
+			},
+		},
+		{
+			name: "cache serves denied calls until it expires",
+			decl: Provider("cache").MaxRequestsPer(Minute, 1).CacheFor(30 * time.Second),
+			calls: []call{
+				{want: "hit 1"},
+				{want: "hit 1"},
+				{advance: 29 * time.Second, want: "hit 1"},
+				{advance: 11 * time.Second, want: "refused:budget"},
+				{advance: 20 * time.Second, want: "hit 2"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := newFakeClock()
+			provider := newFakeProvider(t)
+			client := guardedClient(tc.decl.WithClock(clock))
+			for i, c := range tc.calls {
+				clock.Advance(c.advance)
+				provider.respond(c.status, c.header)
+				if got := get(t, client, provider.URL, Interactive); got != c.want {
+					t.Fatalf("call %d: got %q, want %q", i+1, got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestGuardConcurrentCallers(t *testing.T) {
+	clock := newFakeClock()
+	provider := newFakeProvider(t)
+	client := guardedClient(Provider("concurrent").MaxRequestsPer(Minute, 20).WithClock(clock))
+
+	var wg sync.WaitGroup
+	results := make(chan string, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Distinct URLs so callers are not coalesced.
+			results <- get(t, client, fmt.Sprintf("%s/?n=%d", provider.URL, i), Interactive)
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	allowed, refused := 0, 0
+	for result := range results {
+		if result == "refused:budget" {
+			refused++
+		} else {
+			allowed++
+		}
+	}
+	if allowed != 20 || refused != 30 || provider.Hits() != 20 {
+		t.Fatalf("allowed=%d refused=%d hits=%d, want 20/30/20", allowed, refused, provider.Hits())
+	}
+}
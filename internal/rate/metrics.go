@@ -24,6 +24,41 @@ var (
 		},
 		[]string{"provider"},
 	)
+	refusedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gohome_rate_limit_refused_total",
+			Help: "Requests refused by the rate-limit wrapper",
+		},
+		[]string{"provider", "priority", "reason"},
+	)
+	coalescedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gohome_rate_limit_coalesced_total",
+			Help: "Requests answered by an identical in-flight request instead of their own call",
+		},
+		[]string{"provider"},
+	)
+	breakerGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gohome_rate_limit_circuit_state",
+			Help: "Circuit breaker state per provider (0 closed, 1 open, 2 half-open)",
+		},
+		[]string{"provider"},
+	)
+	retryCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gohome_rate_limit_retries_total",
+			Help: "Requests retried by the rate-limit wrapper, by status code or \"error\"",
+		},
+		[]string{"provider", "reason"},
+	)
+	retriesExhaustedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gohome_rate_limit_retries_exhausted_total",
+			Help: "Requests that still failed after their last retry",
+		},
+		[]string{"provider"},
+	)
 )
 
 // MetricsCollectors exposes shared rate-limit collectors.
@@ -32,5 +67,10 @@ func MetricsCollectors() []prometheus.Collector {
 		remainingGauge,
 		retryAfterGauge,
 		lastStatusGauge,
+		refusedCounter,
+		coalescedCounter,
+		breakerGauge,
+		retryCounter,
+		retriesExhaustedCounter,
 	}
 }
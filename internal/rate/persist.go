@@ -0,0 +1,255 @@
+package rate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/joshp123/gohome/internal/oauth"
+)
+
+// registry tracks the live guard per provider so budgets can be persisted
+// and carried over when a config reload rebuilds a plugin's HTTP client.
+var registry = struct {
+	mu     sync.Mutex
+	guards map[string]*Guard
+	store  oauth.BlobStore
+}{guards: make(map[string]*Guard)}
+
+// savedState is the persisted form of a guard's State.
+type savedState struct {
+	Provider   string                 `json:"provider"`
+	SavedAt    time.Time              `json:"saved_at"`
+	Cooldown   time.Time              `json:"cooldown,omitempty"`
+	LastStatus int                    `json:"last_status,omitempty"`
+	Windows    map[string]savedWindow `json:"windows"`
+}
+
+type savedWindow struct {
+	Limit       int       `json:"limit"`
+	Remaining   int       `json:"remaining"`
+	FromHeaders bool      `json:"from_headers,omitempty"`
+	Tokens      float64   `json:"tokens"`
+	Last        time.Time `json:"last"`
+}
+
+// register makes g the live guard for its provider. A guard replacing an
+// earlier one (config reload) inherits its state; otherwise state is restored
+// from the store when persistence is enabled.
+func register(g *Guard) {
+	name := g.decl.ProviderName()
+	registry.mu.Lock()
+	prev := registry.guards[name]
+	registry.guards[name] = g
+	store := registry.store
+	registry.mu.Unlock()
+
+	now := g.clock.Now()
+	switch {
+	case prev != nil:
+		g.restore(prev.snapshot(now), now)
+	case store != nil:
+		if err := loadGuard(context.Background(), store, g, now); err != nil {
+			log.Printf("rate: restore %s: %v", name, err)
+		}
+	}
+}
+
+// unregister drops g from the registry if it is still the live guard for its
+// provider, saving its state first when persistence is enabled. A guard that
+// a reload already replaced is left alone.
+func unregister(ctx context.Context, g *Guard) error {
+	name := g.decl.ProviderName()
+	registry.mu.Lock()
+	current := registry.guards[name] == g
+	if current {
+		delete(registry.guards, name)
+	}
+	store := registry.store
+	registry.mu.Unlock()
+
+	if !current || store == nil {
+		return nil
+	}
+	return saveGuard(ctx, store, g)
+}
+
+// Release unregisters the guard behind a client built by WrapHTTP. Plugins
+// call it when they stop so a removed provider no longer shows up in
+// ListProviders, Circuit or persistence. Other clients are ignored.
+func Release(ctx context.Context, client *http.Client) error {
+	if client == nil {
+		return nil
+	}
+	rt, ok := client.Transport.(*roundTripper)
+	if !ok {
+		return nil
+	}
+	return unregister(ctx, rt.guard)
+}
+
+// registered returns the live guards sorted by provider.
+func registered() []*Guard {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	out := make([]*Guard, 0, len(registry.guards))
+	for _, g := range registry.guards {
+		out = append(out, g)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].decl.ProviderName() < out[j].decl.ProviderName() })
+	return out
+}
+
+// EnablePersistence restores every registered guard from store and makes
+// guards created later restore on creation. Providers without saved state
+// keep their fresh budgets.
+func EnablePersistence(ctx context.Context, store oauth.BlobStore) {
+	registry.mu.Lock()
+	registry.store = store
+	registry.mu.Unlock()
+
+	for _, g := range registered() {
+		if err := loadGuard(ctx, store, g, g.clock.Now()); err != nil {
+			log.Printf("rate: restore %s: %v", g.decl.ProviderName(), err)
+		}
+	}
+}
+
+// SaveAll writes the state of every registered guard to the persistence
+// store. It is a no-op when persistence is disabled.
+func SaveAll(ctx context.Context) error {
+	registry.mu.Lock()
+	store := registry.store
+	registry.mu.Unlock()
+	if store == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, g := range registered() {
+		if err := saveGuard(ctx, store, g); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func saveGuard(ctx context.Context, store oauth.BlobStore, g *Guard) error {
+	data, err := json.Marshal(g.snapshot(g.clock.Now()))
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", g.decl.ProviderName(), err)
+	}
+	if err := store.Save(ctx, stateKey(g.decl.ProviderName()), data); err != nil {
+		return fmt.Errorf("save %s: %w", g.decl.ProviderName(), err)
+	}
+	return nil
+}
+
+// RunPersistence saves all guards every interval until ctx is done.
+func RunPersistence(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := SaveAll(ctx); err != nil {
+				log.Printf("rate: save state: %v", err)
+			}
+		}
+	}
+}
+
+func loadGuard(ctx context.Context, store oauth.BlobStore, g *Guard, now time.Time) error {
+	data, err := store.Load(ctx, stateKey(g.decl.ProviderName()))
+	if errors.Is(err, oauth.ErrBlobNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var saved savedState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("decode state: %w", err)
+	}
+	g.restore(saved, now)
+	return nil
+}
+
+func stateKey(provider string) string {
+	return "rate/" + provider
+}
+
+func (g *Guard) snapshot(now time.Time) savedState {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	saved := savedState{
+		Provider:   g.decl.ProviderName(),
+		SavedAt:    now,
+		Cooldown:   g.state.cooldown,
+		LastStatus: g.state.lastStatus,
+		Windows:    make(map[string]savedWindow, len(g.state.limits)),
+	}
+	for window, limit := range g.state.limits {
+		sw := savedWindow{
+			Limit:       limit,
+			Remaining:   g.state.remaining[window],
+			FromHeaders: g.state.hasHeaders[window],
+		}
+		if b := g.state.buckets[window]; b != nil {
+			sw.Tokens = b.tokens
+			sw.Last = b.last
+		}
+		saved.Windows[window.String()] = sw
+	}
+	return saved
+}
+
+// restore applies saved state, refilling buckets for the time elapsed since
+// it was saved. Header-reported budgets are only kept while the window they
+// were observed in can still be open; afterwards the bucket takes over.
+func (g *Guard) restore(saved savedState, now time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if saved.Cooldown.After(now) {
+		g.state.cooldown = saved.Cooldown
+	}
+	if saved.LastStatus != 0 {
+		g.state.lastStatus = saved.LastStatus
+	}
+	for window := range g.state.limits {
+		sw, ok := saved.Windows[window.String()]
+		if !ok {
+			continue
+		}
+		if b := g.state.buckets[window]; b != nil && !sw.Last.IsZero() {
+			b.tokens = minFloat(float64(b.capacity), sw.Tokens)
+			b.last = sw.Last
+			if b.last.After(now) {
+				b.last = now
+			}
+			refill(b, windowDuration(window), now)
+		}
+		if sw.FromHeaders && now.Sub(saved.SavedAt) < windowDuration(window) {
+			g.state.remaining[window] = sw.Remaining
+			g.state.limits[window] = sw.Limit
+			g.state.hasHeaders[window] = true
+			remainingGauge.WithLabelValues(g.decl.ProviderName(), window.String()).Set(float64(sw.Remaining))
+		}
+	}
+}
+
+// NewFileStore stores state as <dir>/<key>.json. Rate state is rewritten on
+// every save, so no rotated versions are kept.
+func NewFileStore(dir string) *oauth.FileStore {
+	return oauth.NewFileStore(dir, 0)
+}
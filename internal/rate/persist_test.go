@@ -0,0 +1,194 @@
+package rate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshp123/gohome/internal/oauth"
+)
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(dir)
+	ctx := context.Background()
+
+	if _, err := store.Load(ctx, "rate/daikin"); !errors.Is(err, oauth.ErrBlobNotFound) {
+		t.Fatalf("Load missing = %v, want ErrBlobNotFound", err)
+	}
+	if err := store.Save(ctx, "rate/daikin", []byte(`{"a":1}`)); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	data, err := store.Load(ctx, "rate/daikin")
+	if err != nil || string(data) != `{"a":1}` {
+		t.Fatalf("Load = %q, %v", data, err)
+	}
+	info, err := os.Stat(filepath.Join(dir, "rate", "daikin.json"))
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("perm = %o, want 600", perm)
+	}
+}
+
+func TestRestoreRefillsBucketsForElapsedTime(t *testing.T) {
+	decl := Provider("refill").MaxRequestsPer(Day, 240)
+	now := time.Now()
+
+	g := newGuard(decl)
+	g.restore(savedState{
+		SavedAt: now.Add(-time.Hour),
+		Windows: map[string]savedWindow{
+			"day": {Limit: 240, Tokens: 0, Last: now.Add(-time.Hour)},
+		},
+	}, now)
+
+	// 240/day refills 10 per hour.
+	if got := g.state.buckets[Day].tokens; got < 9.9 || got > 10.1 {
+		t.Fatalf("tokens after 1h = %.2f, want ~10", got)
+	}
+}
+
+func TestRestoreHeaderBudgetAndCooldown(t *testing.T) {
+	decl := Provider("headers").MaxRequestsPer(Minute, 20).MaxRequestsPer(Day, 200)
+	now := time.Now()
+	saved := savedState{
+		SavedAt:  now.Add(-2 * time.Minute),
+		Cooldown: now.Add(time.Hour),
+		Windows: map[string]savedWindow{
+			"minute": {Limit: 20, Remaining: 1, FromHeaders: true, Tokens: 20, Last: now.Add(-2 * time.Minute)},
+			"day":    {Limit: 200, Remaining: 7, FromHeaders: true, Tokens: 200, Last: now.Add(-2 * time.Minute)},
+		},
+	}
+
+	g := newGuard(decl)
+	g.restore(saved, now)
+
+	if !g.state.cooldown.Equal(saved.Cooldown) {
+		t.Fatalf("cooldown = %v, want %v", g.state.cooldown, saved.Cooldown)
+	}
+	if !g.state.hasHeaders[Day] || g.state.remaining[Day] != 7 {
+		t.Fatalf("day budget = %d (headers %v), want 7 from headers", g.state.remaining[Day], g.state.hasHeaders[Day])
+	}
+	if g.state.hasHeaders[Minute] {
+		t.Fatalf("minute budget from headers should expire after its window")
+	}
+
+	expired := saved
+	expired.Cooldown = now.Add(-time.Second)
+	g = newGuard(decl)
+	g.restore(expired, now)
+	if !g.state.cooldown.IsZero() {
+		t.Fatalf("expired cooldown restored: %v", g.state.cooldown)
+	}
+}
+
+func TestPersistenceAcrossRestartAndReload(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+	ctx := context.Background()
+	decl := Provider("persist-test").MaxRequestsPer(Day, 200).ReadHeaders(StandardHeaders())
+
+	WrapHTTP(decl, nil)
+	g := registryGuard(t, "persist-test")
+	header := http.Header{}
+	header.Set("X-RateLimit-Remaining-day", "3")
+	header.Set("X-RateLimit-Limit-day", "200")
+	g.RecordResponse(http.StatusOK, header)
+
+	EnablePersistence(ctx, store)
+	t.Cleanup(func() {
+		registry.mu.Lock()
+		registry.store = nil
+		delete(registry.guards, "persist-test")
+		registry.mu.Unlock()
+	})
+	if err := SaveAll(ctx); err != nil {
+		t.Fatalf("SaveAll: %v", err)
+	}
+	data, err := store.Load(ctx, "rate/persist-test")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var saved savedState
+	if err := json.Unmarshal(data, &saved); err != nil || saved.Windows["day"].Remaining != 3 {
+		t.Fatalf("saved = %+v, %v", saved, err)
+	}
+
+	// A config reload rebuilds the client: the new guard inherits state.
+	WrapHTTP(decl, nil)
+	if next := registryGuard(t, "persist-test"); next == g || next.state.remaining[Day] != 3 {
+		t.Fatalf("reloaded guard remaining = %d, want 3", next.state.remaining[Day])
+	}
+
+	// A restart starts from an empty registry and restores from the store.
+	registry.mu.Lock()
+	delete(registry.guards, "persist-test")
+	registry.mu.Unlock()
+	WrapHTTP(decl, nil)
+	restored := registryGuard(t, "persist-test")
+	if restored.state.remaining[Day] != 3 {
+		t.Fatalf("restored remaining = %d, want 3", restored.state.remaining[Day])
+	}
+	for i := 0; i < 3; i++ {
+		restored.ShouldCall(time.Now(), Interactive)
+	}
+	if d := restored.ShouldCall(time.Now(), Interactive); d.Allowed {
+		t.Fatalf("restored guard allowed a call past the persisted budget")
+	}
+}
+
+func TestReleaseUnregistersCurrentGuard(t *testing.T) {
+	store := oauth.NewMemoryStore()
+	ctx := context.Background()
+	decl := Provider("release-test").MaxRequestsPer(Day, 200)
+
+	old := WrapHTTP(decl, nil)
+	current := WrapHTTP(decl, nil)
+	registry.mu.Lock()
+	registry.store = store
+	registry.mu.Unlock()
+	t.Cleanup(func() {
+		registry.mu.Lock()
+		registry.store = nil
+		delete(registry.guards, "release-test")
+		registry.mu.Unlock()
+	})
+
+	// Stopping a plugin whose client a reload already replaced is a no-op.
+	if err := Release(ctx, old); err != nil {
+		t.Fatalf("Release old: %v", err)
+	}
+	registryGuard(t, "release-test")
+
+	if err := Release(ctx, current); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	for _, g := range registered() {
+		if g.decl.ProviderName() == "release-test" {
+			t.Fatalf("released guard still registered")
+		}
+	}
+	if _, err := store.Load(ctx, "rate/release-test"); err != nil {
+		t.Fatalf("released guard state not saved: %v", err)
+	}
+	if err := Release(ctx, &http.Client{}); err != nil {
+		t.Fatalf("Release of an unguarded client: %v", err)
+	}
+}
+
+func registryGuard(t *testing.T, provider string) *Guard {
+	t.Helper()
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	g := registry.guards[provider]
+	if g == nil {
+		t.Fatalf("no guard registered for %s", provider)
+	}
+	return g
+}
@@ -0,0 +1,44 @@
+package rate
+
+import "context"
+
+// Priority classes a request by urgency. When budget runs low, lower
+// priorities are refused first so user commands still get through.
+type Priority int
+
+const (
+	// Interactive is a user-initiated call (the default for unmarked requests).
+	Interactive Priority = iota
+	// Background is periodic polling such as metrics scrapes.
+	Background
+	// Backfill is bulk historical fetching that can always wait.
+	Backfill
+)
+
+func (p Priority) String() string {
+	switch p {
+	case Interactive:
+		return "interactive"
+	case Background:
+		return "background"
+	case Backfill:
+		return "backfill"
+	default:
+		return "unknown"
+	}
+}
+
+type priorityKey struct{}
+
+// WithPriority marks requests made with ctx as priority p.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFrom returns the priority set on ctx, or Interactive.
+func PriorityFrom(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	return Interactive
+}
@@ -0,0 +1,153 @@
+package rate
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFloorForInheritsHigherClasses(t *testing.T) {
+	decl := Provider("floors").
+		MaxRequestsPer(Day, 200).
+		BudgetFloor(Day, 2).
+		PriorityFloor(Background, Day, 30)
+
+	cases := map[Priority]int{Interactive: 2, Background: 30, Backfill: 30}
+	for priority, want := range cases {
+		if got := decl.FloorFor(priority, Day); got != want {
+			t.Fatalf("FloorFor(%s) = %d, want %d", priority, got, want)
+		}
+	}
+	if got := decl.PriorityFloor(Backfill, Day, 10).FloorFor(Backfill, Day); got != 30 {
+		t.Fatalf("backfill floor below background = %d, want 30", got)
+	}
+}
+
+func TestPriorityFloorsWithHeaderBudget(t *testing.T) {
+	clock := newFakeClock()
+	g := newGuard(Provider("headers").
+		MaxRequestsPer(Day, 200).
+		BudgetFloor(Day, 2).
+		PriorityFloor(Background, Day, 10).
+		PriorityFloor(Backfill, Day, 11).
+		ReadHeaders(StandardHeaders()).
+		WithClock(clock))
+
+	header := http.Header{}
+	header.Set("X-RateLimit-Limit-day", "200")
+	header.Set("X-RateLimit-Remaining-day", "12")
+	g.RecordResponse(http.StatusOK, header)
+
+	if d := g.ShouldCall(clock.Now(), Backfill); !d.Allowed {
+		t.Fatalf("backfill refused at remaining 12")
+	}
+	if d := g.ShouldCall(clock.Now(), Backfill); d.Allowed || d.Reason != "budget" {
+		t.Fatalf("backfill allowed at remaining 11: %+v", d)
+	}
+	if d := g.ShouldCall(clock.Now(), Background); !d.Allowed {
+		t.Fatalf("background refused at remaining 11")
+	}
+	if d := g.ShouldCall(clock.Now(), Background); d.Allowed {
+		t.Fatalf("background allowed at remaining 10")
+	}
+	for remaining := 10; remaining > 2; remaining-- {
+		if d := g.ShouldCall(clock.Now(), Interactive); !d.Allowed {
+			t.Fatalf("interactive refused at remaining %d", remaining)
+		}
+	}
+	if d := g.ShouldCall(clock.Now(), Interactive); d.Allowed {
+		t.Fatalf("interactive allowed past the budget floor")
+	}
+}
+
+func TestPriorityFloorsWithBucket(t *testing.T) {
+	clock := newFakeClock()
+	g := newGuard(Provider("bucket").
+		MaxRequestsPer(Minute, 10).
+		PriorityFloor(Background, Minute, 5).
+		WithClock(clock))
+
+	for i := 0; i < 5; i++ {
+		if d := g.ShouldCall(clock.Now(), Background); !d.Allowed {
+			t.Fatalf("background call %d refused", i)
+		}
+	}
+	d := g.ShouldCall(clock.Now(), Background)
+	if d.Allowed {
+		t.Fatalf("background dipped into the interactive reserve")
+	}
+	// 5 tokens left; background needs 6, refilled at 10/min.
+	if want := clock.Now().Add(6 * time.Second); !d.RetryAt.Equal(want) {
+		t.Fatalf("RetryAt = %v, want %v", d.RetryAt, want)
+	}
+	for i := 0; i < 5; i++ {
+		if d := g.ShouldCall(clock.Now(), Interactive); !d.Allowed {
+			t.Fatalf("interactive call %d refused", i)
+		}
+	}
+	if d := g.ShouldCall(clock.Now(), Interactive); d.Allowed {
+		t.Fatalf("interactive allowed with an empty bucket")
+	}
+
+	clock.Advance(36 * time.Second)
+	if d := g.ShouldCall(clock.Now(), Background); !d.Allowed {
+		t.Fatalf("background refused after refill to 6 tokens")
+	}
+}
+
+func TestRefusalChargesNoWindow(t *testing.T) {
+	clock := newFakeClock()
+	g := newGuard(Provider("windows").
+		MaxRequestsPer(Minute, 10).
+		MaxRequestsPer(Day, 1).
+		WithClock(clock))
+
+	if d := g.ShouldCall(clock.Now(), Interactive); !d.Allowed {
+		t.Fatalf("first call refused")
+	}
+	if d := g.ShouldCall(clock.Now(), Interactive); d.Allowed {
+		t.Fatalf("second call allowed past the daily limit")
+	}
+	if got := g.state.buckets[Minute].tokens; got != 9 {
+		t.Fatalf("minute tokens = %v, want 9 (refusal must not charge)", got)
+	}
+}
+
+func TestRoundTripperReportsRefusedPriority(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit-day", "200")
+		w.Header().Set("X-RateLimit-Remaining-day", "5")
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &roundTripper{
+		base: http.DefaultTransport,
+		guard: newGuard(Provider("roundtrip").
+			MaxRequestsPer(Day, 200).
+			PriorityFloor(Background, Day, 5).
+			ReadHeaders(StandardHeaders())),
+	}}
+	get := func(priority Priority) error {
+		req, _ := http.NewRequestWithContext(WithPriority(context.Background(), priority), http.MethodGet, srv.URL, nil)
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	if err := get(Background); err != nil {
+		t.Fatalf("first background call: %v", err)
+	}
+	err := get(Background)
+	var limited RateLimitError
+	if !errors.As(err, &limited) || limited.Priority != Background || limited.Reason != "budget" {
+		t.Fatalf("expected background budget refusal, got %v", err)
+	}
+	if err := get(Interactive); err != nil {
+		t.Fatalf("interactive call refused: %v", err)
+	}
+}
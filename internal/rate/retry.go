@@ -0,0 +1,196 @@
+package rate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy declares how the guard retries failed calls. Every attempt is
+// charged against the budget like any other call, so a retry may itself be
+// refused with a RateLimitError.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry; it doubles for each
+	// retry after that, up to MaxDelay, with jitter.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Statuses are the response codes worth retrying. Nil means 429, 502,
+	// 503 and 504.
+	Statuses []int
+	// RetryOn reports whether a transport error is worth retrying. Nil means
+	// every error except cancellation, rate-limit refusals and open circuits.
+	RetryOn func(error) bool
+	// RetryOnResponse reports whether a response whose status is not in
+	// Statuses is still worth retrying, for providers that signal throttling
+	// in the body. body is the full response body; the caller still gets it
+	// when the response is returned.
+	RetryOnResponse func(resp *http.Response, body []byte) bool
+	// NonIdempotent allows retrying POST and PATCH requests. Without it they
+	// are only retried when they carry an Idempotency-Key header.
+	NonIdempotent bool
+}
+
+// DefaultRetry makes three attempts with 1s/2s backoff (capped at 30s) on
+// transient errors and 429/502/503/504 responses.
+func DefaultRetry() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: 30 * time.Second}
+}
+
+var defaultRetryStatuses = []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+func (p RetryPolicy) enabled() bool {
+	return p.MaxAttempts > 1
+}
+
+func (p RetryPolicy) retryableStatus(status int) bool {
+	statuses := p.Statuses
+	if statuses == nil {
+		statuses = defaultRetryStatuses
+	}
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (p RetryPolicy) retryableResponse(resp *http.Response, body []byte) bool {
+	return p.retryableStatus(resp.StatusCode) || (p.RetryOnResponse != nil && p.RetryOnResponse(resp, body))
+}
+
+func (p RetryPolicy) retryableError(err error) bool {
+	var limited RateLimitError
+	var open CircuitOpenError
+	if errors.As(err, &limited) || errors.As(err, &open) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if p.RetryOn != nil {
+		return p.RetryOn(err)
+	}
+	return true
+}
+
+func (p RetryPolicy) allows(req *http.Request) bool {
+	if p.NonIdempotent {
+		return true
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	// Same convention as net/http's transport.
+	_, key := req.Header["Idempotency-Key"]
+	_, xKey := req.Header["X-Idempotency-Key"]
+	return key || xKey
+}
+
+// backoff returns the delay before retry n (1-based): BaseDelay doubled per
+// retry and capped at MaxDelay, then jittered into [d/2, d].
+func (p RetryPolicy) backoff(n int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < n && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// retryDelay decides whether attempt n (1-based) should be retried and how
+// long to wait first. The wait honours Retry-After and the guard's cooldown;
+// a wait longer than MaxDelay or past the request deadline is not retried.
+// body is the buffered response body, or nil without a RetryOnResponse.
+func (g *Guard) retryDelay(req *http.Request, n int, resp *http.Response, body []byte, err error) (time.Duration, string, bool) {
+	policy := g.decl.RetryPolicy()
+	if !policy.enabled() || n >= policy.MaxAttempts || !policy.allows(req) {
+		return 0, "", false
+	}
+	reason := "error"
+	if err != nil {
+		if !policy.retryableError(err) {
+			return 0, "", false
+		}
+	} else {
+		switch {
+		case policy.retryableStatus(resp.StatusCode):
+			reason = strconv.Itoa(resp.StatusCode)
+		case policy.RetryOnResponse != nil && policy.RetryOnResponse(resp, body):
+			reason = "response"
+		default:
+			return 0, "", false
+		}
+	}
+
+	now := g.clock.Now()
+	delay := policy.backoff(n)
+	if resp != nil {
+		delay = max(delay, retryAfter(resp.Header, now))
+	}
+	g.mu.Lock()
+	cooldown := g.state.cooldown
+	g.mu.Unlock()
+	if wait := cooldown.Sub(now); wait > delay {
+		delay = wait
+	}
+	if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+		return 0, "", false
+	}
+	if deadline, ok := req.Context().Deadline(); ok && time.Until(deadline) < delay {
+		return 0, "", false
+	}
+	return delay, reason, true
+}
+
+// bufferBody reads resp.Body into memory and replaces it with a reader over
+// the same bytes.
+func bufferBody(resp *http.Response) ([]byte, error) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,179 @@
+package rate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedProvider answers with statuses in order, repeating the last one.
+func scriptedProvider(t *testing.T, statuses []int, header map[string]string) (*httptest.Server, func() int) {
+	t.Helper()
+	var mu sync.Mutex
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		status := statuses[min(hits, len(statuses)-1)]
+		hits++
+		mu.Unlock()
+		for key, value := range header {
+			w.Header().Set(key, value)
+		}
+		w.WriteHeader(status)
+		fmt.Fprintf(w, "status %d", status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return hits
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	fast := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	cases := []struct {
+		name       string
+		policy     RetryPolicy
+		method     string
+		header     http.Header
+		statuses   []int
+		respHeader map[string]string
+		wantStatus int
+		wantHits   int
+		minSlept   time.Duration
+	}{
+		{name: "retries a 503", policy: fast, method: http.MethodGet, statuses: []int{503, 200}, wantStatus: 200, wantHits: 2},
+		{name: "gives up after max attempts", policy: fast, method: http.MethodGet, statuses: []int{502}, wantStatus: 502, wantHits: 3},
+		{name: "does not retry a 500 by default", policy: fast, method: http.MethodGet, statuses: []int{500, 200}, wantStatus: 500, wantHits: 1},
+		{name: "custom statuses", policy: RetryPolicy{MaxAttempts: 2, Statuses: []int{500}}, method: http.MethodGet, statuses: []int{500, 200}, wantStatus: 200, wantHits: 2},
+		{name: "never retries POST by default", policy: fast, method: http.MethodPost, statuses: []int{503, 200}, wantStatus: 503, wantHits: 1},
+		{name: "retries POST when opted in", policy: RetryPolicy{MaxAttempts: 2, NonIdempotent: true}, method: http.MethodPost, statuses: []int{503, 200}, wantStatus: 200, wantHits: 2},
+		{name: "retries POST with an idempotency key", policy: fast, method: http.MethodPost, header: http.Header{"Idempotency-Key": {"abc"}}, statuses: []int{503, 200}, wantStatus: 200, wantHits: 2},
+		{name: "retries PUT", policy: fast, method: http.MethodPut, statuses: []int{503, 200}, wantStatus: 200, wantHits: 2},
+		{name: "honours Retry-After", policy: fast, method: http.MethodGet, statuses: []int{429, 200}, respHeader: map[string]string{"Retry-After": "7"}, wantStatus: 200, wantHits: 2, minSlept: 7 * time.Second},
+		{name: "Retry-After beyond MaxDelay is not retried", policy: fast, method: http.MethodGet, statuses: []int{429, 200}, respHeader: map[string]string{"Retry-After": "60"}, wantStatus: 429, wantHits: 1},
+		{name: "disabled without a policy", method: http.MethodGet, statuses: []int{503, 200}, wantStatus: 503, wantHits: 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := newFakeClock()
+			srv, hits := scriptedProvider(t, tc.statuses, tc.respHeader)
+			client := guardedClient(Provider("retry").MaxRequestsPer(Minute, 100).ReadHeaders(StandardHeaders()).Retry(tc.policy).WithClock(clock))
+			g := client.Transport.(*roundTripper).guard
+			var slept time.Duration
+			g.sleep = func(_ context.Context, d time.Duration) error {
+				slept += d
+				clock.Advance(d)
+				return nil
+			}
+
+			req, _ := http.NewRequest(tc.method, srv.URL, strings.NewReader(`{"on":true}`))
+			for key, values := range tc.header {
+				req.Header[key] = values
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("do: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode != tc.wantStatus || string(body) != fmt.Sprintf("status %d", tc.wantStatus) {
+				t.Fatalf("got %d %q, want %d", resp.StatusCode, body, tc.wantStatus)
+			}
+			if hits() != tc.wantHits {
+				t.Fatalf("hits = %d, want %d", hits(), tc.wantHits)
+			}
+			if slept < tc.minSlept {
+				t.Fatalf("slept %v, want at least %v", slept, tc.minSlept)
+			}
+		})
+	}
+}
+
+func TestRetryTransportErrors(t *testing.T) {
+	failures := 2
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if failures > 0 {
+			failures--
+			return nil, errors.New("connection reset by peer")
+		}
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("ok")), Request: req}, nil
+	})
+	g := newGuard(Provider("retry-errors").MaxRequestsPer(Minute, 100).Retry(RetryPolicy{MaxAttempts: 3}).WithClock(newFakeClock()))
+	g.sleep = func(context.Context, time.Duration) error { return nil }
+	client := &http.Client{Transport: &roundTripper{base: base, guard: g}}
+
+	resp, err := client.Get("http://provider.invalid/")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	if failures != 0 {
+		t.Fatalf("expected both failures to be retried")
+	}
+	// Three attempts were charged.
+	if st := g.Status(g.clock.Now()); st.Windows[0].Remaining != 97 {
+		t.Fatalf("remaining = %d, want 97", st.Windows[0].Remaining)
+	}
+}
+
+func TestRetryOnResponse(t *testing.T) {
+	bodies := []string{`{"error_code":10012}`, `{"error_code":10012}`, `{"error_code":0}`}
+	hits := 0
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body := bodies[min(hits, len(bodies)-1)]
+		hits++
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+	})
+	throttled := func(_ *http.Response, body []byte) bool { return strings.Contains(string(body), "10012") }
+	g := newGuard(Provider("retry-body").Unmetered().Retry(RetryPolicy{MaxAttempts: 3, RetryOnResponse: throttled, NonIdempotent: true}).WithClock(newFakeClock()))
+	g.sleep = func(context.Context, time.Duration) error { return nil }
+	client := &http.Client{Transport: &roundTripper{base: base, guard: g}}
+
+	resp, err := client.Post("http://provider.invalid/", "application/json", nil)
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if hits != 3 || string(body) != `{"error_code":0}` {
+		t.Fatalf("hits = %d, body %q; want the third response", hits, body)
+	}
+
+	// Exhausted: the last throttled response is returned with its body intact.
+	hits = 0
+	bodies = []string{`{"error_code":10012}`}
+	resp, err = client.Post("http://provider.invalid/", "application/json", nil)
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if hits != 3 || string(body) != `{"error_code":10012}` {
+		t.Fatalf("hits = %d, body %q; want three attempts and the throttled body", hits, body)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	for n, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		for i := 0; i < 20; i++ {
+			if got := p.backoff(n); got < want/2 || got > want {
+				t.Fatalf("backoff(%d) = %v, want in [%v, %v]", n, got, want/2, want)
+			}
+		}
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
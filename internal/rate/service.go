@@ -0,0 +1,91 @@
+package rate
+
+import (
+	"context"
+	"time"
+
+	ratev1 "github.com/joshp123/gohome/proto/gen/rate/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type service struct {
+	ratev1.UnimplementedRateServiceServer
+}
+
+// RegisterRateService registers the rate guard introspection RPCs.
+func RegisterRateService(server *grpc.Server) {
+	ratev1.RegisterRateServiceServer(server, &service{})
+}
+
+func (s *service) ListProviders(ctx context.Context, req *ratev1.ListProvidersRequest) (*ratev1.ListProvidersResponse, error) {
+	statuses := Statuses()
+	resp := &ratev1.ListProvidersResponse{Providers: make([]*ratev1.ProviderStatus, 0, len(statuses))}
+	for _, st := range statuses {
+		resp.Providers = append(resp.Providers, providerProto(st))
+	}
+	return resp, nil
+}
+
+func (s *service) GetProvider(ctx context.Context, req *ratev1.GetProviderRequest) (*ratev1.GetProviderResponse, error) {
+	if req.GetProvider() == "" {
+		return nil, status.Error(codes.InvalidArgument, "provider is required")
+	}
+	st, ok := ProviderStatus(req.GetProvider())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no rate guard for provider %q", req.GetProvider())
+	}
+	return &ratev1.GetProviderResponse{Provider: providerProto(st)}, nil
+}
+
+func providerProto(st Status) *ratev1.ProviderStatus {
+	out := &ratev1.ProviderStatus{
+		Provider:      st.Provider,
+		Policy:        st.Policy,
+		LastStatus:    int32(st.LastStatus),
+		CacheEntries:  uint32(st.CacheEntries),
+		CacheHits:     st.CacheHits,
+		CacheMisses:   st.CacheMisses,
+		CacheHitRatio: st.CacheHitRatio(),
+		CooldownUntil: optionalTimestamp(st.Cooldown),
+		Circuit: &ratev1.Circuit{
+			State:               st.Circuit.State.String(),
+			ConsecutiveFailures: int32(st.Circuit.Failures),
+			LastError:           st.Circuit.LastError,
+		},
+	}
+	if st.Circuit.State != BreakerClosed {
+		out.Circuit.RetryAt = optionalTimestamp(st.Circuit.RetryAt)
+	}
+	for _, w := range st.Windows {
+		source := "bucket"
+		if w.FromHeaders {
+			source = "headers"
+		}
+		out.Windows = append(out.Windows, &ratev1.WindowStatus{
+			Window:    w.Window.String(),
+			Limit:     int32(w.Limit),
+			Remaining: int32(w.Remaining),
+			Source:    source,
+		})
+	}
+	if d := st.LastDenial; d != nil {
+		out.LastDenial = &ratev1.Denial{
+			Time:            timestamppb.New(d.Time),
+			Reason:          d.Reason,
+			Priority:        d.Priority.String(),
+			RetryAt:         optionalTimestamp(d.RetryAt),
+			ServedFromCache: d.ServedFromCache,
+		}
+	}
+	return out
+}
+
+func optionalTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
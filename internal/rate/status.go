@@ -0,0 +1,147 @@
+package rate
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// stats records what the guard refused, for introspection.
+type stats struct {
+	lastDenial  Denial
+	cacheHits   uint64
+	cacheMisses uint64
+}
+
+// Denial describes a refused call.
+type Denial struct {
+	Time            time.Time
+	Reason          string
+	Priority        Priority
+	RetryAt         time.Time
+	ServedFromCache bool
+}
+
+// WindowStatus is the budget for one window.
+type WindowStatus struct {
+	Window    Window
+	Limit     int
+	Remaining int
+	// FromHeaders is true when the provider reported the budget; otherwise
+	// it is the local token bucket's estimate.
+	FromHeaders bool
+}
+
+// Status is a point-in-time view of a guard.
+type Status struct {
+	Provider     string
+	Policy       string // limits, custom, unmetered or disabled
+	Windows      []WindowStatus
+	Cooldown     time.Time // zero when not cooling down
+	LastStatus   int
+	CacheEntries int
+	CacheHits    uint64
+	CacheMisses  uint64
+	LastDenial   *Denial
+	Circuit      CircuitStatus
+}
+
+// CacheHitRatio is the share of cache lookups answered from cache. The cache
+// is consulted for denied calls and, with StaleWhileRevalidate, for every read.
+func (s Status) CacheHitRatio() float64 {
+	total := s.CacheHits + s.CacheMisses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.CacheHits) / float64(total)
+}
+
+// Statuses reports every live guard, sorted by provider.
+func Statuses() []Status {
+	guards := registered()
+	out := make([]Status, 0, len(guards))
+	for _, g := range guards {
+		out = append(out, g.Status(g.clock.Now()))
+	}
+	return out
+}
+
+// ProviderStatus reports the live guard for provider.
+func ProviderStatus(provider string) (Status, bool) {
+	registry.mu.Lock()
+	g := registry.guards[provider]
+	registry.mu.Unlock()
+	if g == nil {
+		return Status{}, false
+	}
+	return g.Status(g.clock.Now()), true
+}
+
+// Status reports the guard's budgets as of now without charging anything.
+func (g *Guard) Status(now time.Time) Status {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	status := Status{
+		Provider:    g.decl.ProviderName(),
+		Policy:      "limits",
+		LastStatus:  g.state.lastStatus,
+		CacheHits:   g.stats.cacheHits,
+		CacheMisses: g.stats.cacheMisses,
+	}
+	switch {
+	case g.decl.CustomPolicy() != nil:
+		status.Policy = "custom"
+	case !g.decl.HasLimits() && g.decl.unmetered:
+		status.Policy = "unmetered"
+	case !g.decl.HasLimits():
+		status.Policy = "disabled"
+	}
+	if now.Before(g.state.cooldown) {
+		status.Cooldown = g.state.cooldown
+	}
+	for window, limit := range g.state.limits {
+		ws := WindowStatus{Window: window, Limit: limit, FromHeaders: g.state.hasHeaders[window]}
+		if ws.FromHeaders {
+			ws.Remaining = g.state.remaining[window]
+		} else if b := g.state.buckets[window]; b != nil {
+			// Refill a copy so looking does not move the bucket's clock.
+			peek := *b
+			refill(&peek, windowDuration(window), now)
+			ws.Remaining = int(math.Floor(peek.tokens))
+		}
+		status.Windows = append(status.Windows, ws)
+	}
+	sort.Slice(status.Windows, func(i, j int) bool { return status.Windows[i].Window < status.Windows[j].Window })
+	for _, entry := range g.cache {
+		if !now.After(entry.expires) {
+			status.CacheEntries++
+		}
+	}
+	status.Circuit = g.breaker.status()
+	if !g.stats.lastDenial.Time.IsZero() {
+		denial := g.stats.lastDenial
+		status.LastDenial = &denial
+	}
+	return status
+}
+
+func (g *Guard) recordDenial(now time.Time, priority Priority, decision Decision, servedFromCache bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stats.lastDenial = Denial{
+		Time:            now,
+		Reason:          decision.Reason,
+		Priority:        priority,
+		RetryAt:         decision.RetryAt,
+		ServedFromCache: servedFromCache,
+	}
+	if g.decl.CacheTTL() <= 0 {
+		return
+	}
+	if servedFromCache {
+		g.stats.cacheHits++
+	} else {
+		g.stats.cacheMisses++
+	}
+}
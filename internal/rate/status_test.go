@@ -0,0 +1,97 @@
+package rate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ratev1 "github.com/joshp123/gohome/proto/gen/rate/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGuardStatus(t *testing.T) {
+	clock := newFakeClock()
+	provider := newFakeProvider(t)
+	std := StandardHeaders()
+	decl := Provider("status").
+		MaxRequestsPer(Minute, 2).
+		MaxRequestsPer(Day, 100).
+		PriorityFloor(Background, Day, 40).
+		ReadHeaders(std).
+		CacheFor(time.Minute).
+		WithClock(clock)
+	client := guardedClient(decl)
+	g := client.Transport.(*roundTripper).guard
+
+	provider.respond(200, map[string]string{std.LimitDay: "100", std.RemainingDay: "40"})
+	get(t, client, provider.URL, Interactive)
+	get(t, client, provider.URL, Background)          // refused, served from cache
+	get(t, client, provider.URL+"/other", Background) // refused, nothing cached
+	clock.Advance(10 * time.Second)
+
+	st := g.Status(clock.Now())
+	if st.Policy != "limits" || st.LastStatus != 200 || st.CacheEntries != 1 {
+		t.Fatalf("status = %+v", st)
+	}
+	if len(st.Windows) != 2 {
+		t.Fatalf("windows = %+v", st.Windows)
+	}
+	// One token spent, a third of a token refilled.
+	if w := st.Windows[0]; w.Window != Minute || w.FromHeaders || w.Remaining != 1 {
+		t.Fatalf("minute window = %+v", w)
+	}
+	if w := st.Windows[1]; w.Window != Day || !w.FromHeaders || w.Remaining != 40 || w.Limit != 100 {
+		t.Fatalf("day window = %+v", w)
+	}
+	if st.CacheHits != 1 || st.CacheMisses != 1 || st.CacheHitRatio() != 0.5 {
+		t.Fatalf("cache hits=%d misses=%d", st.CacheHits, st.CacheMisses)
+	}
+	if d := st.LastDenial; d == nil || d.Reason != "budget" || d.Priority != Background || d.ServedFromCache {
+		t.Fatalf("last denial = %+v", st.LastDenial)
+	}
+	if again := g.Status(clock.Now()); again.Windows[0].Remaining != 1 {
+		t.Fatalf("Status changed the bucket: %+v", again.Windows[0])
+	}
+}
+
+func TestRateService(t *testing.T) {
+	WrapHTTP(Provider("service-test").MaxRequestsPer(Day, 50), nil)
+	t.Cleanup(func() {
+		registry.mu.Lock()
+		delete(registry.guards, "service-test")
+		registry.mu.Unlock()
+	})
+	svc := &service{}
+	ctx := context.Background()
+
+	list, err := svc.ListProviders(ctx, &ratev1.ListProvidersRequest{})
+	if err != nil {
+		t.Fatalf("ListProviders: %v", err)
+	}
+	found := false
+	for _, p := range list.Providers {
+		if p.Provider == "service-test" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("ListProviders missing service-test: %v", list.Providers)
+	}
+
+	got, err := svc.GetProvider(ctx, &ratev1.GetProviderRequest{Provider: "service-test"})
+	if err != nil {
+		t.Fatalf("GetProvider: %v", err)
+	}
+	w := got.Provider.Windows
+	if len(w) != 1 || w[0].Window != "day" || w[0].Remaining != 50 || w[0].Source != "bucket" {
+		t.Fatalf("windows = %v", w)
+	}
+	if got.Provider.LastDenial != nil || got.Provider.CooldownUntil != nil {
+		t.Fatalf("unexpected denial/cooldown: %v", got.Provider)
+	}
+
+	if _, err := svc.GetProvider(ctx, &ratev1.GetProviderRequest{Provider: "nope"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("GetProvider unknown = %v, want NotFound", err)
+	}
+}
@@ -48,9 +48,15 @@ type Declaration struct {
 	provider    string
 	limits      map[Window]int
 	budgetFloor map[Window]int
+	classFloor  map[Priority]map[Window]int
 	cacheTTL    time.Duration
+	swr         bool
+	retry       RetryPolicy
+	breaker     BreakerPolicy
+	unmetered   bool
 	headers     Headers
 	custom      CustomPolicy
+	clock       Clock
 }
 
 // Provider creates a new declaration for a provider.
@@ -78,11 +84,55 @@ func (d Declaration) BudgetFloor(window Window, floor int) Declaration {
 	return d
 }
 
+// PriorityFloor reserves budget from requests of priority p: they are refused
+// once the remaining budget in window reaches floor. Interactive requests use
+// BudgetFloor, and each lower class is held to at least the floor of the
+// classes above it.
+func (d Declaration) PriorityFloor(p Priority, window Window, floor int) Declaration {
+	if d.classFloor == nil {
+		d.classFloor = make(map[Priority]map[Window]int)
+	}
+	if d.classFloor[p] == nil {
+		d.classFloor[p] = make(map[Window]int)
+	}
+	d.classFloor[p][window] = floor
+	return d
+}
+
 func (d Declaration) CacheFor(ttl time.Duration) Declaration {
 	d.cacheTTL = ttl
 	return d
 }
 
+// StaleWhileRevalidate serves fresh cache hits (see CacheFor) for GET
+// requests without spending budget. Once an entry is past half its TTL it is
+// still served, and a background request refreshes it. Successful writes
+// clear the cache.
+func (d Declaration) StaleWhileRevalidate() Declaration {
+	d.swr = true
+	return d
+}
+
+// Retry makes the guard retry failed calls according to policy.
+func (d Declaration) Retry(policy RetryPolicy) Declaration {
+	d.retry = policy
+	return d
+}
+
+// Breaker adds a circuit breaker to the provider's calls.
+func (d Declaration) Breaker(policy BreakerPolicy) Declaration {
+	d.breaker = policy
+	return d
+}
+
+// Unmetered lets calls through when no limits are declared, for providers
+// that only want the breaker, retries or cache. Without it a declaration
+// with no limits refuses every call.
+func (d Declaration) Unmetered() Declaration {
+	d.unmetered = true
+	return d
+}
+
 func (d Declaration) ReadHeaders(headers Headers) Declaration {
 	d.headers = headers
 	return d
@@ -93,6 +143,13 @@ func (d Declaration) Custom(policy CustomPolicy) Declaration {
 	return d
 }
 
+// WithClock makes the guard read time from clock instead of the system
+// clock, so tests can drive refills and cooldowns deterministically.
+func (d Declaration) WithClock(clock Clock) Declaration {
+	d.clock = clock
+	return d
+}
+
 func (d Declaration) Limits() map[Window]int {
 	return d.limits
 }
@@ -101,10 +158,33 @@ func (d Declaration) BudgetFloors() map[Window]int {
 	return d.budgetFloor
 }
 
+// FloorFor returns the budget a request of priority p must leave in window.
+func (d Declaration) FloorFor(p Priority, window Window) int {
+	floor := d.budgetFloor[window]
+	for class := Interactive; class <= p; class++ {
+		if f, ok := d.classFloor[class][window]; ok && f > floor {
+			floor = f
+		}
+	}
+	return floor
+}
+
 func (d Declaration) CacheTTL() time.Duration {
 	return d.cacheTTL
 }
 
+func (d Declaration) StaleWhileRevalidates() bool {
+	return d.swr && d.cacheTTL > 0
+}
+
+func (d Declaration) RetryPolicy() RetryPolicy {
+	return d.retry
+}
+
+func (d Declaration) BreakerPolicy() BreakerPolicy {
+	return d.breaker
+}
+
 func (d Declaration) Headers() Headers {
 	return d.headers
 }
@@ -117,6 +197,15 @@ func (d Declaration) HasLimits() bool {
 	return len(d.limits) > 0
 }
 
+// Clock supplies the current time to a Guard.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
 // RateLimited is the compile-time contract for plugins that declare limits.
 type RateLimited interface {
 	RateLimits() Declaration
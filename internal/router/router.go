@@ -8,10 +8,13 @@ import (
 )
 
 // RegisterPlugins registers plugin services and core services on the gRPC server.
-func RegisterPlugins(server *grpc.Server, plugins []core.Plugin) {
-	registryv1.RegisterRegistryServer(server, core.NewRegistryService(plugins))
+// The returned registry can be updated when a config reload changes the plugin set.
+func RegisterPlugins(server *grpc.Server, plugins []core.Plugin, health *core.HealthBroadcaster) *core.RegistryService {
+	registry := core.NewRegistryService(plugins, health)
+	registryv1.RegisterRegistryServer(server, registry)
 
 	for _, p := range plugins {
 		p.RegisterGRPC(server)
 	}
+	return registry
 }
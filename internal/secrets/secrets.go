@@ -0,0 +1,18 @@
+// Package secrets defines how bootstrap secrets are persisted into a
+// nix secrets repo. Backends live in their own packages (agenix, sops).
+package secrets
+
+import "context"
+
+// SecretWriter encrypts plaintext into a secrets repo and returns the path
+// of the file it wrote.
+type SecretWriter interface {
+	Write(ctx context.Context, plaintext []byte) (string, error)
+}
+
+// Backend names accepted by `gohome oauth --persist`.
+const (
+	BackendAgenix = "agenix"
+	BackendSops   = "sops"
+	BackendNone   = "none"
+)
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/joshp123/gohome/internal/audit"
+	"github.com/joshp123/gohome/internal/auth"
+)
+
+// auditUnary records every write RPC, including calls rejected by
+// authorization, to the audit log.
+func auditUnary(auditLog *audit.Log) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if auditLog == nil || MethodAccess(info.FullMethod) != AccessWrite {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		entry := audit.Entry{
+			Time:      start.UTC(),
+			Method:    strings.TrimPrefix(info.FullMethod, "/"),
+			Code:      status.Code(err).String(),
+			LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		}
+		if id, ok := auth.FromContext(ctx); ok {
+			entry.Caller = id.Name
+		}
+		if msg, ok := req.(proto.Message); ok {
+			if data, marshalErr := protojson.Marshal(msg); marshalErr == nil {
+				entry.Request = json.RawMessage(data)
+			}
+		}
+		if err != nil {
+			entry.Error = status.Convert(err).Message()
+		}
+		if recordErr := auditLog.Record(entry); recordErr != nil {
+			log.Printf("audit %s: %v", entry.Method, recordErr)
+		}
+		return resp, err
+	}
+}
@@ -0,0 +1,266 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/joshp123/gohome/internal/auth"
+	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
+)
+
+// ScopeRead grants every method marked NO_SIDE_EFFECTS.
+const ScopeRead = "read"
+
+// Access classifies an RPC as read-only or mutating.
+type Access string
+
+const (
+	AccessRead  Access = "read"
+	AccessWrite Access = "write"
+)
+
+// openServices are callable by any authenticated token.
+var openServices = []string{
+	"grpc.health.v1.Health",
+	"grpc.reflection.v1.ServerReflection",
+	"grpc.reflection.v1alpha.ServerReflection",
+}
+
+var authzDenied = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "gohome_authz_denied_total",
+		Help: "RPCs rejected by per-token authorization",
+	},
+	[]string{"token", "method"},
+)
+
+// MetricsCollectors exposes server collectors.
+func MetricsCollectors() []prometheus.Collector {
+	return []prometheus.Collector{authzDenied}
+}
+
+// MethodAccess reports whether fullMethod ("/pkg.Service/Method") is a read.
+// Reads are methods declaring idempotency_level = NO_SIDE_EFFECTS; anything
+// else, including unknown methods, is a write.
+func MethodAccess(fullMethod string) Access {
+	name := strings.Replace(strings.TrimPrefix(fullMethod, "/"), "/", ".", 1)
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return AccessWrite
+	}
+	method, ok := desc.(protoreflect.MethodDescriptor)
+	if !ok {
+		return AccessWrite
+	}
+	opts, ok := method.Options().(*descriptorpb.MethodOptions)
+	if ok && opts.GetIdempotencyLevel() == descriptorpb.MethodOptions_NO_SIDE_EFFECTS {
+		return AccessRead
+	}
+	return AccessWrite
+}
+
+type denyRule struct {
+	pattern    string
+	start, end int // minutes since midnight; start == end means always
+}
+
+func (r denyRule) active(now time.Time) bool {
+	if r.start == r.end {
+		return true
+	}
+	minute := now.Hour()*60 + now.Minute()
+	if r.start < r.end {
+		return minute >= r.start && minute < r.end
+	}
+	return minute >= r.start || minute < r.end
+}
+
+type tokenPolicy struct {
+	scopes []string
+	deny   []denyRule
+}
+
+// Authorizer enforces the scopes and deny rules of named tokens. Callers
+// without scopes, including the unnamed default token, may call everything.
+type Authorizer struct {
+	now func() time.Time
+
+	mu       sync.RWMutex
+	policies map[string]tokenPolicy
+}
+
+// NewAuthorizer builds an authorizer from the auth config.
+func NewAuthorizer(cfg *configv1.AuthConfig) (*Authorizer, error) {
+	a := &Authorizer{now: time.Now}
+	if err := a.Reload(cfg); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Reload replaces the token policies. On error the previous ones stay active.
+func (a *Authorizer) Reload(cfg *configv1.AuthConfig) error {
+	policies := make(map[string]tokenPolicy)
+	for _, token := range cfg.GetTokens() {
+		policy := tokenPolicy{scopes: token.GetScopes()}
+		for _, scope := range policy.scopes {
+			if err := checkPattern(scope); err != nil {
+				return fmt.Errorf("auth token %s: %w", token.GetName(), err)
+			}
+		}
+		for _, rule := range token.GetDeny() {
+			parsed, err := parseDenyRule(rule)
+			if err != nil {
+				return fmt.Errorf("auth token %s: %w", token.GetName(), err)
+			}
+			policy.deny = append(policy.deny, parsed)
+		}
+		policies[token.GetName()] = policy
+	}
+
+	a.mu.Lock()
+	a.policies = policies
+	a.mu.Unlock()
+	return nil
+}
+
+// Authorize checks whether the caller in ctx may invoke fullMethod.
+func (a *Authorizer) Authorize(ctx context.Context, fullMethod string) error {
+	if a == nil {
+		return nil
+	}
+	id, ok := auth.FromContext(ctx)
+	if !ok {
+		// Authentication is disabled.
+		return nil
+	}
+	method := strings.TrimPrefix(fullMethod, "/")
+	service, _, _ := strings.Cut(method, "/")
+	for _, open := range openServices {
+		if service == open {
+			return nil
+		}
+	}
+
+	a.mu.RLock()
+	policy, ok := a.policies[id.Name]
+	a.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	if !policy.allows(method) {
+		authzDenied.WithLabelValues(id.Name, method).Inc()
+		return status.Errorf(codes.PermissionDenied, "token %q is not allowed to call %s", id.Name, method)
+	}
+	now := a.now()
+	for _, rule := range policy.deny {
+		if rule.active(now) && matchMethod(rule.pattern, method) {
+			authzDenied.WithLabelValues(id.Name, method).Inc()
+			return status.Errorf(codes.PermissionDenied, "token %q may not call %s at this time", id.Name, method)
+		}
+	}
+	return nil
+}
+
+func (p tokenPolicy) allows(method string) bool {
+	if len(p.scopes) == 0 {
+		return true
+	}
+	for _, scope := range p.scopes {
+		if scope == ScopeRead {
+			if MethodAccess("/"+method) == AccessRead {
+				return true
+			}
+			continue
+		}
+		if matchMethod(scope, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnaryInterceptor rejects unary calls outside the caller's scopes.
+func (a *Authorizer) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := a.Authorize(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// StreamInterceptor rejects streaming calls outside the caller's scopes.
+func (a *Authorizer) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.Authorize(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+func matchMethod(pattern, method string) bool {
+	if pattern == "*" {
+		return true
+	}
+	ok, err := path.Match(pattern, method)
+	return err == nil && ok
+}
+
+func checkPattern(pattern string) error {
+	if pattern == ScopeRead || pattern == "*" {
+		return nil
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid method pattern %q: %w", pattern, err)
+	}
+	if !strings.Contains(pattern, "/") {
+		return fmt.Errorf("method pattern %q must be Service/Method", pattern)
+	}
+	return nil
+}
+
+func parseDenyRule(rule *configv1.DenyRule) (denyRule, error) {
+	if err := checkPattern(rule.GetMethod()); err != nil {
+		return denyRule{}, err
+	}
+	parsed := denyRule{pattern: rule.GetMethod()}
+	if rule.GetBetween() == "" {
+		return parsed, nil
+	}
+	from, to, ok := strings.Cut(rule.GetBetween(), "-")
+	if !ok {
+		return denyRule{}, fmt.Errorf("deny window %q must be HH:MM-HH:MM", rule.GetBetween())
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return denyRule{}, err
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return denyRule{}, err
+	}
+	if start == end {
+		return denyRule{}, fmt.Errorf("deny window %q is empty", rule.GetBetween())
+	}
+	parsed.start, parsed.end = start, end
+	return parsed, nil
+}
+
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: want HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
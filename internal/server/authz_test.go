@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/joshp123/gohome/internal/auth"
+	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
+	_ "github.com/joshp123/gohome/proto/gen/plugins/roborock/v1"
+	_ "github.com/joshp123/gohome/proto/gen/plugins/tado/v1"
+)
+
+const (
+	listZones      = "/gohome.plugins.tado.v1.TadoService/ListZones"
+	setTemperature = "/gohome.plugins.tado.v1.TadoService/SetTemperature"
+	roborockStatus = "/gohome.plugins.roborock.v1.RoborockService/GetStatus"
+	startClean     = "/gohome.plugins.roborock.v1.RoborockService/StartClean"
+	healthCheck    = "/grpc.health.v1.Health/Check"
+)
+
+func TestMethodAccess(t *testing.T) {
+	cases := map[string]Access{
+		listZones:                 AccessRead,
+		setTemperature:            AccessWrite,
+		roborockStatus:            AccessRead,
+		startClean:                AccessWrite,
+		"/unknown.Service/Method": AccessWrite,
+	}
+	for method, want := range cases {
+		if got := MethodAccess(method); got != want {
+			t.Fatalf("MethodAccess(%s) = %s, want %s", method, got, want)
+		}
+	}
+}
+
+func TestAuthorizerScopes(t *testing.T) {
+	authz, err := NewAuthorizer(&configv1.AuthConfig{
+		Tokens: []*configv1.NamedToken{
+			{
+				Name:   "agent",
+				Scopes: []string{ScopeRead, "gohome.plugins.tado.v1.TadoService/SetTemperature", "gohome.plugins.roborock.v1.RoborockService/*"},
+				Deny:   []*configv1.DenyRule{{Method: "gohome.plugins.roborock.v1.RoborockService/StartClean", Between: "22:00-07:00"}},
+			},
+			{Name: "admin"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewAuthorizer: %v", err)
+	}
+
+	day := time.Date(2026, 1, 2, 14, 0, 0, 0, time.Local)
+	night := time.Date(2026, 1, 2, 23, 30, 0, 0, time.Local)
+
+	cases := []struct {
+		token  string
+		method string
+		at     time.Time
+		ok     bool
+	}{
+		{"agent", listZones, day, true},
+		{"agent", setTemperature, day, true},
+		{"agent", roborockStatus, night, true},
+		{"agent", startClean, day, true},
+		{"agent", startClean, night, false},
+		{"agent", "/gohome.plugins.daikin.v1.DaikinService/SetOnOff", day, false},
+		{"agent", healthCheck, night, true},
+		{"admin", startClean, night, true},
+		{"default", setTemperature, day, true},
+	}
+	for _, tc := range cases {
+		authz.now = func() time.Time { return tc.at }
+		ctx := auth.WithIdentity(context.Background(), auth.Identity{Name: tc.token})
+		err := authz.Authorize(ctx, tc.method)
+		if tc.ok && err != nil {
+			t.Fatalf("%s %s at %s: unexpected error %v", tc.token, tc.method, tc.at.Format("15:04"), err)
+		}
+		if !tc.ok && status.Code(err) != codes.PermissionDenied {
+			t.Fatalf("%s %s at %s: expected PermissionDenied, got %v", tc.token, tc.method, tc.at.Format("15:04"), err)
+		}
+	}
+}
+
+func TestAuthorizerCountsDenials(t *testing.T) {
+	authz, err := NewAuthorizer(&configv1.AuthConfig{
+		Tokens: []*configv1.NamedToken{{Name: "reader", Scopes: []string{ScopeRead}}},
+	})
+	if err != nil {
+		t.Fatalf("NewAuthorizer: %v", err)
+	}
+	counter := authzDenied.WithLabelValues("reader", "gohome.plugins.tado.v1.TadoService/SetTemperature")
+	before := testutil.ToFloat64(counter)
+
+	ctx := auth.WithIdentity(context.Background(), auth.Identity{Name: "reader"})
+	if err := authz.Authorize(ctx, setTemperature); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied, got %v", err)
+	}
+	if got := testutil.ToFloat64(counter); got != before+1 {
+		t.Fatalf("denial counter = %v, want %v", got, before+1)
+	}
+}
+
+func TestAuthorizerRejectsBadRules(t *testing.T) {
+	bad := []*configv1.NamedToken{
+		{Name: "a", Scopes: []string{"TadoService"}},
+		{Name: "b", Deny: []*configv1.DenyRule{{Method: "x.S/*", Between: "22:00"}}},
+		{Name: "c", Deny: []*configv1.DenyRule{{Method: "x.S/*", Between: "25:00-07:00"}}},
+	}
+	for _, token := range bad {
+		if _, err := NewAuthorizer(&configv1.AuthConfig{Tokens: []*configv1.NamedToken{token}}); err == nil {
+			t.Fatalf("expected error for token %s", token.Name)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// GatewayPrefix is where the JSON gateway is mounted on the HTTP mux.
+const GatewayPrefix = "/api"
+
+// maxGatewayBody bounds JSON request bodies.
+const maxGatewayBody = 1 << 20
+
+var gatewayJSON = protojson.MarshalOptions{UseProtoNames: true}
+
+// Gateway serves POST /api/<full.Service>/<Method> by transcoding the JSON
+// body through the registered descriptors and invoking the method in-process
+// on s.Server, so the usual auth, audit and availability interceptors apply.
+// GET /api lists the callable services. Only unary methods are supported.
+func (s *GRPCServer) Gateway() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.Trim(strings.TrimPrefix(r.URL.Path, GatewayPrefix), "/")
+		if path == "" {
+			if r.Method != http.MethodGet {
+				w.Header().Set("Allow", http.MethodGet)
+				writeMethodNotAllowed(w, "use GET to list services")
+				return
+			}
+			writeGatewayJSON(w, http.StatusOK, s.gatewayListing())
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeMethodNotAllowed(w, "use POST to call a method")
+			return
+		}
+		idx := strings.LastIndex(path, "/")
+		if idx <= 0 || idx == len(path)-1 {
+			writeGatewayError(w, status.New(codes.NotFound, "expected /api/<service>/<method>"))
+			return
+		}
+		s.gatewayCall(w, r, path[:idx], path[idx+1:])
+	})
+}
+
+type gatewayMethod struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Input     string `json:"input"`
+	Output    string `json:"output"`
+	Access    Access `json:"access"`
+	Streaming bool   `json:"streaming,omitempty"`
+}
+
+type gatewayService struct {
+	Name    string          `json:"name"`
+	Methods []gatewayMethod `json:"methods"`
+}
+
+func (s *GRPCServer) gatewayListing() map[string][]gatewayService {
+	var services []gatewayService
+	for name, info := range s.Server.GetServiceInfo() {
+		if strings.HasPrefix(name, "grpc.reflection.") || s.checkAvailable("/"+name+"/") != nil {
+			continue
+		}
+		svc := gatewayService{Name: name, Methods: []gatewayMethod{}}
+		for _, method := range info.Methods {
+			full := "/" + name + "/" + method.Name
+			entry := gatewayMethod{
+				Name:      method.Name,
+				Path:      GatewayPrefix + full,
+				Access:    MethodAccess(full),
+				Streaming: method.IsClientStream || method.IsServerStream,
+			}
+			if desc, err := findMethod(name, method.Name); err == nil {
+				entry.Input = string(desc.Input().FullName())
+				entry.Output = string(desc.Output().FullName())
+			}
+			svc.Methods = append(svc.Methods, entry)
+		}
+		sort.Slice(svc.Methods, func(i, j int) bool { return svc.Methods[i].Name < svc.Methods[j].Name })
+		services = append(services, svc)
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	return map[string][]gatewayService{"services": services}
+}
+
+func (s *GRPCServer) gatewayCall(w http.ResponseWriter, r *http.Request, service, method string) {
+	info, ok := s.Server.GetServiceInfo()[service]
+	if !ok {
+		writeGatewayError(w, status.Newf(codes.NotFound, "unknown service %s", service))
+		return
+	}
+	registered := false
+	for _, m := range info.Methods {
+		if m.Name == method {
+			if m.IsClientStream || m.IsServerStream {
+				writeGatewayError(w, status.Newf(codes.Unimplemented, "%s/%s is a streaming method; use gRPC", service, method))
+				return
+			}
+			registered = true
+		}
+	}
+	desc, err := findMethod(service, method)
+	if !registered || err != nil {
+		writeGatewayError(w, status.Newf(codes.NotFound, "unknown method %s/%s", service, method))
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxGatewayBody+1))
+	if err != nil {
+		writeGatewayError(w, status.Newf(codes.InvalidArgument, "read body: %v", err))
+		return
+	}
+	if len(body) > maxGatewayBody {
+		writeGatewayError(w, status.Newf(codes.ResourceExhausted, "request body exceeds %d bytes", maxGatewayBody))
+		return
+	}
+	req := newMessage(desc.Input())
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := protojson.Unmarshal(body, req); err != nil {
+			writeGatewayError(w, status.Newf(codes.InvalidArgument, "decode %s: %v", desc.Input().FullName(), err))
+			return
+		}
+	}
+	payload, err := proto.Marshal(req)
+	if err != nil {
+		writeGatewayError(w, status.Newf(codes.Internal, "encode request: %v", err))
+		return
+	}
+
+	resp, st := s.invoke(r, "/"+service+"/"+method, payload)
+	if st.Code() != codes.OK {
+		writeGatewayError(w, st)
+		return
+	}
+	out := newMessage(desc.Output())
+	if err := proto.Unmarshal(resp, out); err != nil {
+		writeGatewayError(w, status.Newf(codes.Internal, "decode response: %v", err))
+		return
+	}
+	data, err := gatewayJSON.Marshal(out)
+	if err != nil {
+		writeGatewayError(w, status.Newf(codes.Internal, "encode response: %v", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+// invoke runs one unary call through grpc.Server.ServeHTTP with an in-memory
+// HTTP/2-shaped request. The caller's Authorization header and TLS state are
+// passed through so the gRPC interceptors see the same identity.
+func (s *GRPCServer) invoke(r *http.Request, fullMethod string, payload []byte) ([]byte, *status.Status) {
+	s.inproc.RLock()
+	defer s.inproc.RUnlock()
+	if s.stopping {
+		return nil, status.New(codes.Unavailable, "server is shutting down")
+	}
+
+	frame := make([]byte, 5+len(payload))
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(payload)))
+	copy(frame[5:], payload)
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, fullMethod, bytes.NewReader(frame))
+	if err != nil {
+		return nil, status.Newf(codes.Internal, "build request: %v", err)
+	}
+	req.ProtoMajor, req.ProtoMinor, req.Proto = 2, 0, "HTTP/2.0"
+	req.Host = r.Host
+	req.RemoteAddr = r.RemoteAddr
+	req.TLS = r.TLS
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	if value := r.Header.Get("Authorization"); value != "" {
+		req.Header.Set("Authorization", value)
+	}
+
+	rec := &grpcRecorder{header: make(http.Header)}
+	s.Server.ServeHTTP(rec, req)
+
+	code, err := strconv.Atoi(rec.header.Get("Grpc-Status"))
+	if err != nil {
+		return nil, status.Newf(codes.Internal, "in-process call returned HTTP %d without a gRPC status", rec.code)
+	}
+	if codes.Code(code) != codes.OK {
+		message, _ := url.PathUnescape(rec.header.Get("Grpc-Message"))
+		return nil, status.New(codes.Code(code), message)
+	}
+	data := rec.body.Bytes()
+	if len(data) < 5 || data[0] != 0 {
+		return nil, status.New(codes.Internal, "in-process call returned no message")
+	}
+	size := binary.BigEndian.Uint32(data[1:5])
+	if int(size) != len(data)-5 {
+		return nil, status.New(codes.Internal, "in-process call returned a truncated message")
+	}
+	return data[5:], status.New(codes.OK, "")
+}
+
+// grpcRecorder captures the response of an in-process ServeHTTP call. gRPC
+// writes its status into the header map as trailers once the body is done.
+type grpcRecorder struct {
+	header http.Header
+	body   bytes.Buffer
+	code   int
+}
+
+func (r *grpcRecorder) Header() http.Header { return r.header }
+
+func (r *grpcRecorder) Write(p []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	return r.body.Write(p)
+}
+
+func (r *grpcRecorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+}
+
+func (r *grpcRecorder) Flush() {}
+
+func findMethod(service, method string) (protoreflect.MethodDescriptor, error) {
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, err
+	}
+	svc, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", service)
+	}
+	md := svc.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return nil, fmt.Errorf("%s has no method %s", service, method)
+	}
+	return md, nil
+}
+
+// newMessage prefers the generated type so responses round-trip exactly.
+func newMessage(desc protoreflect.MessageDescriptor) proto.Message {
+	if mt, err := protoregistry.GlobalTypes.FindMessageByName(desc.FullName()); err == nil {
+		return mt.New().Interface()
+	}
+	return dynamicpb.NewMessage(desc)
+}
+
+// HTTPStatus maps a gRPC code to the closest HTTP status.
+func HTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeGatewayError(w http.ResponseWriter, st *status.Status) {
+	writeGatewayJSON(w, HTTPStatus(st.Code()), map[string]string{
+		"code":    st.Code().String(),
+		"message": st.Message(),
+	})
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter, message string) {
+	writeGatewayJSON(w, http.StatusMethodNotAllowed, map[string]string{
+		"code":    codes.Unimplemented.String(),
+		"message": message,
+	})
+}
+
+func writeGatewayJSON(w http.ResponseWriter, code int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/joshp123/gohome/internal/audit"
+	"github.com/joshp123/gohome/internal/auth"
+	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
+)
+
+func newGatewayServer(t *testing.T, opts Options) *GRPCServer {
+	t.Helper()
+	g, err := NewGRPCServer("127.0.0.1:0", opts)
+	if err != nil {
+		t.Fatalf("NewGRPCServer: %v", err)
+	}
+	t.Cleanup(func() { _ = g.Shutdown(context.Background()) })
+	hs := health.NewServer()
+	hs.SetServingStatus("gohome.plugins.tado.v1.TadoService", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(g.Server, hs)
+	return g
+}
+
+func gatewayDo(t *testing.T, h http.Handler, method, path, body, token string) (int, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	var out map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatalf("%s %s: decode %q: %v", method, path, rec.Body.String(), err)
+	}
+	return rec.Code, out
+}
+
+func TestGatewayCall(t *testing.T) {
+	gw := newGatewayServer(t, Options{}).Gateway()
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		code   int
+		field  string
+		want   string
+	}{
+		{"ok", http.MethodPost, "/api/grpc.health.v1.Health/Check", `{"service":""}`, http.StatusOK, "status", "SERVING"},
+		{"empty body", http.MethodPost, "/api/grpc.health.v1.Health/Check", "", http.StatusOK, "status", "SERVING"},
+		{"proto field", http.MethodPost, "/api/grpc.health.v1.Health/Check", `{"service":"gohome.plugins.tado.v1.TadoService"}`, http.StatusOK, "status", "NOT_SERVING"},
+		{"grpc error", http.MethodPost, "/api/grpc.health.v1.Health/Check", `{"service":"nope"}`, http.StatusNotFound, "code", "NotFound"},
+		{"bad json", http.MethodPost, "/api/grpc.health.v1.Health/Check", `{"service":`, http.StatusBadRequest, "code", "InvalidArgument"},
+		{"unknown field", http.MethodPost, "/api/grpc.health.v1.Health/Check", `{"bogus":1}`, http.StatusBadRequest, "code", "InvalidArgument"},
+		{"unknown method", http.MethodPost, "/api/grpc.health.v1.Health/Nope", "", http.StatusNotFound, "code", "NotFound"},
+		{"unknown service", http.MethodPost, "/api/nope.Service/Call", "", http.StatusNotFound, "code", "NotFound"},
+		{"streaming", http.MethodPost, "/api/grpc.health.v1.Health/Watch", "", http.StatusNotImplemented, "code", "Unimplemented"},
+		{"wrong verb", http.MethodGet, "/api/grpc.health.v1.Health/Check", "", http.StatusMethodNotAllowed, "code", "Unimplemented"},
+	}
+	for _, tc := range cases {
+		code, out := gatewayDo(t, gw, tc.method, tc.path, tc.body, "")
+		if code != tc.code || out[tc.field] != tc.want {
+			t.Fatalf("%s: got %d %v, want %d %s=%s", tc.name, code, out, tc.code, tc.field, tc.want)
+		}
+	}
+}
+
+func TestGatewayListing(t *testing.T) {
+	gw := newGatewayServer(t, Options{}).Gateway()
+
+	code, out := gatewayDo(t, gw, http.MethodGet, "/api", "", "")
+	if code != http.StatusOK {
+		t.Fatalf("GET /api = %d", code)
+	}
+	services, _ := out["services"].([]any)
+	if len(services) != 1 {
+		t.Fatalf("expected only the health service (reflection hidden), got %v", services)
+	}
+	svc := services[0].(map[string]any)
+	if svc["name"] != "grpc.health.v1.Health" {
+		t.Fatalf("service = %v", svc["name"])
+	}
+	methods := svc["methods"].([]any)
+	check := methods[0].(map[string]any)
+	if check["name"] != "Check" || check["path"] != "/api/grpc.health.v1.Health/Check" || check["input"] != "grpc.health.v1.HealthCheckRequest" {
+		t.Fatalf("unexpected method entry %v", check)
+	}
+}
+
+func TestGatewayForwardsToken(t *testing.T) {
+	dir := t.TempDir()
+	writeToken := func(name, secret string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(secret), 0o600); err != nil {
+			t.Fatalf("write token: %v", err)
+		}
+		return path
+	}
+	authCfg := &configv1.AuthConfig{
+		Tokens: []*configv1.NamedToken{
+			{Name: "admin", TokenFile: writeToken("admin", "admin-secret")},
+		},
+	}
+	tokens, err := auth.LoadTokens(authCfg)
+	if err != nil {
+		t.Fatalf("LoadTokens: %v", err)
+	}
+	authz, err := NewAuthorizer(authCfg)
+	if err != nil {
+		t.Fatalf("NewAuthorizer: %v", err)
+	}
+	log, err := audit.Open(filepath.Join(dir, "audit.jsonl"), 0, 0)
+	if err != nil {
+		t.Fatalf("audit.Open: %v", err)
+	}
+	defer log.Close()
+	g := newGatewayServer(t, Options{Tokens: tokens, Authz: authz})
+	audit.RegisterAuditService(g.Server, log)
+	gw := g.Gateway()
+
+	// Health is open to everyone, so exercise a service behind auth.
+	path := "/api/gohome.audit.v1.AuditService/Query"
+	if code, out := gatewayDo(t, gw, http.MethodPost, path, "", "wrong"); code != http.StatusUnauthorized {
+		t.Fatalf("bad token: got %d %v", code, out)
+	}
+	if code, out := gatewayDo(t, gw, http.MethodPost, path, "", "admin-secret"); code != http.StatusOK {
+		t.Fatalf("good token: got %d %v", code, out)
+	}
+}
+
+func TestGatewayRefusesAfterShutdown(t *testing.T) {
+	g := newGatewayServer(t, Options{})
+	if err := g.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	code, out := gatewayDo(t, g.Gateway(), http.MethodPost, "/api/grpc.health.v1.Health/Check", "", "")
+	if code != http.StatusServiceUnavailable {
+		t.Fatalf("got %d %v", code, out)
+	}
+}
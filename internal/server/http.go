@@ -1,18 +1,50 @@
 package server
 
 import (
+	"context"
 	"net/http"
+	"sync/atomic"
 )
 
+// openPaths are served without a bearer token so liveness probes keep working.
+var openPaths = []string{"/health"}
+
 // HTTPServer serves health, metrics, and dashboards.
 type HTTPServer struct {
 	Server *http.Server
+
+	handler atomic.Pointer[http.Handler]
+}
+
+func NewHTTPServer(addr string, handler http.Handler, opts Options) *HTTPServer {
+	s := &HTTPServer{}
+	s.SetHandler(handler)
+	s.Server = &http.Server{
+		Addr:      addr,
+		Handler:   opts.Tokens.Middleware(http.HandlerFunc(s.serveHTTP), openPaths...),
+		TLSConfig: opts.TLS,
+	}
+	return s
 }
 
-func NewHTTPServer(addr string, handler http.Handler) *HTTPServer {
-	return &HTTPServer{Server: &http.Server{Addr: addr, Handler: handler}}
+// SetHandler swaps the root handler, e.g. after a config reload changed the
+// set of plugin routes. In-flight requests finish on the previous handler.
+func (s *HTTPServer) SetHandler(handler http.Handler) {
+	s.handler.Store(&handler)
 }
 
 func (s *HTTPServer) ListenAndServe() error {
+	if s.Server.TLSConfig != nil {
+		return s.Server.ListenAndServeTLS("", "")
+	}
 	return s.Server.ListenAndServe()
 }
+
+// Shutdown stops accepting connections and waits for active requests.
+func (s *HTTPServer) Shutdown(ctx context.Context) error {
+	return s.Server.Shutdown(ctx)
+}
+
+func (s *HTTPServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	(*s.handler.Load()).ServeHTTP(w, r)
+}
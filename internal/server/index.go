@@ -0,0 +1,115 @@
+package server
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"log"
+	"net/http"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/joshp123/gohome/internal/core"
+)
+
+//go:embed templates/index.html
+var indexHTML string
+
+var indexTemplate = template.Must(template.New("index").Parse(indexHTML))
+
+type indexPage struct {
+	Plugins []indexPlugin
+}
+
+type indexPlugin struct {
+	ID         string
+	Name       string
+	Version    string
+	Status     core.HealthStatus
+	Message    string
+	Dashboards []indexLink
+	Services   []indexService
+	AgentsMD   template.HTML
+}
+
+type indexLink struct {
+	Name string
+	Path string
+}
+
+type indexService struct {
+	Name    string
+	Methods []indexMethod
+}
+
+type indexMethod struct {
+	Name      string
+	Access    Access
+	Streaming bool
+}
+
+// IndexHandler renders the landing page for plugins on every request, so
+// health and messages are always current. Links are relative so the page
+// also works behind the /gohome/ reverse proxy path.
+func IndexHandler(plugins []core.Plugin) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		page := indexPage{Plugins: make([]indexPlugin, 0, len(plugins))}
+		for _, plugin := range plugins {
+			page.Plugins = append(page.Plugins, indexEntry(plugin))
+		}
+		var buf bytes.Buffer
+		if err := indexTemplate.Execute(&buf, page); err != nil {
+			log.Printf("render index: %v", err)
+			http.Error(w, "render index", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(buf.Bytes())
+	})
+}
+
+func indexEntry(plugin core.Plugin) indexPlugin {
+	manifest := plugin.Manifest()
+	entry := indexPlugin{
+		ID:      manifest.PluginID,
+		Name:    manifest.DisplayName,
+		Version: manifest.Version,
+		Status:  plugin.Health(),
+		Message: plugin.HealthMessage(),
+	}
+	if entry.Name == "" {
+		entry.Name = manifest.PluginID
+	}
+	if md := plugin.AgentsMD(); md != "" {
+		entry.AgentsMD = renderMarkdown(md)
+	}
+	for _, dash := range plugin.Dashboards() {
+		entry.Dashboards = append(entry.Dashboards, indexLink{
+			Name: dash.Name,
+			Path: "dashboards/" + manifest.PluginID + "/" + dash.Name + ".json",
+		})
+	}
+	for _, name := range manifest.Services {
+		service := indexService{Name: name}
+		desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+		if sd, ok := desc.(protoreflect.ServiceDescriptor); err == nil && ok {
+			methods := sd.Methods()
+			for i := 0; i < methods.Len(); i++ {
+				method := methods.Get(i)
+				service.Methods = append(service.Methods, indexMethod{
+					Name:      string(method.Name()),
+					Access:    MethodAccess("/" + name + "/" + string(method.Name())),
+					Streaming: method.IsStreamingClient() || method.IsStreamingServer(),
+				})
+			}
+		}
+		entry.Services = append(entry.Services, service)
+	}
+	return entry
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+
+	"github.com/joshp123/gohome/internal/core"
+	"github.com/joshp123/gohome/internal/oauth"
+)
+
+type indexStub struct {
+	health  *core.HealthStatus
+	message string
+}
+
+func (s indexStub) ID() string { return "tado" }
+
+func (s indexStub) Manifest() core.Manifest {
+	return core.Manifest{
+		PluginID:    "tado",
+		DisplayName: "Tado",
+		Version:     "0.1.0",
+		Services:    []string{"gohome.plugins.tado.v1.TadoService"},
+	}
+}
+
+func (s indexStub) AgentsMD() string {
+	return "# Tado\n\nUse `ListZones` first.\n\n- read <zones>\n- set **temperature**\n"
+}
+
+func (s indexStub) OAuthDeclaration() oauth.Declaration { return oauth.Declaration{} }
+
+func (s indexStub) Dashboards() []core.Dashboard {
+	return []core.Dashboard{{Name: "tado-overview", JSON: []byte("{}")}}
+}
+
+func (s indexStub) RegisterGRPC(*grpc.Server) {}
+
+func (s indexStub) Collectors() []prometheus.Collector { return nil }
+
+func (s indexStub) Health() core.HealthStatus { return *s.health }
+
+func (s indexStub) HealthMessage() string { return s.message }
+
+func renderIndex(t *testing.T, h http.Handler) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET / = %d", rec.Code)
+	}
+	return rec.Body.String()
+}
+
+func TestIndexHandler(t *testing.T) {
+	health := core.HealthHealthy
+	stub := indexStub{health: &health, message: "token <expired>"}
+	h := IndexHandler([]core.Plugin{core.NewCorePlugin("dev"), stub})
+
+	body := renderIndex(t, h)
+	for _, want := range []string{
+		"GoHome Core",
+		`<h2>Tado <span class="status HEALTHY">HEALTHY</span></h2>`,
+		"0.1.0",
+		"token &lt;expired&gt;",
+		`href="dashboards/tado/tado-overview.json"`,
+		"<code>gohome.plugins.tado.v1.TadoService</code>",
+		"<code>SetTemperature</code> <span class=\"meta\">write",
+		"<code>ListZones</code> <span class=\"meta\">read",
+		"<h1>Tado</h1>",
+		"<li>read &lt;zones&gt;</li>",
+		"<strong>temperature</strong>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("index missing %q:\n%s", want, body)
+		}
+	}
+
+	health = core.HealthError
+	if body := renderIndex(t, h); !strings.Contains(body, `class="status ERROR"`) {
+		t.Fatalf("index did not pick up health change")
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/nope", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("GET /nope = %d, want 404", rec.Code)
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	got := string(renderMarkdown("## Usage\n\n1. first\n2. second\n\n```\nx < y\n```\nSee [docs](https://example.com) or [local](javascript:void).\n"))
+	for _, want := range []string{
+		"<h2>Usage</h2>",
+		"<ol>\n<li>first</li>\n<li>second</li>\n</ol>",
+		"<pre><code>x &lt; y\n</code></pre>",
+		`<a href="https://example.com">docs</a>`,
+		"or local.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("markdown missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+var (
+	mdCode    = regexp.MustCompile("`([^`]+)`")
+	mdBold    = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	mdLink    = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	mdOrdered = regexp.MustCompile(`^\d+[.)]\s+`)
+)
+
+// renderMarkdown converts the subset of Markdown used by plugin AGENTS.md
+// files (headings, lists, fenced code, paragraphs, inline code, bold and
+// links) to HTML. Everything else is escaped and shown as text.
+func renderMarkdown(src string) template.HTML {
+	var b strings.Builder
+	var para []string
+	list := ""
+	inCode := false
+
+	flushPara := func() {
+		if len(para) > 0 {
+			b.WriteString("<p>" + renderInline(strings.Join(para, " ")) + "</p>\n")
+			para = nil
+		}
+	}
+	closeList := func() {
+		if list != "" {
+			b.WriteString("</" + list + ">\n")
+			list = ""
+		}
+	}
+	openList := func(tag string) {
+		if list != tag {
+			closeList()
+			b.WriteString("<" + tag + ">\n")
+			list = tag
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			if inCode {
+				b.WriteString("</code></pre>\n")
+			} else {
+				flushPara()
+				closeList()
+				b.WriteString("<pre><code>")
+			}
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			b.WriteString(html.EscapeString(line) + "\n")
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			flushPara()
+			closeList()
+		case strings.HasPrefix(trimmed, "#"):
+			flushPara()
+			closeList()
+			level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+			if level > 6 {
+				level = 6
+			}
+			tag := "h" + string(rune('0'+level))
+			b.WriteString("<" + tag + ">" + renderInline(strings.TrimSpace(trimmed[level:])) + "</" + tag + ">\n")
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* "):
+			flushPara()
+			openList("ul")
+			b.WriteString("<li>" + renderInline(trimmed[2:]) + "</li>\n")
+		case mdOrdered.MatchString(trimmed):
+			flushPara()
+			openList("ol")
+			b.WriteString("<li>" + renderInline(mdOrdered.ReplaceAllString(trimmed, "")) + "</li>\n")
+		default:
+			closeList()
+			para = append(para, trimmed)
+		}
+	}
+	if inCode {
+		b.WriteString("</code></pre>\n")
+	}
+	flushPara()
+	closeList()
+	return template.HTML(b.String())
+}
+
+func renderInline(text string) string {
+	out := html.EscapeString(text)
+	out = mdCode.ReplaceAllString(out, "<code>$1</code>")
+	out = mdBold.ReplaceAllString(out, "<strong>$1</strong>")
+	return mdLink.ReplaceAllStringFunc(out, func(match string) string {
+		parts := mdLink.FindStringSubmatch(match)
+		href := parts[2]
+		if !strings.HasPrefix(href, "http://") && !strings.HasPrefix(href, "https://") {
+			return parts[1]
+		}
+		return `<a href="` + href + `">` + parts[1] + `</a>`
+	})
+}
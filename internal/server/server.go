@@ -2,30 +2,64 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"log"
 	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/joshp123/gohome/internal/audit"
+	"github.com/joshp123/gohome/internal/auth"
 )
 
 const defaultUnaryTimeout = 20 * time.Second
 
+// Options configures authentication and transport security for the gRPC and
+// HTTP listeners. The zero value serves plaintext without authentication.
+type Options struct {
+	Tokens *auth.Tokens
+	Authz  *Authorizer
+	Audit  *audit.Log
+	TLS    *tls.Config
+}
+
 // GRPCServer wraps a gRPC server and listener.
 type GRPCServer struct {
 	Server   *grpc.Server
 	Listener net.Listener
+
+	unavailable atomic.Pointer[map[string]bool]
+
+	// inproc guards gateway calls made through Server.ServeHTTP, which
+	// GracefulStop cannot drain; Shutdown waits for them and then refuses more.
+	inproc   sync.RWMutex
+	stopping bool
 }
 
-func NewGRPCServer(addr string) (*GRPCServer, error) {
+func NewGRPCServer(addr string, opts Options) (*GRPCServer, error) {
 	ln, err := net.Listen("tcp", addr)
 	if err != nil {
 		return nil, err
 	}
 
-	s := grpc.NewServer(
-		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	g := &GRPCServer{Listener: ln}
+	var serverOpts []grpc.ServerOption
+	if opts.TLS != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(opts.TLS)))
+	}
+	serverOpts = append(serverOpts,
+		grpc.ChainUnaryInterceptor(opts.Tokens.UnaryInterceptor, auditUnary(opts.Audit), opts.Authz.UnaryInterceptor, func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := g.checkAvailable(info.FullMethod); err != nil {
+				return nil, err
+			}
 			start := time.Now()
 			if _, ok := ctx.Deadline(); !ok {
 				var cancel context.CancelFunc
@@ -41,12 +75,66 @@ func NewGRPCServer(addr string) (*GRPCServer, error) {
 			}
 			return resp, err
 		}),
+		grpc.ChainStreamInterceptor(opts.Tokens.StreamInterceptor, opts.Authz.StreamInterceptor, func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := g.checkAvailable(info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
 	)
+	s := grpc.NewServer(serverOpts...)
 	reflection.Register(s)
+	g.Server = s
 
-	return &GRPCServer{Server: s, Listener: ln}, nil
+	return g, nil
 }
 
 func (s *GRPCServer) Serve() error {
 	return s.Server.Serve(s.Listener)
 }
+
+// SetUnavailable marks registered services as unavailable, e.g. because a
+// config reload disabled the plugin behind them. Calls to them fail with
+// codes.Unavailable until the set is replaced.
+func (s *GRPCServer) SetUnavailable(services []string) {
+	set := make(map[string]bool, len(services))
+	for _, service := range services {
+		set[service] = true
+	}
+	s.unavailable.Store(&set)
+}
+
+func (s *GRPCServer) checkAvailable(fullMethod string) error {
+	set := s.unavailable.Load()
+	if set == nil {
+		return nil
+	}
+	service := strings.TrimPrefix(fullMethod, "/")
+	if idx := strings.LastIndex(service, "/"); idx >= 0 {
+		service = service[:idx]
+	}
+	if (*set)[service] {
+		return status.Errorf(codes.Unavailable, "%s is not active in the current config", service)
+	}
+	return nil
+}
+
+// Shutdown drains in-flight RPCs, forcing a hard stop if ctx expires first.
+func (s *GRPCServer) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.inproc.Lock()
+		s.stopping = true
+		s.inproc.Unlock()
+		s.Server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.Server.Stop()
+		<-done
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
+)
+
+// TLSConfig builds the listener TLS config from cfg. It returns nil when no
+// certificate is configured. A client CA enables mutual TLS.
+func TLSConfig(cfg *configv1.TLSConfig) (*tls.Config, error) {
+	if cfg.GetCertFile() == "" && cfg.GetKeyFile() == "" {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(cfg.GetCertFile(), cfg.GetKeyFile())
+	if err != nil {
+		return nil, fmt.Errorf("load tls certificate: %w", err)
+	}
+	tlsCfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if path := cfg.GetClientCaFile(); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read tls client ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("tls client ca %s contains no certificates", path)
+		}
+		tlsCfg.ClientCAs = pool
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsCfg, nil
+}
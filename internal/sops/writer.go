@@ -0,0 +1,118 @@
+package sops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/joshp123/gohome/internal/secrets"
+)
+
+// DefaultKey is the key the secret is stored under; point sops-nix at it
+// with `key = "bootstrap"`.
+const DefaultKey = "bootstrap"
+
+var _ secrets.SecretWriter = Writer{}
+
+// Writer persists secrets into a nix-secrets repo via sops. Encryption keys
+// come from the creation rules in the repo's .sops.yaml.
+type Writer struct {
+	RepoPath string
+	// ConfigPath defaults to <RepoPath>/.sops.yaml.
+	ConfigPath string
+	// SecretName is the file name relative to RepoPath. Its extension picks
+	// the format: .json, or .yaml when it has none.
+	SecretName string
+	// Key is the top-level key holding the plaintext; DefaultKey if empty.
+	Key  string
+	Exec string
+}
+
+// Write stores plaintext under Key in the configured secret file. A new
+// file is encrypted under the matching creation rule; an existing one keeps
+// its other keys and recipients.
+func (w Writer) Write(ctx context.Context, plaintext []byte) (string, error) {
+	if w.RepoPath == "" {
+		return "", fmt.Errorf("sops repo path is required")
+	}
+	secretName := w.SecretName
+	if secretName == "" {
+		return "", fmt.Errorf("sops secret name is required")
+	}
+	format, ok := formatFor(secretName)
+	if !ok {
+		secretName += ".yaml"
+		format = "yaml"
+	}
+	config := w.ConfigPath
+	if config == "" {
+		config = filepath.Join(w.RepoPath, ".sops.yaml")
+	}
+	if _, err := os.Stat(config); err != nil {
+		return "", fmt.Errorf("stat .sops.yaml: %w", err)
+	}
+	key := w.Key
+	if key == "" {
+		key = DefaultKey
+	}
+	secretPath := filepath.Join(w.RepoPath, secretName)
+
+	value, err := json.Marshal(string(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	var args []string
+	var stdin []byte
+	if _, err := os.Stat(secretPath); err == nil {
+		keyPath, err := json.Marshal([]string{key})
+		if err != nil {
+			return "", err
+		}
+		// The value goes over stdin so the plaintext never shows up in
+		// the process list.
+		stdin = value
+		args = []string{"--config", config, "set", "--value-stdin", secretName, string(keyPath)}
+	} else if os.IsNotExist(err) {
+		// JSON is valid YAML, so one document serves both formats.
+		stdin = []byte(fmt.Sprintf("{%q: %s}\n", key, value))
+		args = []string{"--config", config, "--encrypt",
+			"--filename-override", secretName,
+			"--input-type", format, "--output-type", format,
+			"--output", secretName, "/dev/stdin"}
+	} else {
+		return "", fmt.Errorf("stat %s: %w", secretPath, err)
+	}
+
+	execName := w.Exec
+	if execName == "" {
+		execName = "sops"
+	}
+	// Run from the repo so path_regex creation rules match the relative
+	// file name.
+	cmd := exec.CommandContext(ctx, execName, args...)
+	cmd.Dir = w.RepoPath
+	cmd.Stdin = bytes.NewReader(stdin)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("sops: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return secretPath, nil
+}
+
+func formatFor(name string) (string, bool) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return "json", true
+	case ".yaml", ".yml":
+		return "yaml", true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,139 @@
+package sops
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeSops writes a stand-in sops that records its arguments and working
+// directory. With --encrypt it copies stdin to the --output file; set
+// copies stdin to sops.stdin next to the log.
+func fakeSops(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	log := filepath.Join(dir, "sops.log")
+	script := filepath.Join(dir, "sops")
+	body := "#!/bin/sh\n" +
+		"echo \"cwd=$(pwd)\" >> " + log + "\n" +
+		"for arg in \"$@\"; do echo \"arg=$arg\" >> " + log + "; done\n" +
+		"out=\n" +
+		"while [ $# -gt 0 ]; do\n" +
+		"  if [ \"$1\" = --output ]; then out=$2; fi\n" +
+		"  shift\n" +
+		"done\n" +
+		"if [ -n \"$out\" ]; then cat > \"$out\"; else cat > " + filepath.Join(dir, "sops.stdin") + "; fi\n"
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatalf("write fake sops: %v", err)
+	}
+	return script, log
+}
+
+func newRepo(t *testing.T) string {
+	t.Helper()
+	repo := t.TempDir()
+	rules := "creation_rules:\n  - path_regex: gohome-.*\\.(yaml|json)$\n    age: age1host\n"
+	if err := os.WriteFile(filepath.Join(repo, ".sops.yaml"), []byte(rules), 0o644); err != nil {
+		t.Fatalf("write .sops.yaml: %v", err)
+	}
+	return repo
+}
+
+func loggedArgs(t *testing.T, log string) []string {
+	t.Helper()
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	var args []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if arg, ok := strings.CutPrefix(line, "arg="); ok {
+			args = append(args, arg)
+		}
+	}
+	return args
+}
+
+func TestWriterEncryptsNewFile(t *testing.T) {
+	repo := newRepo(t)
+	execPath, log := fakeSops(t)
+
+	path, err := Writer{RepoPath: repo, SecretName: "gohome-tado-bootstrap", Exec: execPath}.Write(context.Background(), []byte(`{"client_id":"x"}`))
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if want := filepath.Join(repo, "gohome-tado-bootstrap.yaml"); path != want {
+		t.Fatalf("path = %s, want %s", path, want)
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"bootstrap": "{\"client_id\":\"x\"}"}`+"\n" {
+		t.Fatalf("plaintext handed to sops = %q", data)
+	}
+
+	want := []string{"--config", filepath.Join(repo, ".sops.yaml"), "--encrypt",
+		"--filename-override", "gohome-tado-bootstrap.yaml",
+		"--input-type", "yaml", "--output-type", "yaml",
+		"--output", "gohome-tado-bootstrap.yaml", "/dev/stdin"}
+	if got := loggedArgs(t, log); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("sops args = %q, want %q", got, want)
+	}
+	calls, _ := os.ReadFile(log)
+	if !strings.Contains(string(calls), "cwd="+repo) {
+		t.Fatalf("sops not run from the repo, so creation rules would not match:\n%s", calls)
+	}
+}
+
+func TestWriterJSONFormat(t *testing.T) {
+	repo := newRepo(t)
+	execPath, log := fakeSops(t)
+
+	if _, err := (Writer{RepoPath: repo, SecretName: "gohome-weheat-bootstrap.json", Key: "weheat", Exec: execPath}).Write(context.Background(), []byte("{}")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	args := strings.Join(loggedArgs(t, log), " ")
+	if !strings.Contains(args, "--input-type json --output-type json") {
+		t.Fatalf("sops args = %s, want json formats", args)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repo, "gohome-weheat-bootstrap.json")); string(data) != `{"weheat": "{}"}`+"\n" {
+		t.Fatalf("plaintext = %q", data)
+	}
+}
+
+func TestWriterSetsKeyInExistingFile(t *testing.T) {
+	repo := newRepo(t)
+	existing := filepath.Join(repo, "gohome-secrets.yaml")
+	if err := os.WriteFile(existing, []byte("other: ENC[...]\nsops: {}\n"), 0o600); err != nil {
+		t.Fatalf("write existing: %v", err)
+	}
+	execPath, log := fakeSops(t)
+
+	if _, err := (Writer{RepoPath: repo, SecretName: "gohome-secrets.yaml", Key: "tado", Exec: execPath}).Write(context.Background(), []byte(`{"a":1}`)); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	want := []string{"--config", filepath.Join(repo, ".sops.yaml"), "set", "--value-stdin", "gohome-secrets.yaml", `["tado"]`}
+	got := loggedArgs(t, log)
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("sops args = %q, want %q", got, want)
+	}
+	for _, arg := range got {
+		if strings.Contains(arg, `"a"`) {
+			t.Fatalf("secret passed on the command line: %q", arg)
+		}
+	}
+	stdin, err := os.ReadFile(filepath.Join(filepath.Dir(log), "sops.stdin"))
+	if err != nil || string(stdin) != `"{\"a\":1}"` {
+		t.Fatalf("value on stdin = %q, %v", stdin, err)
+	}
+	if data, _ := os.ReadFile(existing); !strings.HasPrefix(string(data), "other:") {
+		t.Fatalf("existing file rewritten: %q", data)
+	}
+}
+
+func TestWriterRequiresSopsConfig(t *testing.T) {
+	execPath, _ := fakeSops(t)
+	_, err := Writer{RepoPath: t.TempDir(), SecretName: "gohome-x.yaml", Exec: execPath}.Write(context.Background(), []byte("{}"))
+	if err == nil || !strings.Contains(err.Error(), ".sops.yaml") {
+		t.Fatalf("Write = %v, want missing .sops.yaml error", err)
+	}
+}
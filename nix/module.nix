@@ -21,6 +21,9 @@ let
     replaceStrings [ "\\" "\"" "\n" "\r" "\t" ] [ "\\\\" "\\\"" "\\n" "\\r" "\\t" ] (toString value);
 
   textprotoString = value: "\"${escapeTextproto value}\"";
+
+  # A file:// blob endpoint keeps OAuth state on local disk; no S3 settings needed.
+  fileBlob = cfg.oauth.blobEndpoint != null && hasPrefix "file://" cfg.oauth.blobEndpoint;
   textprotoMapString = field: attrs:
     lib.concatStringsSep "\n" (lib.mapAttrsToList (k: v: ''
       ${field} {
@@ -50,6 +53,12 @@ ${lib.concatStringsSep "\n" lines}
       }
   '';
 
+  # The metrics scrape token is accepted alongside the user-defined tokens.
+  authTokens = cfg.auth.tokens
+    // optionalAttrs (cfg.auth.metricsTokenFile != null) {
+      victoriametrics = { tokenFile = cfg.auth.metricsTokenFile; scopes = [ "read" ]; deny = [ ]; };
+    };
+
   p1TariffLine = field: value:
     if value == null
     then ""
@@ -61,31 +70,89 @@ ${lib.concatStringsSep "\n" lines}
       grpc_addr: ${textprotoString "${cfg.listenAddress}:${toString cfg.grpcPort}"}
       http_addr: ${textprotoString "${cfg.listenAddress}:${toString cfg.httpPort}"}
       dashboard_dir: ${textprotoString "/var/lib/gohome/dashboards"}
+      grpc_health_degraded: ${if cfg.degradedServing then "DEGRADED_HEALTH_SERVING" else "DEGRADED_HEALTH_NOT_SERVING"}
+      auth {
+  '' + optionalString (cfg.auth.tokenFile != null) ''
+        token_file: ${textprotoString cfg.auth.tokenFile}
+  '' + concatStrings (mapAttrsToList (name: token: ''
+        tokens {
+          name: ${textprotoString name}
+          token_file: ${textprotoString token.tokenFile}
+  '' + concatMapStrings (scope: ''
+          scopes: ${textprotoString scope}
+  '') token.scopes + concatMapStrings (rule: ''
+          deny {
+            method: ${textprotoString rule.method}
+  '' + optionalString (rule.between != null) ''
+            between: ${textprotoString rule.between}
+  '' + ''
+          }
+  '') token.deny + ''
+        }
+  '') authTokens) + ''
+      }
+  '' + optionalString (cfg.tls.certFile != null) ''
+      tls {
+        cert_file: ${textprotoString cfg.tls.certFile}
+        key_file: ${textprotoString cfg.tls.keyFile}
+  '' + optionalString (cfg.tls.certFile != null && cfg.tls.clientCaFile != null) ''
+        client_ca_file: ${textprotoString cfg.tls.clientCaFile}
+  '' + optionalString (cfg.tls.certFile != null) ''
+      }
+  '' + optionalString (cfg.rateState.backend != null) ''
+      rate_state {
+        backend: ${if cfg.rateState.backend == "blob" then "RATE_STATE_BACKEND_BLOB" else "RATE_STATE_BACKEND_FILE"}
+        dir: ${textprotoString "/var/lib/gohome"}
+      }
+  '' + ''
     }
     oauth {
       blob_endpoint: ${textprotoString cfg.oauth.blobEndpoint}
-      blob_bucket: ${textprotoString cfg.oauth.blobBucket}
       blob_prefix: ${textprotoString cfg.oauth.blobPrefix}
-      blob_access_key_file: ${textprotoString cfg.oauth.blobAccessKeyFile}
-      blob_secret_key_file: ${textprotoString cfg.oauth.blobSecretKeyFile}
       refresh_enabled: ${if cfg.oauth.refreshEnabled then "true" else "false"}
       refresh_interval_seconds: ${toString cfg.oauth.refreshIntervalSeconds}
+      blob_versions: ${toString cfg.oauth.blobVersions}
+  '' + optionalString (cfg.oauth.blobKeyFile != null) ''
+      blob_key_file: ${textprotoString cfg.oauth.blobKeyFile}
+  '' + concatMapStrings (path: ''
+      blob_previous_key_files: ${textprotoString path}
+  '') cfg.oauth.blobPreviousKeyFiles + optionalString (!fileBlob) ''
+      blob_bucket: ${textprotoString cfg.oauth.blobBucket}
+      blob_access_key_file: ${textprotoString cfg.oauth.blobAccessKeyFile}
+      blob_secret_key_file: ${textprotoString cfg.oauth.blobSecretKeyFile}
   '' + optionalString (cfg.oauth.blobRegion != null) ''
       blob_region: ${textprotoString cfg.oauth.blobRegion}
   '' + ''
     }
-  '' + optionalString (cfg.plugins.tado != null) ''
+  '' + optionalString (cfg.plugins.tado != null) (''
     tado {
+  '' + optionalString (cfg.plugins.tado.bootstrapFile != null) ''
       bootstrap_file: ${textprotoString cfg.plugins.tado.bootstrapFile}
   '' + optionalString (cfg.plugins.tado.homeId != null) ''
       home_id: ${toString cfg.plugins.tado.homeId}
+  '' + concatStrings (mapAttrsToList (name: account: ''
+      accounts {
+        name: ${textprotoString name}
+        bootstrap_file: ${textprotoString account.bootstrapFile}
+  '' + optionalString (account.homeId != null) ''
+        home_id: ${toString account.homeId}
   '' + ''
+      }
+  '') cfg.plugins.tado.accounts) + ''
     }
-  '' + optionalString (cfg.plugins.daikin != null) ''
+  '') + ''
+  '' + optionalString (cfg.plugins.daikin != null) (''
     daikin {
+  '' + optionalString (cfg.plugins.daikin.bootstrapFile != null) ''
       bootstrap_file: ${textprotoString cfg.plugins.daikin.bootstrapFile}
+  '' + concatStrings (mapAttrsToList (name: account: ''
+      accounts {
+        name: ${textprotoString name}
+        bootstrap_file: ${textprotoString account.bootstrapFile}
+      }
+  '') cfg.plugins.daikin.accounts) + ''
     }
-  '' + optionalString (cfg.plugins.growatt != null) ''
+  '') + optionalString (cfg.plugins.growatt != null) ''
     growatt {
       token_file: ${textprotoString cfg.plugins.growatt.tokenFile}
       region: ${textprotoString (if cfg.plugins.growatt.region == null then "other_regions" else cfg.plugins.growatt.region)}
@@ -128,6 +195,13 @@ ${p1TariffLine "tariff_export_t2_eur_per_kwh" cfg.plugins.p1_homewizard.tariffEx
     }
   '';
 
+  # Validated at build time so a bad config fails the system build instead of
+  # the service start.
+  configFile = pkgs.runCommand "gohome-config.pbtxt" { } ''
+    cp ${pkgs.writeText "gohome-config.pbtxt" configText} $out
+    ${gohomePkg}/bin/gohome config validate --config $out
+  '';
+
 in
 {
   imports = [
@@ -164,6 +238,89 @@ in
       description = "HTTP port (health/metrics/dashboards)";
     };
 
+    auth = {
+      tokenFile = mkOption {
+        type = types.nullOr types.path;
+        default = null;
+        description = "Bearer token accepted on gRPC and HTTP (caller name \"default\"). Leave all tokens unset to disable authentication.";
+      };
+
+      tokens = mkOption {
+        type = types.attrsOf (types.submodule {
+          options = {
+            tokenFile = mkOption {
+              type = types.path;
+              description = "File holding the token secret.";
+            };
+            scopes = mkOption {
+              type = types.listOf types.str;
+              default = [ ];
+              example = [ "read" "gohome.plugins.tado.v1.TadoService/SetTemperature" ];
+              description = "Methods this token may call: \"read\", \"*\" or Service/Method patterns. Empty grants everything.";
+            };
+            deny = mkOption {
+              type = types.listOf (types.submodule {
+                options = {
+                  method = mkOption {
+                    type = types.str;
+                    description = "Method pattern to block, e.g. gohome.plugins.roborock.v1.RoborockService/*.";
+                  };
+                  between = mkOption {
+                    type = types.nullOr types.str;
+                    default = null;
+                    example = "22:00-07:00";
+                    description = "Local time window for the block; null blocks always.";
+                  };
+                };
+              });
+              default = [ ];
+              description = "Methods blocked even when a scope allows them.";
+            };
+          };
+        });
+        default = { };
+        description = "Named bearer tokens keyed by caller name.";
+      };
+
+      metricsTokenFile = mkOption {
+        type = types.nullOr types.path;
+        default = null;
+        description = "Token VictoriaMetrics sends when scraping /metrics; required once any token is set. Must be readable by the victoriametrics user.";
+      };
+    };
+
+    tls = {
+      certFile = mkOption {
+        type = types.nullOr types.path;
+        default = null;
+        description = "TLS certificate for the gRPC and HTTP listeners.";
+      };
+
+      keyFile = mkOption {
+        type = types.nullOr types.path;
+        default = null;
+        description = "TLS private key for the gRPC and HTTP listeners.";
+      };
+
+      clientCaFile = mkOption {
+        type = types.nullOr types.path;
+        default = null;
+        description = "CA for client certificates; when set, clients must present one (mTLS). The local metrics scrape does not present one.";
+      };
+    };
+
+    degradedServing = mkOption {
+      type = types.bool;
+      default = true;
+      description = "Report services of DEGRADED plugins as SERVING in grpc.health.v1 (false reports NOT_SERVING).";
+    };
+
+    rateState.backend = mkOption {
+      type = types.nullOr (types.enum [ "file" "blob" ]);
+      default = "file";
+      description = "Persist provider rate-limit budgets across restarts: \"file\" under /var/lib/gohome/rate, \"blob\" in the OAuth S3 bucket, or null to keep them in memory only.";
+    };
+
     grafanaEnvFile = mkOption {
       type = types.nullOr types.path;
       default = null;
@@ -174,7 +331,25 @@ in
       blobEndpoint = mkOption {
         type = types.nullOr types.str;
         default = null;
-        description = "S3-compatible endpoint for OAuth state blob mirror (e.g., Hetzner Object Storage endpoint)";
+        description = "S3-compatible endpoint for OAuth state blob mirror (e.g., Hetzner Object Storage endpoint), or file:///var/lib/gohome/oauth-blobs to keep it on local disk";
+      };
+
+      blobKeyFile = mkOption {
+        type = types.nullOr types.path;
+        default = null;
+        description = "Secret file (32+ bytes, e.g. openssl rand -base64 32) used to encrypt OAuth blobs with AES-256-GCM; null stores plaintext JSON";
+      };
+
+      blobPreviousKeyFiles = mkOption {
+        type = types.listOf types.path;
+        default = [ ];
+        description = "Keys retired by `gohome oauth rekey`, kept to decrypt blobs written before the rekey";
+      };
+
+      blobVersions = mkOption {
+        type = types.ints.unsigned;
+        default = 3;
+        description = "Rotated versions kept per key by a file:// blob endpoint";
       };
 
       blobBucket = mkOption {
@@ -224,8 +399,9 @@ in
       type = types.nullOr (types.submodule {
         options = {
           bootstrapFile = mkOption {
-            type = types.path;
-            description = "Path to bootstrap Tado OAuth credentials (read-only secret)";
+            type = types.nullOr types.path;
+            default = null;
+            description = "Path to bootstrap Tado OAuth credentials for the default account (read-only secret)";
           };
 
           homeId = mkOption {
@@ -233,6 +409,26 @@ in
             default = null;
             description = "Optional homeId override (if /me contains multiple homes)";
           };
+
+          accounts = mkOption {
+            type = types.attrsOf (types.submodule {
+              options = {
+                bootstrapFile = mkOption {
+                  type = types.path;
+                  description = "Path to bootstrap Tado OAuth credentials for this account (read-only secret)";
+                };
+
+                homeId = mkOption {
+                  type = types.nullOr types.int;
+                  default = null;
+                  description = "Optional homeId override for this account";
+                };
+              };
+            });
+            default = { };
+            example = { holiday.bootstrapFile = "/run/agenix/gohome-tado-holiday-bootstrap"; };
+            description = "Additional named Tado accounts; zones from every account are exposed with an account label";
+          };
         };
       });
       default = null;
@@ -243,8 +439,23 @@ in
       type = types.nullOr (types.submodule {
         options = {
           bootstrapFile = mkOption {
-            type = types.path;
-            description = "Path to bootstrap Daikin Onecta OAuth credentials (read-only secret)";
+            type = types.nullOr types.path;
+            default = null;
+            description = "Path to bootstrap Daikin Onecta OAuth credentials for the default account (read-only secret)";
+          };
+
+          accounts = mkOption {
+            type = types.attrsOf (types.submodule {
+              options = {
+                bootstrapFile = mkOption {
+                  type = types.path;
+                  description = "Path to bootstrap Daikin Onecta OAuth credentials for this account (read-only secret)";
+                };
+              };
+            });
+            default = { };
+            example = { holiday.bootstrapFile = "/run/agenix/gohome-daikin-holiday-bootstrap"; };
+            description = "Additional named Daikin accounts; units from every account are exposed with an account label";
           };
         };
       });
@@ -424,24 +635,24 @@ in
         message = "services.gohome.oauth.blobEndpoint is required";
       }
       {
-        assertion = cfg.oauth.blobBucket != null;
-        message = "services.gohome.oauth.blobBucket is required";
+        assertion = fileBlob || cfg.oauth.blobBucket != null;
+        message = "services.gohome.oauth.blobBucket is required unless blobEndpoint is file://";
       }
       {
-        assertion = cfg.oauth.blobAccessKeyFile != null;
-        message = "services.gohome.oauth.blobAccessKeyFile is required";
+        assertion = fileBlob || cfg.oauth.blobAccessKeyFile != null;
+        message = "services.gohome.oauth.blobAccessKeyFile is required unless blobEndpoint is file://";
       }
       {
-        assertion = cfg.oauth.blobSecretKeyFile != null;
-        message = "services.gohome.oauth.blobSecretKeyFile is required";
+        assertion = fileBlob || cfg.oauth.blobSecretKeyFile != null;
+        message = "services.gohome.oauth.blobSecretKeyFile is required unless blobEndpoint is file://";
       }
       {
-        assertion = cfg.plugins.tado == null || cfg.plugins.tado.bootstrapFile != null;
-        message = "services.gohome.plugins.tado.bootstrapFile is required when tado is enabled";
+        assertion = cfg.plugins.tado == null || cfg.plugins.tado.bootstrapFile != null || cfg.plugins.tado.accounts != { };
+        message = "services.gohome.plugins.tado.bootstrapFile or accounts is required when tado is enabled";
       }
       {
-        assertion = cfg.plugins.daikin == null || cfg.plugins.daikin.bootstrapFile != null;
-        message = "services.gohome.plugins.daikin.bootstrapFile is required when daikin is enabled";
+        assertion = cfg.plugins.daikin == null || cfg.plugins.daikin.bootstrapFile != null || cfg.plugins.daikin.accounts != { };
+        message = "services.gohome.plugins.daikin.bootstrapFile or accounts is required when daikin is enabled";
       }
       {
         assertion = cfg.plugins.growatt == null || cfg.plugins.growatt.tokenFile != null;
@@ -462,12 +673,16 @@ in
 
     systemd.services.gohome = let
       secretChecks =
-        [
+        lib.optionals (!fileBlob) [
           "${pkgs.coreutils}/bin/test -r ${cfg.oauth.blobAccessKeyFile}"
           "${pkgs.coreutils}/bin/test -r ${cfg.oauth.blobSecretKeyFile}"
         ]
-        ++ lib.optional (cfg.plugins.tado != null) "${pkgs.coreutils}/bin/test -r ${cfg.plugins.tado.bootstrapFile}"
-        ++ lib.optional (cfg.plugins.daikin != null) "${pkgs.coreutils}/bin/test -r ${cfg.plugins.daikin.bootstrapFile}"
+        ++ lib.optional (cfg.oauth.blobKeyFile != null) "${pkgs.coreutils}/bin/test -r ${cfg.oauth.blobKeyFile}"
+        ++ map (path: "${pkgs.coreutils}/bin/test -r ${path}") cfg.oauth.blobPreviousKeyFiles
+        ++ lib.optional (cfg.plugins.tado != null && cfg.plugins.tado.bootstrapFile != null) "${pkgs.coreutils}/bin/test -r ${cfg.plugins.tado.bootstrapFile}"
+        ++ lib.optionals (cfg.plugins.tado != null) (mapAttrsToList (_: account: "${pkgs.coreutils}/bin/test -r ${account.bootstrapFile}") cfg.plugins.tado.accounts)
+        ++ lib.optional (cfg.plugins.daikin != null && cfg.plugins.daikin.bootstrapFile != null) "${pkgs.coreutils}/bin/test -r ${cfg.plugins.daikin.bootstrapFile}"
+        ++ lib.optionals (cfg.plugins.daikin != null) (mapAttrsToList (_: account: "${pkgs.coreutils}/bin/test -r ${account.bootstrapFile}") cfg.plugins.daikin.accounts)
         ++ lib.optional (cfg.plugins.growatt != null) "${pkgs.coreutils}/bin/test -r ${cfg.plugins.growatt.tokenFile}"
         ++ lib.optional (cfg.plugins.roborock != null) "${pkgs.coreutils}/bin/test -r ${cfg.plugins.roborock.bootstrapFile}"
         ;
@@ -475,18 +690,21 @@ in
       description = "GoHome";
       wantedBy = [ "multi-user.target" ];
       after = [ "network.target" ];
+      # Config-only changes are applied with SIGHUP instead of a restart.
+      reloadTriggers = [ configText ];
 
       serviceConfig = {
         User = "gohome";
         Group = "gohome";
         ExecStart = "${gohomePkg}/bin/gohome";
+        ExecReload = "${pkgs.coreutils}/bin/kill -HUP $MAINPID";
         Restart = "on-failure";
         ExecStartPre = secretChecks;
         RequiresMountsFor = [ "/run/agenix" ];
       };
     };
 
-    environment.etc."gohome/config.pbtxt".text = configText;
+    environment.etc."gohome/config.pbtxt".source = configFile;
 
     systemd.tmpfiles.rules = [
       "d /var/lib/gohome 0755 gohome gohome - -"
@@ -14,10 +14,11 @@ in
       listenAddress = "127.0.0.1:8428";
       prometheusConfig = {
         scrape_configs = [
-          {
+          ({
             job_name = "gohome";
             scrape_interval = "15s";
             metrics_path = "/metrics";
+            scheme = if cfg.tls.certFile != null then "https" else "http";
             static_configs = [
               {
                 targets = [ "127.0.0.1:8080" ];
@@ -27,6 +28,13 @@ in
               }
             ];
           }
+          // optionalAttrs (cfg.auth.metricsTokenFile != null) {
+            authorization.credentials_file = cfg.auth.metricsTokenFile;
+          }
+          // optionalAttrs (cfg.tls.certFile != null) {
+            # Loopback scrape of our own listener.
+            tls_config.insecure_skip_verify = true;
+          })
         ];
       };
       extraOptions = [
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -43,34 +44,55 @@ type Client struct {
 	lastGatewayRaw []json.RawMessage
 	cooldownUntil  time.Time
 	rateLimits     RateLimits
+
+	account         string
+	refreshInterval time.Duration
 }
 
 func NewClient(cfg Config, decl oauth.Declaration, rateDecl rate.Declaration, oauthCfg *configv1.OAuthConfig) (*Client, error) {
-	blobStore, err := oauth.NewS3Store(oauthCfg)
+	return newClient(cfg, decl, oauthCfg, rate.WrapHTTP(rateDecl, &http.Client{Timeout: 15 * time.Second}))
+}
+
+// newClient lets the plugin route every account through one rate guard.
+func newClient(cfg Config, decl oauth.Declaration, oauthCfg *configv1.OAuthConfig, httpClient *http.Client) (*Client, error) {
+	blobStore, err := oauth.NewBlobStore(oauthCfg)
 	if err != nil {
 		return nil, err
 	}
 
-	manager, err := oauth.NewManager(decl, cfg.BootstrapFile, blobStore)
+	manager, err := oauth.NewManager(decl.ForAccount(cfg.Account), cfg.BootstrapFile, blobStore)
 	if err != nil {
 		return nil, err
 	}
-	manager.StartWithInterval(context.Background(), oauth.RefreshInterval(oauthCfg))
-
 	baseURL := strings.TrimSpace(cfg.BaseURL)
 	if baseURL == "" {
 		baseURL = defaultBaseURL
 	}
 
-	httpClient := rate.WrapHTTP(rateDecl, &http.Client{Timeout: 15 * time.Second})
-
 	return &Client{
-		baseURL:    baseURL,
-		oauth:      manager,
-		httpClient: httpClient,
+		baseURL:         baseURL,
+		oauth:           manager,
+		httpClient:      httpClient,
+		account:         cfg.AccountName(),
+		refreshInterval: oauth.RefreshInterval(oauthCfg),
 	}, nil
 }
 
+// Account returns the account name, "default" for the default account.
+func (c *Client) Account() string {
+	return c.account
+}
+
+// Start begins background OAuth refresh; ctx bounds its lifetime.
+func (c *Client) Start(ctx context.Context) {
+	c.oauth.StartWithInterval(ctx, c.refreshInterval)
+}
+
+// Stop ends background OAuth refresh and releases the client's rate guard.
+func (c *Client) Stop(ctx context.Context) error {
+	return errors.Join(c.oauth.Stop(ctx), rate.Release(ctx, c.httpClient))
+}
+
 // Devices returns the Daikin units from the gateway devices endpoint.
 func (c *Client) Devices(ctx context.Context) ([]Device, error) {
 	states, err := c.DeviceStates(ctx)
@@ -3,6 +3,7 @@ package daikin
 import (
 	"fmt"
 
+	"github.com/joshp123/gohome/internal/oauth"
 	daikinv1 "github.com/joshp123/gohome/proto/gen/plugins/daikin/v1"
 )
 
@@ -10,22 +11,61 @@ const (
 	defaultBaseURL = "https://api.onecta.daikineurope.com"
 )
 
-// Config defines runtime configuration for the Daikin client.
+// Config defines runtime configuration for one Daikin account.
 type Config struct {
 	BaseURL       string
 	BootstrapFile string
+	// Account is "" for the default account (the top-level bootstrap_file).
+	Account string
 }
 
-func ConfigFromProto(cfg *daikinv1.DaikinConfig) (Config, error) {
+// ConfigsFromProto returns one Config per account: the default account first
+// when bootstrap_file is set, then the named accounts in order.
+func ConfigsFromProto(cfg *daikinv1.DaikinConfig) ([]Config, error) {
 	if cfg == nil {
-		return Config{}, fmt.Errorf("daikin config is required")
+		return nil, fmt.Errorf("daikin config is required")
 	}
-	if cfg.BootstrapFile == "" {
-		return Config{}, fmt.Errorf("daikin bootstrap_file is required")
+	if cfg.BootstrapFile == "" && len(cfg.Accounts) == 0 {
+		return nil, fmt.Errorf("daikin bootstrap_file is required")
 	}
 
-	return Config{
-		BaseURL:       defaultBaseURL,
-		BootstrapFile: cfg.BootstrapFile,
-	}, nil
+	var configs []Config
+	if cfg.BootstrapFile != "" {
+		configs = append(configs, Config{
+			BaseURL:       defaultBaseURL,
+			BootstrapFile: cfg.BootstrapFile,
+		})
+	}
+
+	seen := make(map[string]bool)
+	for _, account := range cfg.Accounts {
+		name := account.GetName()
+		if err := oauth.ValidateAccount(name); err != nil {
+			return nil, fmt.Errorf("daikin account: %w", err)
+		}
+		if name == oauth.DefaultAccount {
+			return nil, fmt.Errorf("daikin account %q is reserved; use the top-level bootstrap_file", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("daikin account %q is declared twice", name)
+		}
+		seen[name] = true
+		if account.GetBootstrapFile() == "" {
+			return nil, fmt.Errorf("daikin account %q: bootstrap_file is required", name)
+		}
+		configs = append(configs, Config{
+			BaseURL:       defaultBaseURL,
+			BootstrapFile: account.GetBootstrapFile(),
+			Account:       name,
+		})
+	}
+	return configs, nil
+}
+
+// AccountName returns the account's name, "default" for the default account.
+func (c Config) AccountName() string {
+	if c.Account == "" {
+		return oauth.DefaultAccount
+	}
+	return c.Account
 }
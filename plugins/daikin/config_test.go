@@ -0,0 +1,51 @@
+package daikin
+
+import (
+	"strings"
+	"testing"
+
+	daikinv1 "github.com/joshp123/gohome/proto/gen/plugins/daikin/v1"
+)
+
+func TestConfigsFromProto(t *testing.T) {
+	configs, err := ConfigsFromProto(&daikinv1.DaikinConfig{
+		BootstrapFile: "/run/agenix/daikin",
+		Accounts: []*daikinv1.DaikinAccount{
+			{Name: "holiday", BootstrapFile: "/run/agenix/daikin-holiday"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ConfigsFromProto: %v", err)
+	}
+	if len(configs) != 2 {
+		t.Fatalf("expected 2 accounts, got %d", len(configs))
+	}
+	if configs[0].Account != "" || configs[0].AccountName() != "default" || configs[0].BootstrapFile != "/run/agenix/daikin" {
+		t.Fatalf("unexpected default account: %+v", configs[0])
+	}
+	if configs[1].Account != "holiday" || configs[1].BootstrapFile != "/run/agenix/daikin-holiday" {
+		t.Fatalf("unexpected holiday account: %+v", configs[1])
+	}
+}
+
+func TestConfigsFromProtoErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *daikinv1.DaikinConfig
+		want string
+	}{
+		{"empty", &daikinv1.DaikinConfig{}, "bootstrap_file is required"},
+		{"bad name", &daikinv1.DaikinConfig{Accounts: []*daikinv1.DaikinAccount{{Name: "Holiday", BootstrapFile: "/x"}}}, "invalid account name"},
+		{"reserved", &daikinv1.DaikinConfig{Accounts: []*daikinv1.DaikinAccount{{Name: "default", BootstrapFile: "/x"}}}, "reserved"},
+		{"duplicate", &daikinv1.DaikinConfig{Accounts: []*daikinv1.DaikinAccount{{Name: "a", BootstrapFile: "/x"}, {Name: "a", BootstrapFile: "/y"}}}, "declared twice"},
+		{"no bootstrap", &daikinv1.DaikinConfig{Accounts: []*daikinv1.DaikinAccount{{Name: "a"}}}, "bootstrap_file is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ConfigsFromProto(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -4,15 +4,18 @@ import (
 	"context"
 	"time"
 
+	"github.com/joshp123/gohome/internal/rate"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-// MetricsCollector collects Daikin unit health metrics.
+// MetricsCollector collects Daikin unit health metrics for every account.
+// Each series carries an account label ("default" for the top-level
+// bootstrap_file).
 type MetricsCollector struct {
-	client *Client
+	clients []*Client
 
 	cloudUp *prometheus.GaugeVec
-	success prometheus.Gauge
+	success *prometheus.GaugeVec
 
 	onOffMode       *prometheus.GaugeVec
 	operationMode   *prometheus.GaugeVec
@@ -26,26 +29,28 @@ type MetricsCollector struct {
 	holidayMode     *prometheus.GaugeVec
 	rateLimitLimit  *prometheus.GaugeVec
 	rateLimitRemain *prometheus.GaugeVec
-	rateRetryAfter  prometheus.Gauge
-	rateResetAfter  prometheus.Gauge
-	rateLastStatus  prometheus.Gauge
+	rateRetryAfter  *prometheus.GaugeVec
+	rateResetAfter  *prometheus.GaugeVec
+	rateLastStatus  *prometheus.GaugeVec
 }
 
-func NewMetricsCollector(client *Client) *MetricsCollector {
-	labels := []string{"unit_id", "unit_name"}
-	modeLabels := []string{"unit_id", "unit_name", "embedded_id", "mode"}
-	embeddedLabels := []string{"unit_id", "unit_name", "embedded_id"}
-	setpointLabels := []string{"unit_id", "unit_name", "embedded_id", "operation_mode", "setpoint"}
+func NewMetricsCollector(clients ...*Client) *MetricsCollector {
+	labels := []string{"account", "unit_id", "unit_name"}
+	modeLabels := []string{"account", "unit_id", "unit_name", "embedded_id", "mode"}
+	embeddedLabels := []string{"account", "unit_id", "unit_name", "embedded_id"}
+	setpointLabels := []string{"account", "unit_id", "unit_name", "embedded_id", "operation_mode", "setpoint"}
+	windowLabels := []string{"account", "window"}
+	accountLabels := []string{"account"}
 	return &MetricsCollector{
-		client: client,
+		clients: clients,
 		cloudUp: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "gohome_daikin_cloud_connected",
 			Help: "Whether the unit reports cloud connectivity (1=up, 0=down)",
 		}, labels),
-		success: prometheus.NewGauge(prometheus.GaugeOpts{
+		success: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "gohome_daikin_scrape_success",
 			Help: "Last scrape success (1=ok, 0=error)",
-		}),
+		}, accountLabels),
 		onOffMode: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "gohome_daikin_on_off",
 			Help: "On/off mode for the management point (1=on, 0=off)",
@@ -89,23 +94,23 @@ func NewMetricsCollector(client *Client) *MetricsCollector {
 		rateLimitLimit: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "gohome_daikin_rate_limit",
 			Help: "Daikin rate limit ceilings",
-		}, []string{"window"}),
+		}, windowLabels),
 		rateLimitRemain: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "gohome_daikin_rate_limit_remaining",
 			Help: "Daikin remaining requests for the window",
-		}, []string{"window"}),
-		rateRetryAfter: prometheus.NewGauge(prometheus.GaugeOpts{
+		}, windowLabels),
+		rateRetryAfter: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "gohome_daikin_rate_limit_retry_after_seconds",
 			Help: "Retry-after seconds returned by the Daikin API",
-		}),
-		rateResetAfter: prometheus.NewGauge(prometheus.GaugeOpts{
+		}, accountLabels),
+		rateResetAfter: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "gohome_daikin_rate_limit_reset_after_seconds",
 			Help: "Rate limit reset time (seconds) returned by the Daikin API",
-		}),
-		rateLastStatus: prometheus.NewGauge(prometheus.GaugeOpts{
+		}, accountLabels),
+		rateLastStatus: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "gohome_daikin_last_status_code",
 			Help: "Last HTTP status code returned by the Daikin API",
-		}),
+		}, accountLabels),
 	}
 }
 
@@ -130,55 +135,43 @@ func (c *MetricsCollector) Describe(ch chan<- *prometheus.Desc) {
 }
 
 func (c *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(rate.WithPriority(context.Background(), rate.Background), 10*time.Second)
 	defer cancel()
 
-	states, err := c.client.DeviceStates(ctx)
+	for _, client := range c.clients {
+		c.collectAccount(ctx, client)
+	}
+	c.collectAll(ch)
+}
+
+// collectAccount refreshes one account's series. On error the account's
+// previous unit values are kept and only its success gauge drops to 0.
+func (c *MetricsCollector) collectAccount(ctx context.Context, client *Client) {
+	account := client.Account()
+	states, err := client.DeviceStates(ctx)
 	if err != nil {
-		c.success.Set(0)
-		limits := c.client.RateLimits()
-		c.rateLimitLimit.WithLabelValues("minute").Set(float64(limits.Minute))
-		c.rateLimitLimit.WithLabelValues("day").Set(float64(limits.Day))
-		c.rateLimitRemain.WithLabelValues("minute").Set(float64(limits.RemainingMinute))
-		c.rateLimitRemain.WithLabelValues("day").Set(float64(limits.RemainingDay))
-		c.rateRetryAfter.Set(float64(limits.RetryAfter))
-		c.rateResetAfter.Set(float64(limits.ResetAfter))
-		c.rateLastStatus.Set(float64(limits.LastStatusCode))
-		c.cloudUp.Collect(ch)
-		c.onOffMode.Collect(ch)
-		c.operationMode.Collect(ch)
-		c.roomTemp.Collect(ch)
-		c.outdoorTemp.Collect(ch)
-		c.roomHumidity.Collect(ch)
-		c.setpoint.Collect(ch)
-		c.errorState.Collect(ch)
-		c.warningState.Collect(ch)
-		c.cautionState.Collect(ch)
-		c.holidayMode.Collect(ch)
-		c.rateLimitLimit.Collect(ch)
-		c.rateLimitRemain.Collect(ch)
-		c.rateRetryAfter.Collect(ch)
-		c.rateResetAfter.Collect(ch)
-		c.rateLastStatus.Collect(ch)
-		c.success.Collect(ch)
+		c.success.WithLabelValues(account).Set(0)
+		c.setRateLimits(client)
 		return
 	}
 
-	c.cloudUp.Reset()
-	c.onOffMode.Reset()
-	c.operationMode.Reset()
-	c.roomTemp.Reset()
-	c.outdoorTemp.Reset()
-	c.roomHumidity.Reset()
-	c.setpoint.Reset()
-	c.errorState.Reset()
-	c.warningState.Reset()
-	c.cautionState.Reset()
-	c.holidayMode.Reset()
+	match := prometheus.Labels{"account": account}
+	c.cloudUp.DeletePartialMatch(match)
+	c.onOffMode.DeletePartialMatch(match)
+	c.operationMode.DeletePartialMatch(match)
+	c.roomTemp.DeletePartialMatch(match)
+	c.outdoorTemp.DeletePartialMatch(match)
+	c.roomHumidity.DeletePartialMatch(match)
+	c.setpoint.DeletePartialMatch(match)
+	c.errorState.DeletePartialMatch(match)
+	c.warningState.DeletePartialMatch(match)
+	c.cautionState.DeletePartialMatch(match)
+	c.holidayMode.DeletePartialMatch(match)
 
 	for _, state := range states {
 		device := state.Device
 		labels := prometheus.Labels{
+			"account":   account,
 			"unit_id":   device.ID,
 			"unit_name": device.Name,
 		}
@@ -194,6 +187,7 @@ func (c *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
 			}
 
 			mpLabels := prometheus.Labels{
+				"account":     account,
 				"unit_id":     device.ID,
 				"unit_name":   device.Name,
 				"embedded_id": mp.EmbeddedID,
@@ -209,6 +203,7 @@ func (c *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
 
 			if mp.OperationMode != nil {
 				modeLabels := prometheus.Labels{
+					"account":     account,
 					"unit_id":     device.ID,
 					"unit_name":   device.Name,
 					"embedded_id": mp.EmbeddedID,
@@ -233,6 +228,7 @@ func (c *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
 				for opMode, opData := range mp.TemperatureControl.Value.OperationModes {
 					for setpointName, setpoint := range opData.Setpoints {
 						setpointLabels := prometheus.Labels{
+							"account":        account,
 							"unit_id":        device.ID,
 							"unit_name":      device.Name,
 							"embedded_id":    mp.EmbeddedID,
@@ -259,15 +255,23 @@ func (c *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
 		}
 	}
 
-	c.success.Set(1)
-	limits := c.client.RateLimits()
-	c.rateLimitLimit.WithLabelValues("minute").Set(float64(limits.Minute))
-	c.rateLimitLimit.WithLabelValues("day").Set(float64(limits.Day))
-	c.rateLimitRemain.WithLabelValues("minute").Set(float64(limits.RemainingMinute))
-	c.rateLimitRemain.WithLabelValues("day").Set(float64(limits.RemainingDay))
-	c.rateRetryAfter.Set(float64(limits.RetryAfter))
-	c.rateResetAfter.Set(float64(limits.ResetAfter))
-	c.rateLastStatus.Set(float64(limits.LastStatusCode))
+	c.success.WithLabelValues(account).Set(1)
+	c.setRateLimits(client)
+}
+
+func (c *MetricsCollector) setRateLimits(client *Client) {
+	account := client.Account()
+	limits := client.RateLimits()
+	c.rateLimitLimit.WithLabelValues(account, "minute").Set(float64(limits.Minute))
+	c.rateLimitLimit.WithLabelValues(account, "day").Set(float64(limits.Day))
+	c.rateLimitRemain.WithLabelValues(account, "minute").Set(float64(limits.RemainingMinute))
+	c.rateLimitRemain.WithLabelValues(account, "day").Set(float64(limits.RemainingDay))
+	c.rateRetryAfter.WithLabelValues(account).Set(float64(limits.RetryAfter))
+	c.rateResetAfter.WithLabelValues(account).Set(float64(limits.ResetAfter))
+	c.rateLastStatus.WithLabelValues(account).Set(float64(limits.LastStatusCode))
+}
+
+func (c *MetricsCollector) collectAll(ch chan<- prometheus.Metric) {
 	c.cloudUp.Collect(ch)
 	c.onOffMode.Collect(ch)
 	c.operationMode.Collect(ch)
@@ -15,13 +15,26 @@ in
       default = null;
       description = "Path to Daikin Onecta OAuth credentials bootstrap JSON";
     };
+
+    accounts = mkOption {
+      type = types.attrsOf (types.submodule {
+        options = {
+          bootstrapFile = mkOption {
+            type = types.path;
+            description = "Path to Daikin Onecta OAuth credentials bootstrap JSON for this account";
+          };
+        };
+      });
+      default = { };
+      description = "Additional named Daikin accounts";
+    };
   };
 
   config = mkIf cfg.enable {
     assertions = [
       {
-        assertion = cfg.bootstrapFile != null;
-        message = "services.gohome.plugins.daikin.bootstrapFile is required";
+        assertion = cfg.bootstrapFile != null || cfg.accounts != { };
+        message = "services.gohome.plugins.daikin.bootstrapFile or accounts is required";
       }
     ];
   };
@@ -1,7 +1,12 @@
 package daikin
 
 import (
+	"context"
 	_ "embed"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/joshp123/gohome/internal/core"
@@ -21,32 +26,53 @@ var dashboardJSON []byte
 
 // Plugin implements the GoHome plugin contract.
 type Plugin struct {
-	client        *Client
+	clients       []*Client
 	health        core.HealthStatus
 	healthMessage string
 }
 
 var _ rate.RateLimited = (*Plugin)(nil)
+var _ core.Lifecycle = Plugin{}
 
-// NewPlugin constructs a Daikin plugin from config.
+// NewPlugin constructs a Daikin plugin from config, with one client per
+// account. Accounts that fail to start are reported in the health message;
+// the plugin is degraded while at least one account works.
 func NewPlugin(cfg *daikinv1.DaikinConfig, oauthCfg *configv1.OAuthConfig) (Plugin, bool) {
 	if cfg == nil {
 		return Plugin{}, false
 	}
 
-	runtimeCfg, err := ConfigFromProto(cfg)
+	configs, err := ConfigsFromProto(cfg)
 	if err != nil {
 		return Plugin{health: core.HealthError, healthMessage: err.Error()}, true
 	}
 
 	decl := Plugin{}.OAuthDeclaration()
-	rateDecl := Plugin{}.RateLimits()
-	client, err := NewClient(runtimeCfg, decl, rateDecl, oauthCfg)
-	if err != nil {
-		return Plugin{health: core.HealthError, healthMessage: err.Error()}, true
+	httpClient := rate.WrapHTTP(Plugin{}.RateLimits(), &http.Client{Timeout: 15 * time.Second})
+	var clients []*Client
+	var failures []string
+	for _, runtimeCfg := range configs {
+		client, err := newClient(runtimeCfg, decl, oauthCfg, httpClient)
+		if err != nil {
+			if len(configs) > 1 {
+				err = fmt.Errorf("account %s: %w", runtimeCfg.AccountName(), err)
+			}
+			failures = append(failures, err.Error())
+			continue
+		}
+		clients = append(clients, client)
 	}
 
-	return Plugin{client: client, health: core.HealthHealthy}, true
+	switch {
+	case len(failures) == 0:
+		return Plugin{clients: clients, health: core.HealthHealthy}, true
+	case len(clients) == 0:
+		// No client owns the shared guard, so Stop would never release it.
+		_ = rate.Release(context.Background(), httpClient)
+		return Plugin{health: core.HealthError, healthMessage: strings.Join(failures, "; ")}, true
+	default:
+		return Plugin{clients: clients, health: core.HealthDegraded, healthMessage: strings.Join(failures, "; ")}, true
+	}
 }
 
 func (p Plugin) ID() string {
@@ -81,29 +107,62 @@ func (p Plugin) RateLimits() rate.Declaration {
 	return rate.Provider("daikin").
 		MaxRequestsPer(rate.Minute, 20).
 		MaxRequestsPer(rate.Day, 200).
+		PriorityFloor(rate.Background, rate.Day, 30).
 		CacheFor(10 * time.Minute).
+		StaleWhileRevalidate().
+		Retry(rate.DefaultRetry()).
+		Breaker(rate.DefaultBreaker()).
 		ReadHeaders(rate.StandardHeaders())
 }
 
+func (p Plugin) Start(ctx context.Context) error {
+	for _, client := range p.clients {
+		client.Start(ctx)
+	}
+	return nil
+}
+
+func (p Plugin) Stop(ctx context.Context) error {
+	var errs []error
+	for _, client := range p.clients {
+		if err := client.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", client.Account(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (p Plugin) Dashboards() []core.Dashboard {
 	return []core.Dashboard{{Name: "daikin-overview", JSON: dashboardJSON}}
 }
 
 func (p Plugin) RegisterGRPC(server *grpc.Server) {
-	RegisterDaikinService(server, p.client)
+	RegisterDaikinService(server, p.clients...)
 }
 
 func (p Plugin) Collectors() []prometheus.Collector {
-	if p.client == nil {
+	if len(p.clients) == 0 {
 		return nil
 	}
-	return []prometheus.Collector{NewMetricsCollector(p.client)}
+	return []prometheus.Collector{NewMetricsCollector(p.clients...)}
 }
 
 func (p Plugin) Health() core.HealthStatus {
+	if oauth.ReauthMessage("daikin") != "" {
+		return core.HealthError
+	}
+	if p.health == core.HealthHealthy && rate.Circuit("daikin").State != rate.BreakerClosed {
+		return core.HealthDegraded
+	}
 	return p.health
 }
 
 func (p Plugin) HealthMessage() string {
+	if msg := oauth.ReauthMessage("daikin"); msg != "" {
+		return msg
+	}
+	if p.healthMessage == "" {
+		return rate.Circuit("daikin").Message()
+	}
 	return p.healthMessage
 }
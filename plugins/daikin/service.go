@@ -2,6 +2,7 @@ package daikin
 
 import (
 	context "context"
+	"strings"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -12,45 +13,54 @@ import (
 
 type service struct {
 	daikinv1.UnimplementedDaikinServiceServer
-	client *Client
+	clients []*Client
 }
 
-func RegisterDaikinService(server *grpc.Server, client *Client) {
-	daikinv1.RegisterDaikinServiceServer(server, &service{client: client})
+// RegisterDaikinService serves every configured account; units carry the
+// account they belong to.
+func RegisterDaikinService(server *grpc.Server, clients ...*Client) {
+	daikinv1.RegisterDaikinServiceServer(server, &service{clients: clients})
 }
 
 func (s *service) ListUnits(ctx context.Context, _ *daikinv1.ListUnitsRequest) (*daikinv1.ListUnitsResponse, error) {
-	if s.client == nil {
+	if len(s.clients) == 0 {
 		return nil, status.Error(codes.FailedPrecondition, "daikin client not configured")
 	}
 
-	devices, err := s.client.Devices(ctx)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "list units: %v", err)
-	}
-
 	resp := &daikinv1.ListUnitsResponse{}
-	for _, device := range devices {
-		resp.Units = append(resp.Units, &daikinv1.Unit{
-			Id:               device.ID,
-			Name:             device.Name,
-			Model:            device.Model,
-			ClimateControlId: device.ClimateControlID,
-		})
+	for _, client := range s.clients {
+		devices, err := client.Devices(ctx)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "list units (account %s): %v", client.Account(), err)
+		}
+		for _, device := range devices {
+			resp.Units = append(resp.Units, &daikinv1.Unit{
+				Id:               device.ID,
+				Name:             device.Name,
+				Model:            device.Model,
+				ClimateControlId: device.ClimateControlID,
+				Account:          client.Account(),
+			})
+		}
 	}
 
 	return resp, nil
 }
 
 func (s *service) GetUnitState(ctx context.Context, req *daikinv1.GetUnitStateRequest) (*daikinv1.GetUnitStateResponse, error) {
-	if s.client == nil {
+	if len(s.clients) == 0 {
 		return nil, status.Error(codes.FailedPrecondition, "daikin client not configured")
 	}
 	if req.GetUnitId() == "" {
 		return nil, status.Error(codes.InvalidArgument, "unit_id is required")
 	}
 
-	payload, err := s.client.DeviceStateJSON(ctx, req.UnitId)
+	client, err := s.client(req.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := client.DeviceStateJSON(ctx, req.UnitId)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "get unit state: %v", err)
 	}
@@ -59,7 +69,7 @@ func (s *service) GetUnitState(ctx context.Context, req *daikinv1.GetUnitStateRe
 }
 
 func (s *service) SetOnOff(ctx context.Context, req *daikinv1.SetOnOffRequest) (*daikinv1.SetOnOffResponse, error) {
-	if s.client == nil {
+	if len(s.clients) == 0 {
 		return nil, status.Error(codes.FailedPrecondition, "daikin client not configured")
 	}
 	if req.GetUnitId() == "" {
@@ -69,12 +79,17 @@ func (s *service) SetOnOff(ctx context.Context, req *daikinv1.SetOnOffRequest) (
 		return nil, status.Error(codes.InvalidArgument, "on_off_mode is required")
 	}
 
-	embeddedID, err := s.resolveEmbeddedID(ctx, req.UnitId, req.ClimateControlId)
+	client, err := s.client(req.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	embeddedID, err := s.resolveEmbeddedID(ctx, client, req.UnitId, req.ClimateControlId)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := s.client.SetOnOff(ctx, req.UnitId, embeddedID, req.OnOffMode); err != nil {
+	if err := client.SetOnOff(ctx, req.UnitId, embeddedID, req.OnOffMode); err != nil {
 		return nil, status.Errorf(codes.Internal, "set on/off: %v", err)
 	}
 
@@ -82,7 +97,7 @@ func (s *service) SetOnOff(ctx context.Context, req *daikinv1.SetOnOffRequest) (
 }
 
 func (s *service) SetOperationMode(ctx context.Context, req *daikinv1.SetOperationModeRequest) (*daikinv1.SetOperationModeResponse, error) {
-	if s.client == nil {
+	if len(s.clients) == 0 {
 		return nil, status.Error(codes.FailedPrecondition, "daikin client not configured")
 	}
 	if req.GetUnitId() == "" {
@@ -92,12 +107,17 @@ func (s *service) SetOperationMode(ctx context.Context, req *daikinv1.SetOperati
 		return nil, status.Error(codes.InvalidArgument, "operation_mode is required")
 	}
 
-	embeddedID, err := s.resolveEmbeddedID(ctx, req.UnitId, req.ClimateControlId)
+	client, err := s.client(req.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	embeddedID, err := s.resolveEmbeddedID(ctx, client, req.UnitId, req.ClimateControlId)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := s.client.SetOperationMode(ctx, req.UnitId, embeddedID, req.OperationMode); err != nil {
+	if err := client.SetOperationMode(ctx, req.UnitId, embeddedID, req.OperationMode); err != nil {
 		return nil, status.Errorf(codes.Internal, "set operation mode: %v", err)
 	}
 
@@ -105,7 +125,7 @@ func (s *service) SetOperationMode(ctx context.Context, req *daikinv1.SetOperati
 }
 
 func (s *service) SetTemperature(ctx context.Context, req *daikinv1.SetTemperatureRequest) (*daikinv1.SetTemperatureResponse, error) {
-	if s.client == nil {
+	if len(s.clients) == 0 {
 		return nil, status.Error(codes.FailedPrecondition, "daikin client not configured")
 	}
 	if req.GetUnitId() == "" {
@@ -118,26 +138,56 @@ func (s *service) SetTemperature(ctx context.Context, req *daikinv1.SetTemperatu
 		return nil, status.Error(codes.InvalidArgument, "setpoint is required")
 	}
 
-	embeddedID, err := s.resolveEmbeddedID(ctx, req.UnitId, req.ClimateControlId)
+	client, err := s.client(req.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	embeddedID, err := s.resolveEmbeddedID(ctx, client, req.UnitId, req.ClimateControlId)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := s.client.SetTemperature(ctx, req.UnitId, embeddedID, req.OperationMode, req.Setpoint, req.TemperatureCelsius); err != nil {
+	if err := client.SetTemperature(ctx, req.UnitId, embeddedID, req.OperationMode, req.Setpoint, req.TemperatureCelsius); err != nil {
 		return nil, status.Errorf(codes.Internal, "set temperature: %v", err)
 	}
 
 	return &daikinv1.SetTemperatureResponse{}, nil
 }
 
-func (s *service) resolveEmbeddedID(ctx context.Context, unitID, provided string) (string, error) {
+func (s *service) resolveEmbeddedID(ctx context.Context, client *Client, unitID, provided string) (string, error) {
 	if provided != "" {
 		return provided, nil
 	}
 
-	embeddedID, err := s.client.resolveClimateControlID(ctx, unitID)
+	embeddedID, err := client.resolveClimateControlID(ctx, unitID)
 	if err != nil {
 		return "", status.Errorf(codes.InvalidArgument, "resolve climate_control_id: %v", err)
 	}
 	return embeddedID, nil
 }
+
+// client resolves a request's account. It may be omitted when only one
+// account is configured.
+func (s *service) client(account string) (*Client, error) {
+	if account == "" {
+		if len(s.clients) == 1 {
+			return s.clients[0], nil
+		}
+		return nil, status.Errorf(codes.InvalidArgument, "account is required (one of %s)", strings.Join(s.accounts(), ", "))
+	}
+	for _, client := range s.clients {
+		if client.Account() == account {
+			return client, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "unknown account %q (have %s)", account, strings.Join(s.accounts(), ", "))
+}
+
+func (s *service) accounts() []string {
+	names := make([]string, 0, len(s.clients))
+	for _, client := range s.clients {
+		names = append(names, client.Account())
+	}
+	return names
+}
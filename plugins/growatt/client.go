@@ -14,14 +14,14 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/joshp123/gohome/internal/rate"
 )
 
 const (
-	apiPrefix        = "v1/"
-	apiV4Prefix      = "v4/"
-	timeLayout       = "2006-01-02 15:04:05"
-	rateLimitRetries = 4
-	rateLimitBackoff = 20 * time.Second
+	apiPrefix   = "v1/"
+	apiV4Prefix = "v4/"
+	timeLayout  = "2006-01-02 15:04:05"
 )
 
 // APIError surfaces Growatt error codes.
@@ -37,11 +37,34 @@ func (e APIError) Error() string {
 func isRateLimit(err error) bool {
 	var apiErr APIError
 	if errors.As(err, &apiErr) {
-		return apiErr.Code == 10012 || apiErr.Code == 100 || apiErr.Code == 102
+		return isRateLimitCode(apiErr.Code)
 	}
 	return false
 }
 
+func isRateLimitCode(code int) bool {
+	return code == 10012 || code == 100 || code == 102
+}
+
+// rateLimitedResponse reports whether a successful HTTP response carries a
+// Growatt rate-limit error code; the guard retries those.
+func rateLimitedResponse(resp *http.Response, body []byte) bool {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return false
+	}
+	var wrapper struct {
+		ErrorCode int  `json:"error_code"`
+		Code      *int `json:"code"`
+	}
+	if err := json.Unmarshal(body, &wrapper); err != nil {
+		return false
+	}
+	if isRateLimitCode(wrapper.ErrorCode) {
+		return true
+	}
+	return wrapper.Code != nil && isRateLimitCode(*wrapper.Code)
+}
+
 // Client talks to the Growatt OpenAPI.
 type Client struct {
 	baseURL      string
@@ -73,7 +96,7 @@ func NewClient(cfg Config) (*Client, error) {
 	return &Client{
 		baseURL: baseURL,
 		token:   token,
-		http:    &http.Client{Timeout: 15 * time.Second},
+		http:    rate.WrapHTTP(Plugin{}.RateLimits(), &http.Client{Timeout: 15 * time.Second}),
 		plantID: cfg.PlantID,
 	}, nil
 }
@@ -191,22 +214,6 @@ func (c *Client) postJSON(ctx context.Context, path string, params map[string]st
 }
 
 func (c *Client) doJSON(ctx context.Context, method, prefix, path string, params map[string]string, out any) error {
-	for attempt := 0; attempt <= rateLimitRetries; attempt++ {
-		err := c.doJSONOnce(ctx, method, prefix, path, params, out)
-		if err == nil {
-			return nil
-		}
-		if !isRateLimit(err) || attempt == rateLimitRetries {
-			return err
-		}
-		if sleepErr := sleepWithContext(ctx, rateLimitBackoff*time.Duration(attempt+1)); sleepErr != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-func (c *Client) doJSONOnce(ctx context.Context, method, prefix, path string, params map[string]string, out any) error {
 	endpoint := c.baseURL + prefix + strings.TrimPrefix(path, "/")
 	reqURL, err := url.Parse(endpoint)
 	if err != nil {
@@ -66,3 +66,23 @@ func TestResolvePlantUsesCachedConfiguredPlant(t *testing.T) {
 		t.Fatalf("plant = %+v", plant)
 	}
 }
+
+func TestRateLimitedResponse(t *testing.T) {
+	cases := []struct {
+		status int
+		body   string
+		want   bool
+	}{
+		{http.StatusOK, `{"error_code":10012,"error_msg":"error_frequently_access"}`, true},
+		{http.StatusOK, `{"code":102,"message":"frequently"}`, true},
+		{http.StatusOK, `{"error_code":0,"data":{}}`, false},
+		{http.StatusOK, `{"code":10011,"message":"permission denied"}`, false},
+		{http.StatusOK, `not json`, false},
+		{http.StatusInternalServerError, `{"error_code":10012}`, false},
+	}
+	for _, tc := range cases {
+		if got := rateLimitedResponse(&http.Response{StatusCode: tc.status}, []byte(tc.body)); got != tc.want {
+			t.Errorf("rateLimitedResponse(%d, %s) = %v, want %v", tc.status, tc.body, got, tc.want)
+		}
+	}
+}
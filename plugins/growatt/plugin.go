@@ -1,10 +1,13 @@
 package growatt
 
 import (
+	"context"
 	_ "embed"
+	"time"
 
 	"github.com/joshp123/gohome/internal/core"
 	"github.com/joshp123/gohome/internal/oauth"
+	"github.com/joshp123/gohome/internal/rate"
 	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
 	growattv1 "github.com/joshp123/gohome/proto/gen/plugins/growatt/v1"
 	"github.com/prometheus/client_golang/prometheus"
@@ -17,6 +20,9 @@ var agentsMD string
 //go:embed dashboard.json
 var dashboardJSON []byte
 
+var _ rate.RateLimited = (*Plugin)(nil)
+var _ core.Lifecycle = Plugin{}
+
 // Plugin implements the GoHome plugin contract.
 type Plugin struct {
 	client        *Client
@@ -64,6 +70,19 @@ func (p Plugin) OAuthDeclaration() oauth.Declaration {
 	return oauth.Declaration{Provider: "growatt"}
 }
 
+// Start is a no-op; Growatt is polled at scrape time.
+func (p Plugin) Start(context.Context) error {
+	return nil
+}
+
+// Stop releases the client's rate guard.
+func (p Plugin) Stop(ctx context.Context) error {
+	if p.client == nil {
+		return nil
+	}
+	return rate.Release(ctx, p.client.http)
+}
+
 func (p Plugin) Dashboards() []core.Dashboard {
 	return []core.Dashboard{{Name: "growatt-overview", JSON: dashboardJSON}}
 }
@@ -80,9 +99,32 @@ func (p Plugin) Collectors() []prometheus.Collector {
 }
 
 func (p Plugin) Health() core.HealthStatus {
+	if p.health == core.HealthHealthy && rate.Circuit("growatt").State != rate.BreakerClosed {
+		return core.HealthDegraded
+	}
 	return p.health
 }
 
 func (p Plugin) HealthMessage() string {
+	if p.healthMessage == "" {
+		return rate.Circuit("growatt").Message()
+	}
 	return p.healthMessage
 }
+
+// RateLimits enforces no budget. Growatt signals throttling with an error
+// code in a 200 response, so retries classify the body; the v4 POST
+// endpoints are queries and safe to repeat. A circuit breaker makes calls
+// fail fast while the cloud is down.
+func (p Plugin) RateLimits() rate.Declaration {
+	return rate.Provider("growatt").
+		Unmetered().
+		Retry(rate.RetryPolicy{
+			MaxAttempts:     5,
+			BaseDelay:       20 * time.Second,
+			MaxDelay:        80 * time.Second,
+			RetryOnResponse: rateLimitedResponse,
+			NonIdempotent:   true,
+		}).
+		Breaker(rate.DefaultBreaker())
+}
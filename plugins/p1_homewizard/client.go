@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -16,23 +17,52 @@ const (
 )
 
 type Client struct {
-	baseURL    string
 	httpClient *http.Client
+
+	mu      sync.RWMutex
+	baseURL string
+	tariffs Tariffs
 }
 
 func NewClient(cfg Config) (*Client, error) {
 	if strings.TrimSpace(cfg.BaseURL) == "" {
 		return nil, fmt.Errorf("p1_homewizard base_url is required")
 	}
-	baseURL := strings.TrimRight(cfg.BaseURL, "/")
 	return &Client{
-		baseURL: baseURL,
+		baseURL: strings.TrimRight(cfg.BaseURL, "/"),
+		tariffs: cfg.Tariffs,
 		httpClient: &http.Client{
 			Timeout: requestTimeout,
 		},
 	}, nil
 }
 
+// Reconfigure swaps the meter address and tariffs; in-flight requests finish
+// against the previous address.
+func (c *Client) Reconfigure(cfg Config) error {
+	if strings.TrimSpace(cfg.BaseURL) == "" {
+		return fmt.Errorf("p1_homewizard base_url is required")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.baseURL = strings.TrimRight(cfg.BaseURL, "/")
+	c.tariffs = cfg.Tariffs
+	return nil
+}
+
+// Tariffs returns the configured cost rates.
+func (c *Client) Tariffs() Tariffs {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tariffs
+}
+
+func (c *Client) currentBaseURL() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.baseURL
+}
+
 func (c *Client) Info(ctx context.Context) (Info, error) {
 	var info Info
 	if err := c.getJSON(ctx, "/api", &info); err != nil {
@@ -73,7 +103,7 @@ func (c *Client) getJSON(ctx context.Context, path string, dest any) error {
 }
 
 func (c *Client) getBytes(ctx context.Context, path string) ([]byte, error) {
-	endpoint, err := url.JoinPath(c.baseURL, path)
+	endpoint, err := url.JoinPath(c.currentBaseURL(), path)
 	if err != nil {
 		return nil, fmt.Errorf("build url: %w", err)
 	}
@@ -10,8 +10,7 @@ import (
 
 // MetricsCollector collects P1 Homewizard power metrics.
 type MetricsCollector struct {
-	client  *Client
-	tariffs Tariffs
+	client *Client
 
 	activePowerW        prometheus.Gauge
 	activePowerL1W      prometheus.Gauge
@@ -46,10 +45,9 @@ type MetricsCollector struct {
 	telegramSuccess prometheus.Gauge
 }
 
-func NewMetricsCollector(client *Client, tariffs Tariffs) *MetricsCollector {
+func NewMetricsCollector(client *Client) *MetricsCollector {
 	return &MetricsCollector{
-		client:  client,
-		tariffs: tariffs,
+		client: client,
 		activePowerW: prometheus.NewGauge(prometheus.GaugeOpts{
 			Name: "gohome_p1_homewizard_active_power_w",
 			Help: "Active power (net) in watts",
@@ -232,14 +230,15 @@ func (c *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
 	setGauge(c.anyPowerFailCount, data.AnyPowerFailCount)
 	setGauge(c.longPowerFailCount, data.LongPowerFailCount)
 
-	c.tariffImportT1.Set(c.tariffs.ImportT1EurPerKWh)
-	c.tariffImportT2.Set(c.tariffs.ImportT2EurPerKWh)
-	c.tariffExportT1.Set(c.tariffs.ExportT1EurPerKWh)
-	c.tariffExportT2.Set(c.tariffs.ExportT2EurPerKWh)
+	tariffs := c.client.Tariffs()
+	c.tariffImportT1.Set(tariffs.ImportT1EurPerKWh)
+	c.tariffImportT2.Set(tariffs.ImportT2EurPerKWh)
+	c.tariffExportT1.Set(tariffs.ExportT1EurPerKWh)
+	c.tariffExportT2.Set(tariffs.ExportT2EurPerKWh)
 
-	if c.tariffs.Configured() {
+	if tariffs.Configured() {
 		c.costConfigured.Set(1)
-		importCost, exportCredit, totalCost := costBreakdown(data, c.tariffs)
+		importCost, exportCredit, totalCost := costBreakdown(data, tariffs)
 		c.importCostEUR.Set(importCost)
 		c.exportCreditEUR.Set(exportCredit)
 		c.totalCostEUR.Set(totalCost)
@@ -2,6 +2,7 @@ package p1_homewizard
 
 import (
 	_ "embed"
+	"fmt"
 
 	"github.com/joshp123/gohome/internal/core"
 	"github.com/joshp123/gohome/internal/oauth"
@@ -17,10 +18,11 @@ var agentsMD string
 //go:embed dashboard.json
 var dashboardJSON []byte
 
+var _ core.Reconfigurable = Plugin{}
+
 // Plugin implements the GoHome plugin contract.
 type Plugin struct {
 	client        *Client
-	tariffs       Tariffs
 	health        core.HealthStatus
 	healthMessage string
 }
@@ -41,7 +43,7 @@ func NewPlugin(cfg *p1v1.P1HomewizardConfig, _ *configv1.OAuthConfig) (Plugin, b
 		return Plugin{health: core.HealthError, healthMessage: err.Error()}, true
 	}
 
-	return Plugin{client: client, tariffs: runtimeCfg.Tariffs, health: core.HealthHealthy}, true
+	return Plugin{client: client, health: core.HealthHealthy}, true
 }
 
 func (p Plugin) ID() string {
@@ -65,6 +67,21 @@ func (p Plugin) OAuthDeclaration() oauth.Declaration {
 	return oauth.Declaration{}
 }
 
+// Reconfigure applies a new base_url and tariffs without restarting.
+func (p Plugin) Reconfigure(cfg *configv1.Config) error {
+	if p.client == nil {
+		return fmt.Errorf("p1_homewizard failed to start; restart required")
+	}
+	if cfg.GetP1Homewizard() == nil {
+		return fmt.Errorf("p1_homewizard config missing")
+	}
+	runtimeCfg, err := ConfigFromProto(cfg.GetP1Homewizard())
+	if err != nil {
+		return err
+	}
+	return p.client.Reconfigure(runtimeCfg)
+}
+
 func (p Plugin) Dashboards() []core.Dashboard {
 	return []core.Dashboard{{Name: "p1-homewizard-overview", JSON: dashboardJSON}}
 }
@@ -77,7 +94,7 @@ func (p Plugin) Collectors() []prometheus.Collector {
 	if p.client == nil {
 		return nil
 	}
-	return []prometheus.Collector{NewMetricsCollector(p.client, p.tariffs)}
+	return []prometheus.Collector{NewMetricsCollector(p.client)}
 }
 
 func (p Plugin) Health() core.HealthStatus {
@@ -39,6 +39,8 @@ type Client struct {
 	mapCache        map[string]mapSnapshot
 	defaultProfiles map[string]profileCache
 	traceCache      map[string]traceSnapshot
+	keepaliveCancel context.CancelFunc
+	keepaliveDone   chan struct{}
 }
 
 func LoadBootstrap(path string) (BootstrapState, error) {
@@ -96,6 +98,77 @@ func NewClient(cfg Config) (*Client, error) {
 	}, nil
 }
 
+// config returns the current runtime config; Reconfigure may replace it.
+func (c *Client) config() Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cfg
+}
+
+// Reconfigure applies cloud fallback, IP overrides, segment names, and the
+// default clean profile. Changing the bootstrap file requires a restart.
+func (c *Client) Reconfigure(cfg Config) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cfg.BootstrapFile != c.cfg.BootstrapFile {
+		return fmt.Errorf("roborock bootstrap_file change requires a restart")
+	}
+	// Drop cached addresses and connections for devices whose override moved.
+	changed := make(map[string]bool)
+	for deviceID, ip := range c.cfg.IPOverrides {
+		if cfg.IPOverrides[deviceID] != ip {
+			changed[deviceID] = true
+		}
+	}
+	for deviceID, ip := range cfg.IPOverrides {
+		if c.cfg.IPOverrides[deviceID] != ip {
+			changed[deviceID] = true
+		}
+	}
+	for deviceID := range changed {
+		delete(c.ipCache, deviceID)
+		if channel := c.channels[deviceID]; channel != nil {
+			channel.Close()
+			delete(c.channels, deviceID)
+		}
+	}
+	c.cfg = cfg
+	c.overrides = cfg.IPOverrides
+	return nil
+}
+
+// Close stops the keepalive loop and releases local and MQTT connections.
+func (c *Client) Close(ctx context.Context) error {
+	c.mu.Lock()
+	cancel, done := c.keepaliveCancel, c.keepaliveDone
+	c.keepaliveCancel, c.keepaliveDone = nil, nil
+	c.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	c.mu.Lock()
+	channels := c.channels
+	c.channels = make(map[string]*LocalChannel)
+	mc := c.mqtt
+	c.mqtt = nil
+	c.mu.Unlock()
+
+	for _, channel := range channels {
+		channel.Close()
+	}
+	if mc != nil {
+		mc.close()
+	}
+	return nil
+}
+
 func parseUserData(raw json.RawMessage) (*UserData, error) {
 	var data UserData
 	if err := json.Unmarshal(raw, &data); err != nil {
@@ -162,7 +235,7 @@ func (c *Client) Status(ctx context.Context, deviceID string) (Status, error) {
 	}
 	channel, err := c.getLocalChannel(ctx, device)
 	if err != nil {
-		if c.cfg.CloudFallback {
+		if c.config().CloudFallback {
 			return parseStatusFromDeviceStatus(device.DeviceStatus), nil
 		}
 		return Status{}, err
@@ -293,7 +366,7 @@ func (c *Client) DefaultCleanProfile(ctx context.Context, deviceID string) (Clea
 	}
 	source := "schedule"
 	if !ok || !profile.HasAny() {
-		profile = c.cfg.DefaultProfile
+		profile = c.config().DefaultProfile
 		ok = profile.HasAny()
 		source = "config"
 		err = nil
@@ -421,7 +494,7 @@ func (c *Client) simpleCommand(ctx context.Context, deviceID, method string, par
 	}
 	channel, err := c.getLocalChannel(ctx, device)
 	if err != nil {
-		if c.cfg.CloudFallback {
+		if c.config().CloudFallback {
 			return fmt.Errorf("cloud fallback not implemented for command %s", method)
 		}
 		return err
@@ -77,7 +77,7 @@ func (c *Client) mapSnapshot(ctx context.Context, deviceID string, labelMode str
 			trace = nil
 		}
 	}
-	parsed, segments, err := parseMapData(data, device.Name, labelMode, c.cfg.SegmentNames, trace)
+	parsed, segments, err := parseMapData(data, device.Name, labelMode, c.config().SegmentNames, trace)
 	if err != nil {
 		return mapImage{}, nil, err
 	}
@@ -94,6 +94,10 @@ func (c *mqttClient) subscribe(topic string, cb func([]byte)) (func(), error) {
 	}, nil
 }
 
+func (c *mqttClient) close() {
+	c.client.Disconnect(250)
+}
+
 func (c *mqttClient) publish(topic string, payload []byte) error {
 	if token := c.client.Publish(topic, 0, false, payload); token.Wait() && token.Error() != nil {
 		return token.Error()
@@ -3,6 +3,7 @@ package roborock
 import (
 	"context"
 	_ "embed"
+	"fmt"
 	"log"
 	"time"
 
@@ -22,11 +23,15 @@ var dashboardJSON []byte
 
 // Plugin implements the GoHome plugin contract.
 type Plugin struct {
-	client        *Client
-	health        core.HealthStatus
-	healthMessage string
+	client            *Client
+	keepaliveInterval time.Duration
+	health            core.HealthStatus
+	healthMessage     string
 }
 
+var _ core.Lifecycle = Plugin{}
+var _ core.Reconfigurable = Plugin{}
+
 // NewPlugin constructs a Roborock plugin from config.
 func NewPlugin(cfg *roborockv1.RoborockConfig, oauthCfg *configv1.OAuthConfig) (Plugin, bool) {
 	if cfg == nil {
@@ -43,34 +48,71 @@ func NewPlugin(cfg *roborockv1.RoborockConfig, oauthCfg *configv1.OAuthConfig) (
 		return Plugin{health: core.HealthError, healthMessage: err.Error()}, true
 	}
 
-	startRoborockKeepalive(client, oauthCfg)
-
-	return Plugin{client: client, health: core.HealthHealthy}, true
+	return Plugin{
+		client:            client,
+		keepaliveInterval: oauth.RefreshInterval(oauthCfg),
+		health:            core.HealthHealthy,
+	}, true
 }
 
-func startRoborockKeepalive(client *Client, oauthCfg *configv1.OAuthConfig) {
-	if client == nil {
-		return
-	}
-	interval := oauth.RefreshInterval(oauthCfg)
+// startKeepalive periodically refreshes home data until ctx is cancelled or Close is called.
+func (c *Client) startKeepalive(ctx context.Context, interval time.Duration) {
 	if interval <= 0 {
 		return
 	}
+	loopCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	c.mu.Lock()
+	c.keepaliveCancel = cancel
+	c.keepaliveDone = done
+	c.mu.Unlock()
+
 	go func() {
+		defer close(done)
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 		for {
-			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
-			err := client.RefreshHomeData(ctx)
-			cancel()
-			if err != nil {
+			refreshCtx, cancelRefresh := context.WithTimeout(loopCtx, 20*time.Second)
+			err := c.RefreshHomeData(refreshCtx)
+			cancelRefresh()
+			if err != nil && loopCtx.Err() == nil {
 				log.Printf("roborock keepalive refresh failed: %v", err)
 			}
-			<-ticker.C
+			select {
+			case <-loopCtx.Done():
+				return
+			case <-ticker.C:
+			}
 		}
 	}()
 }
 
+func (p Plugin) Start(ctx context.Context) error {
+	if p.client != nil {
+		p.client.startKeepalive(ctx, p.keepaliveInterval)
+	}
+	return nil
+}
+
+func (p Plugin) Stop(ctx context.Context) error {
+	if p.client == nil {
+		return nil
+	}
+	return p.client.Close(ctx)
+}
+
+// Reconfigure applies roborock config changes other than bootstrap_file.
+func (p Plugin) Reconfigure(cfg *configv1.Config) error {
+	if p.client == nil {
+		return fmt.Errorf("roborock failed to start; restart required")
+	}
+	runtimeCfg, err := ConfigFromProto(cfg.GetRoborock())
+	if err != nil {
+		return err
+	}
+	return p.client.Reconfigure(runtimeCfg)
+}
+
 func (p Plugin) ID() string {
 	return "roborock"
 }
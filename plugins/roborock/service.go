@@ -240,14 +240,15 @@ func (s *service) ListRooms(ctx context.Context, req *roborockv1.ListRoomsReques
 	}
 
 	rooms := make([]*roborockv1.Room, 0)
-	if len(s.client.cfg.SegmentNames) > 0 {
-		ids := make([]int, 0, len(s.client.cfg.SegmentNames))
-		for id := range s.client.cfg.SegmentNames {
+	segmentNames := s.client.config().SegmentNames
+	if len(segmentNames) > 0 {
+		ids := make([]int, 0, len(segmentNames))
+		for id := range segmentNames {
 			ids = append(ids, int(id))
 		}
 		sort.Ints(ids)
 		for _, id := range ids {
-			label := s.client.cfg.SegmentNames[uint32(id)]
+			label := segmentNames[uint32(id)]
 			name := canonicalRoomName(label, id)
 			rooms = append(rooms, &roborockv1.Room{
 				Name:      name,
@@ -423,7 +424,7 @@ func (s *service) resolveRoomSegment(ctx context.Context, deviceID, room string)
 	if id, ok := parseRoomSegmentID(normalized); ok {
 		return id, nil
 	}
-	for id, name := range s.client.cfg.SegmentNames {
+	for id, name := range s.client.config().SegmentNames {
 		if normalizeRoomName(name) == normalized {
 			return id, nil
 		}
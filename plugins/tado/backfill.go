@@ -73,7 +73,7 @@ func Backfill(ctx context.Context, client *Client, opts BackfillOptions) error {
 				}
 				return err
 			}
-			buf = append(buf, dayReportSamples(report, zone)...)
+			buf = append(buf, dayReportSamples(report, client.Account(), zone)...)
 			if len(buf) >= opts.BatchSize {
 				if err := importSamples(ctx, opts.ImportURL, buf); err != nil {
 					return err
@@ -115,17 +115,19 @@ func filterZones(zones []Zone, names []string) []Zone {
 	return filtered
 }
 
-func dayReportSamples(report dayReport, zone Zone) []sample {
+func dayReportSamples(report dayReport, account string, zone Zone) []sample {
 	var samples []sample
 	labels := map[string]string{
 		"job":       "gohome",
 		"instance":  "gohome",
+		"account":   account,
 		"zone_id":   strconv.Itoa(zone.ID),
 		"zone_name": zone.Name,
 	}
 	weatherLabels := map[string]string{
 		"job":      "gohome",
 		"instance": "gohome",
+		"account":  account,
 	}
 
 	for _, pt := range report.MeasuredData.InsideTemperature.DataPoints {
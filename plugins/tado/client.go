@@ -3,6 +3,7 @@ package tado
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"github.com/joshp123/gohome/internal/oauth"
+	"github.com/joshp123/gohome/internal/rate"
 	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
 )
 
@@ -21,6 +23,9 @@ type Client struct {
 
 	httpClient *http.Client
 	homeID     *int
+	account    string
+
+	refreshInterval time.Duration
 }
 
 type HTTPStatusError struct {
@@ -33,7 +38,12 @@ func (e HTTPStatusError) Error() string {
 }
 
 func NewClient(cfg Config, decl oauth.Declaration, oauthCfg *configv1.OAuthConfig) (*Client, error) {
-	blobStore, err := oauth.NewS3Store(oauthCfg)
+	return newClient(cfg, decl, oauthCfg, rate.WrapHTTP(Plugin{}.RateLimits(), &http.Client{Timeout: 15 * time.Second}))
+}
+
+// newClient lets the plugin route every account through one rate guard.
+func newClient(cfg Config, decl oauth.Declaration, oauthCfg *configv1.OAuthConfig, httpClient *http.Client) (*Client, error) {
+	blobStore, err := oauth.NewBlobStore(oauthCfg)
 	if err != nil {
 		return nil, err
 	}
@@ -41,15 +51,27 @@ func NewClient(cfg Config, decl oauth.Declaration, oauthCfg *configv1.OAuthConfi
 	if err != nil {
 		return nil, err
 	}
-	client.oauth.StartWithInterval(context.Background(), oauth.RefreshInterval(oauthCfg))
+	client.httpClient = httpClient
+	client.refreshInterval = oauth.RefreshInterval(oauthCfg)
 	return client, nil
 }
 
+// Start begins background OAuth refresh; ctx bounds its lifetime.
+func (c *Client) Start(ctx context.Context) {
+	c.oauth.StartWithInterval(ctx, c.refreshInterval)
+}
+
+// Stop ends background OAuth refresh and releases the client's rate guard.
+func (c *Client) Stop(ctx context.Context) error {
+	return errors.Join(c.oauth.Stop(ctx), rate.Release(ctx, c.httpClient))
+}
+
 func NewClientWithStore(cfg Config, decl oauth.Declaration, blobStore oauth.BlobStore) (*Client, error) {
 	if blobStore == nil {
 		return nil, fmt.Errorf("blob store is required")
 	}
 
+	decl = decl.ForAccount(cfg.Account)
 	manager, err := oauth.NewManager(decl, cfg.BootstrapFile, blobStore)
 	if err != nil {
 		return nil, err
@@ -65,9 +87,15 @@ func NewClientWithStore(cfg Config, decl oauth.Declaration, blobStore oauth.Blob
 		oauth:      manager,
 		httpClient: &http.Client{Timeout: 15 * time.Second},
 		homeID:     cfg.HomeID,
+		account:    cfg.AccountName(),
 	}, nil
 }
 
+// Account returns the account name, "default" for the default account.
+func (c *Client) Account() string {
+	return c.account
+}
+
 func (c *Client) HomeID(ctx context.Context) (int, error) {
 	if c.homeID != nil {
 		return *c.homeID, nil
@@ -3,6 +3,7 @@ package tado
 import (
 	"fmt"
 
+	"github.com/joshp123/gohome/internal/oauth"
 	tadov1 "github.com/joshp123/gohome/proto/gen/plugins/tado/v1"
 )
 
@@ -10,33 +11,106 @@ const (
 	defaultBaseURL = "https://my.tado.com/api/v2"
 )
 
-// Config defines runtime configuration for the Tado client.
+// Config defines runtime configuration for one Tado account.
 type Config struct {
 	BaseURL       string
 	BootstrapFile string
 	HomeID        *int
+	// Account is "" for the default account (the top-level bootstrap_file).
+	Account string
 }
 
-func ConfigFromProto(cfg *tadov1.TadoConfig) (Config, error) {
+// ConfigsFromProto returns one Config per account: the default account first
+// when bootstrap_file is set, then the named accounts in order.
+func ConfigsFromProto(cfg *tadov1.TadoConfig) ([]Config, error) {
 	if cfg == nil {
-		return Config{}, fmt.Errorf("tado config is required")
+		return nil, fmt.Errorf("tado config is required")
 	}
-	if cfg.BootstrapFile == "" {
-		return Config{}, fmt.Errorf("tado bootstrap_file is required")
+	if cfg.BootstrapFile == "" && len(cfg.Accounts) == 0 {
+		return nil, fmt.Errorf("tado bootstrap_file is required")
 	}
 
-	var homeID *int
-	if cfg.HomeId != nil {
-		value := int(cfg.GetHomeId())
-		if value <= 0 {
-			return Config{}, fmt.Errorf("tado home_id must be positive")
+	var configs []Config
+	if cfg.BootstrapFile != "" {
+		homeID, err := homeIDFromProto(cfg.HomeId)
+		if err != nil {
+			return nil, err
 		}
-		homeID = &value
+		configs = append(configs, Config{
+			BaseURL:       defaultBaseURL,
+			BootstrapFile: cfg.BootstrapFile,
+			HomeID:        homeID,
+		})
 	}
 
-	return Config{
-		BaseURL:       defaultBaseURL,
-		BootstrapFile: cfg.BootstrapFile,
-		HomeID:        homeID,
-	}, nil
+	seen := make(map[string]bool)
+	for _, account := range cfg.Accounts {
+		name := account.GetName()
+		if err := oauth.ValidateAccount(name); err != nil {
+			return nil, fmt.Errorf("tado account: %w", err)
+		}
+		if name == oauth.DefaultAccount {
+			return nil, fmt.Errorf("tado account %q is reserved; use the top-level bootstrap_file", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("tado account %q is declared twice", name)
+		}
+		seen[name] = true
+		if account.GetBootstrapFile() == "" {
+			return nil, fmt.Errorf("tado account %q: bootstrap_file is required", name)
+		}
+		homeID, err := homeIDFromProto(account.HomeId)
+		if err != nil {
+			return nil, fmt.Errorf("tado account %q: %w", name, err)
+		}
+		configs = append(configs, Config{
+			BaseURL:       defaultBaseURL,
+			BootstrapFile: account.GetBootstrapFile(),
+			HomeID:        homeID,
+			Account:       name,
+		})
+	}
+	return configs, nil
+}
+
+// SelectAccount returns the config for account. "" selects the default
+// account, or the only account when just one is configured.
+func SelectAccount(configs []Config, account string) (Config, error) {
+	if account == "" && len(configs) == 1 {
+		return configs[0], nil
+	}
+	if account == oauth.DefaultAccount {
+		account = ""
+	}
+	names := make([]string, 0, len(configs))
+	for _, cfg := range configs {
+		if cfg.Account == account {
+			return cfg, nil
+		}
+		names = append(names, cfg.AccountName())
+	}
+	return Config{}, fmt.Errorf("tado account %q not configured (have %v)", accountLabel(account), names)
+}
+
+// AccountName returns the account's name, "default" for the default account.
+func (c Config) AccountName() string {
+	return accountLabel(c.Account)
+}
+
+func accountLabel(account string) string {
+	if account == "" {
+		return oauth.DefaultAccount
+	}
+	return account
+}
+
+func homeIDFromProto(id *int32) (*int, error) {
+	if id == nil {
+		return nil, nil
+	}
+	value := int(*id)
+	if value <= 0 {
+		return nil, fmt.Errorf("tado home_id must be positive")
+	}
+	return &value, nil
 }
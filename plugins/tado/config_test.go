@@ -0,0 +1,78 @@
+package tado
+
+import (
+	"strings"
+	"testing"
+
+	tadov1 "github.com/joshp123/gohome/proto/gen/plugins/tado/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestConfigsFromProto(t *testing.T) {
+	configs, err := ConfigsFromProto(&tadov1.TadoConfig{
+		BootstrapFile: "/run/agenix/tado",
+		Accounts: []*tadov1.TadoAccount{
+			{Name: "holiday", BootstrapFile: "/run/agenix/tado-holiday", HomeId: proto.Int32(42)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ConfigsFromProto: %v", err)
+	}
+	if len(configs) != 2 {
+		t.Fatalf("expected 2 accounts, got %d", len(configs))
+	}
+	if configs[0].Account != "" || configs[0].BootstrapFile != "/run/agenix/tado" {
+		t.Fatalf("unexpected default account: %+v", configs[0])
+	}
+	if configs[1].Account != "holiday" || configs[1].HomeID == nil || *configs[1].HomeID != 42 {
+		t.Fatalf("unexpected holiday account: %+v", configs[1])
+	}
+
+	holiday, err := SelectAccount(configs, "holiday")
+	if err != nil || holiday.BootstrapFile != "/run/agenix/tado-holiday" {
+		t.Fatalf("SelectAccount(holiday) = %+v, %v", holiday, err)
+	}
+	def, err := SelectAccount(configs, "default")
+	if err != nil || def.Account != "" {
+		t.Fatalf("SelectAccount(default) = %+v, %v", def, err)
+	}
+	if _, err := SelectAccount(configs, "office"); err == nil {
+		t.Fatal("expected error for unknown account")
+	}
+}
+
+func TestConfigsFromProtoAccountsOnly(t *testing.T) {
+	configs, err := ConfigsFromProto(&tadov1.TadoConfig{
+		Accounts: []*tadov1.TadoAccount{{Name: "flat", BootstrapFile: "/run/agenix/tado-flat"}},
+	})
+	if err != nil {
+		t.Fatalf("ConfigsFromProto: %v", err)
+	}
+	only, err := SelectAccount(configs, "")
+	if err != nil || only.Account != "flat" {
+		t.Fatalf("SelectAccount(\"\") = %+v, %v", only, err)
+	}
+}
+
+func TestConfigsFromProtoErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *tadov1.TadoConfig
+		want string
+	}{
+		{"empty", &tadov1.TadoConfig{}, "bootstrap_file is required"},
+		{"bad name", &tadov1.TadoConfig{Accounts: []*tadov1.TadoAccount{{Name: "Holiday", BootstrapFile: "/x"}}}, "invalid account name"},
+		{"reserved", &tadov1.TadoConfig{Accounts: []*tadov1.TadoAccount{{Name: "default", BootstrapFile: "/x"}}}, "reserved"},
+		{"duplicate", &tadov1.TadoConfig{Accounts: []*tadov1.TadoAccount{{Name: "a", BootstrapFile: "/x"}, {Name: "a", BootstrapFile: "/y"}}}, "declared twice"},
+		{"no bootstrap", &tadov1.TadoConfig{Accounts: []*tadov1.TadoAccount{{Name: "a"}}}, "bootstrap_file is required"},
+		{"bad home", &tadov1.TadoConfig{Accounts: []*tadov1.TadoAccount{{Name: "a", BootstrapFile: "/x", HomeId: proto.Int32(0)}}}, "home_id must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ConfigsFromProto(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
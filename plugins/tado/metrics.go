@@ -8,9 +8,11 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-// MetricsCollector collects zone temperature and humidity metrics.
+// MetricsCollector collects zone temperature and humidity metrics for every
+// account. Each series carries an account label ("default" for the top-level
+// bootstrap_file).
 type MetricsCollector struct {
-	client *Client
+	clients []*Client
 
 	temp           *prometheus.GaugeVec
 	humidity       *prometheus.GaugeVec
@@ -20,16 +22,17 @@ type MetricsCollector struct {
 	override       *prometheus.GaugeVec
 	heatingActive  *prometheus.GaugeVec
 	lastUpdated    *prometheus.GaugeVec
-	outsideTemp    prometheus.Gauge
-	solarIntensity prometheus.Gauge
-	lastSuccess    prometheus.Gauge
-	success        prometheus.Gauge
+	outsideTemp    *prometheus.GaugeVec
+	solarIntensity *prometheus.GaugeVec
+	lastSuccess    *prometheus.GaugeVec
+	success        *prometheus.GaugeVec
 }
 
-func NewMetricsCollector(client *Client) *MetricsCollector {
-	labels := []string{"zone_id", "zone_name"}
+func NewMetricsCollector(clients ...*Client) *MetricsCollector {
+	labels := []string{"account", "zone_id", "zone_name"}
+	accountLabels := []string{"account"}
 	return &MetricsCollector{
-		client: client,
+		clients: clients,
 		temp: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "gohome_tado_inside_temperature_celsius",
 			Help: "Current inside temperature per zone",
@@ -62,22 +65,22 @@ func NewMetricsCollector(client *Client) *MetricsCollector {
 			Name: "gohome_tado_zone_last_updated_timestamp_seconds",
 			Help: "Last update timestamp per zone (epoch seconds)",
 		}, labels),
-		outsideTemp: prometheus.NewGauge(prometheus.GaugeOpts{
+		outsideTemp: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "gohome_tado_outside_temperature_celsius",
 			Help: "Outside temperature from Tado",
-		}),
-		solarIntensity: prometheus.NewGauge(prometheus.GaugeOpts{
+		}, accountLabels),
+		solarIntensity: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "gohome_tado_solar_intensity_percent",
 			Help: "Solar intensity from Tado weather (percent)",
-		}),
-		lastSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+		}, accountLabels),
+		lastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "gohome_tado_last_success_timestamp_seconds",
 			Help: "Last successful Tado scrape timestamp (epoch seconds)",
-		}),
-		success: prometheus.NewGauge(prometheus.GaugeOpts{
+		}, accountLabels),
+		success: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "gohome_tado_scrape_success",
 			Help: "Last scrape success (1=ok, 0=error)",
-		}),
+		}, accountLabels),
 	}
 }
 
@@ -100,35 +103,44 @@ func (c *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	zones, err := c.client.Zones(ctx)
+	for _, client := range c.clients {
+		c.collectAccount(ctx, client)
+	}
+	c.collectAll(ch)
+}
+
+// collectAccount refreshes one account's series. On error the account's
+// previous values are kept and only its success gauge drops to 0.
+func (c *MetricsCollector) collectAccount(ctx context.Context, client *Client) {
+	account := client.Account()
+	zones, err := client.Zones(ctx)
 	if err != nil {
-		c.success.Set(0)
-		c.collectAll(ch)
+		c.success.WithLabelValues(account).Set(0)
 		return
 	}
 
-	states, err := c.client.ZoneStates(ctx)
+	states, err := client.ZoneStates(ctx)
 	if err != nil {
-		c.success.Set(0)
-		c.collectAll(ch)
+		c.success.WithLabelValues(account).Set(0)
 		return
 	}
 
-	c.temp.Reset()
-	c.humidity.Reset()
-	c.setpoint.Reset()
-	c.heatingPower.Reset()
-	c.powerOn.Reset()
-	c.override.Reset()
-	c.heatingActive.Reset()
-	c.lastUpdated.Reset()
+	match := prometheus.Labels{"account": account}
+	c.temp.DeletePartialMatch(match)
+	c.humidity.DeletePartialMatch(match)
+	c.setpoint.DeletePartialMatch(match)
+	c.heatingPower.DeletePartialMatch(match)
+	c.powerOn.DeletePartialMatch(match)
+	c.override.DeletePartialMatch(match)
+	c.heatingActive.DeletePartialMatch(match)
+	c.lastUpdated.DeletePartialMatch(match)
 
-	if weather, err := c.client.Weather(ctx); err == nil {
+	if weather, err := client.Weather(ctx); err == nil {
 		if weather.OutsideTemperatureCelsius != nil {
-			c.outsideTemp.Set(*weather.OutsideTemperatureCelsius)
+			c.outsideTemp.WithLabelValues(account).Set(*weather.OutsideTemperatureCelsius)
 		}
 		if weather.SolarIntensityPercent != nil {
-			c.solarIntensity.Set(*weather.SolarIntensityPercent)
+			c.solarIntensity.WithLabelValues(account).Set(*weather.SolarIntensityPercent)
 		}
 	}
 
@@ -138,6 +150,7 @@ func (c *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
 			continue
 		}
 		labels := prometheus.Labels{
+			"account":   account,
 			"zone_id":   strconv.Itoa(zone.ID),
 			"zone_name": zone.Name,
 		}
@@ -169,9 +182,8 @@ func (c *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
 		}
 	}
 
-	c.success.Set(1)
-	c.lastSuccess.Set(float64(time.Now().Unix()))
-	c.collectAll(ch)
+	c.success.WithLabelValues(account).Set(1)
+	c.lastSuccess.WithLabelValues(account).Set(float64(time.Now().Unix()))
 }
 
 func (c *MetricsCollector) collectAll(ch chan<- prometheus.Metric) {
@@ -21,13 +21,32 @@ in
       default = null;
       description = "Optional homeId override (if /me contains multiple homes)";
     };
+
+    accounts = mkOption {
+      type = types.attrsOf (types.submodule {
+        options = {
+          bootstrapFile = mkOption {
+            type = types.path;
+            description = "Path to Tado OAuth credentials bootstrap JSON for this account";
+          };
+
+          homeId = mkOption {
+            type = types.nullOr types.int;
+            default = null;
+            description = "Optional homeId override for this account";
+          };
+        };
+      });
+      default = { };
+      description = "Additional named Tado accounts";
+    };
   };
 
   config = mkIf cfg.enable {
     assertions = [
       {
-        assertion = cfg.bootstrapFile != null;
-        message = "services.gohome.plugins.tado.bootstrapFile is required";
+        assertion = cfg.bootstrapFile != null || cfg.accounts != { };
+        message = "services.gohome.plugins.tado.bootstrapFile or accounts is required";
       }
     ];
   };
@@ -1,10 +1,17 @@
 package tado
 
 import (
+	"context"
 	_ "embed"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/joshp123/gohome/internal/core"
 	"github.com/joshp123/gohome/internal/oauth"
+	"github.com/joshp123/gohome/internal/rate"
 	configv1 "github.com/joshp123/gohome/proto/gen/config/v1"
 	tadov1 "github.com/joshp123/gohome/proto/gen/plugins/tado/v1"
 	"github.com/prometheus/client_golang/prometheus"
@@ -17,31 +24,55 @@ var agentsMD string
 //go:embed dashboard.json
 var dashboardJSON []byte
 
+var _ rate.RateLimited = (*Plugin)(nil)
+var _ core.Lifecycle = Plugin{}
+
 // Plugin implements the GoHome plugin contract.
 type Plugin struct {
-	client        *Client
+	clients       []*Client
 	health        core.HealthStatus
 	healthMessage string
 }
 
-// NewPlugin constructs a Tado plugin from config.
+// NewPlugin constructs a Tado plugin from config, with one client per
+// account. Accounts that fail to start are reported in the health message;
+// the plugin is degraded while at least one account works.
 func NewPlugin(cfg *tadov1.TadoConfig, oauthCfg *configv1.OAuthConfig) (Plugin, bool) {
 	if cfg == nil {
 		return Plugin{}, false
 	}
 
-	runtimeCfg, err := ConfigFromProto(cfg)
+	configs, err := ConfigsFromProto(cfg)
 	if err != nil {
 		return Plugin{health: core.HealthError, healthMessage: err.Error()}, true
 	}
 
 	decl := Plugin{}.OAuthDeclaration()
-	client, err := NewClient(runtimeCfg, decl, oauthCfg)
-	if err != nil {
-		return Plugin{health: core.HealthError, healthMessage: err.Error()}, true
+	httpClient := rate.WrapHTTP(Plugin{}.RateLimits(), &http.Client{Timeout: 15 * time.Second})
+	var clients []*Client
+	var failures []string
+	for _, runtimeCfg := range configs {
+		client, err := newClient(runtimeCfg, decl, oauthCfg, httpClient)
+		if err != nil {
+			if len(configs) > 1 {
+				err = fmt.Errorf("account %s: %w", runtimeCfg.AccountName(), err)
+			}
+			failures = append(failures, err.Error())
+			continue
+		}
+		clients = append(clients, client)
 	}
 
-	return Plugin{client: client, health: core.HealthHealthy}, true
+	switch {
+	case len(failures) == 0:
+		return Plugin{clients: clients, health: core.HealthHealthy}, true
+	case len(clients) == 0:
+		// No client owns the shared guard, so Stop would never release it.
+		_ = rate.Release(context.Background(), httpClient)
+		return Plugin{health: core.HealthError, healthMessage: strings.Join(failures, "; ")}, true
+	default:
+		return Plugin{clients: clients, health: core.HealthDegraded, healthMessage: strings.Join(failures, "; ")}, true
+	}
 }
 
 func (p Plugin) ID() string {
@@ -73,25 +104,62 @@ func (p Plugin) OAuthDeclaration() oauth.Declaration {
 	}
 }
 
+func (p Plugin) Start(ctx context.Context) error {
+	for _, client := range p.clients {
+		client.Start(ctx)
+	}
+	return nil
+}
+
+func (p Plugin) Stop(ctx context.Context) error {
+	var errs []error
+	for _, client := range p.clients {
+		if err := client.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", client.Account(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (p Plugin) Dashboards() []core.Dashboard {
 	return []core.Dashboard{{Name: "tado-overview", JSON: dashboardJSON}}
 }
 
 func (p Plugin) RegisterGRPC(server *grpc.Server) {
-	RegisterTadoService(server, p.client)
+	RegisterTadoService(server, p.clients...)
 }
 
 func (p Plugin) Collectors() []prometheus.Collector {
-	if p.client == nil {
+	if len(p.clients) == 0 {
 		return nil
 	}
-	return []prometheus.Collector{NewMetricsCollector(p.client)}
+	return []prometheus.Collector{NewMetricsCollector(p.clients...)}
 }
 
 func (p Plugin) Health() core.HealthStatus {
+	if oauth.ReauthMessage("tado") != "" {
+		return core.HealthError
+	}
+	if p.health == core.HealthHealthy && rate.Circuit("tado").State != rate.BreakerClosed {
+		return core.HealthDegraded
+	}
 	return p.health
 }
 
 func (p Plugin) HealthMessage() string {
+	if msg := oauth.ReauthMessage("tado"); msg != "" {
+		return msg
+	}
+	if p.healthMessage == "" {
+		return rate.Circuit("tado").Message()
+	}
 	return p.healthMessage
 }
+
+// RateLimits enforces no budget; it adds a circuit breaker so calls fail fast
+// while the cloud is down.
+func (p Plugin) RateLimits() rate.Declaration {
+	return rate.Provider("tado").
+		Unmetered().
+		Breaker(rate.DefaultBreaker())
+}
@@ -3,6 +3,7 @@ package tado
 import (
 	context "context"
 	"strconv"
+	"strings"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -13,47 +14,81 @@ import (
 
 type service struct {
 	v1.UnimplementedTadoServiceServer
-	client *Client
+	clients []*Client
 }
 
-func RegisterTadoService(server *grpc.Server, client *Client) {
-	v1.RegisterTadoServiceServer(server, &service{client: client})
+// RegisterTadoService serves every configured account; zones carry the
+// account they belong to.
+func RegisterTadoService(server *grpc.Server, clients ...*Client) {
+	v1.RegisterTadoServiceServer(server, &service{clients: clients})
 }
 
 func (s *service) ListZones(ctx context.Context, _ *v1.ListZonesRequest) (*v1.ListZonesResponse, error) {
-	if s.client == nil {
+	if len(s.clients) == 0 {
 		return nil, status.Error(codes.FailedPrecondition, "tado client not configured")
 	}
 
-	zones, err := s.client.Zones(ctx)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "list zones: %v", err)
-	}
-
 	resp := &v1.ListZonesResponse{}
-	for _, zone := range zones {
-		resp.Zones = append(resp.Zones, &v1.Zone{
-			Id:   strconv.Itoa(zone.ID),
-			Name: zone.Name,
-		})
+	for _, client := range s.clients {
+		zones, err := client.Zones(ctx)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "list zones (account %s): %v", client.Account(), err)
+		}
+		for _, zone := range zones {
+			resp.Zones = append(resp.Zones, &v1.Zone{
+				Id:      strconv.Itoa(zone.ID),
+				Name:    zone.Name,
+				Account: client.Account(),
+			})
+		}
 	}
 
 	return resp, nil
 }
 
 func (s *service) SetTemperature(ctx context.Context, req *v1.SetTemperatureRequest) (*v1.SetTemperatureResponse, error) {
-	if s.client == nil {
+	if len(s.clients) == 0 {
 		return nil, status.Error(codes.FailedPrecondition, "tado client not configured")
 	}
 
+	client, err := s.client(req.Account)
+	if err != nil {
+		return nil, err
+	}
+
 	zoneID, err := strconv.Atoi(req.ZoneId)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid zone_id: %v", err)
 	}
 
-	if err := s.client.SetZoneTemperature(ctx, zoneID, req.TemperatureCelsius); err != nil {
+	if err := client.SetZoneTemperature(ctx, zoneID, req.TemperatureCelsius); err != nil {
 		return nil, status.Errorf(codes.Internal, "set temperature: %v", err)
 	}
 
 	return &v1.SetTemperatureResponse{}, nil
 }
+
+// client resolves a request's account. It may be omitted when only one
+// account is configured.
+func (s *service) client(account string) (*Client, error) {
+	if account == "" {
+		if len(s.clients) == 1 {
+			return s.clients[0], nil
+		}
+		return nil, status.Errorf(codes.InvalidArgument, "account is required (one of %s)", strings.Join(s.accounts(), ", "))
+	}
+	for _, client := range s.clients {
+		if client.Account() == account {
+			return client, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "unknown account %q (have %s)", account, strings.Join(s.accounts(), ", "))
+}
+
+func (s *service) accounts() []string {
+	names := make([]string, 0, len(s.clients))
+	for _, client := range s.clients {
+		names = append(names, client.Account())
+	}
+	return names
+}
@@ -0,0 +1,37 @@
+package tado
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/joshp123/gohome/proto/gen/plugins/tado/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServiceAccountResolution(t *testing.T) {
+	home := &Client{account: "default"}
+	holiday := &Client{account: "holiday"}
+
+	single := &service{clients: []*Client{home}}
+	if got, err := single.client(""); err != nil || got != home {
+		t.Fatalf("single account, no name: got %v, %v", got, err)
+	}
+
+	multi := &service{clients: []*Client{home, holiday}}
+	if got, err := multi.client("holiday"); err != nil || got != holiday {
+		t.Fatalf("client(holiday): got %v, %v", got, err)
+	}
+	if got, err := multi.client("default"); err != nil || got != home {
+		t.Fatalf("client(default): got %v, %v", got, err)
+	}
+
+	_, err := multi.SetTemperature(context.Background(), &v1.SetTemperatureRequest{ZoneId: "1", TemperatureCelsius: 20})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("missing account: expected InvalidArgument, got %v", err)
+	}
+	_, err = multi.SetTemperature(context.Background(), &v1.SetTemperatureRequest{ZoneId: "1", TemperatureCelsius: 20, Account: "office"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("unknown account: expected NotFound, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package weheat
 import (
 	"context"
 	"fmt"
+	"time"
 
 	weheatapi "github.com/joshp123/weheat-golang"
 
@@ -14,6 +15,8 @@ import (
 type Client struct {
 	api   *weheatapi.Client
 	oauth *oauth.Manager
+
+	refreshInterval time.Duration
 }
 
 type oauthTokenSource struct {
@@ -28,7 +31,7 @@ func (o oauthTokenSource) Token(ctx context.Context) (string, error) {
 }
 
 func NewClient(cfg Config, bootstrap oauth.Bootstrap, decl oauth.Declaration, oauthCfg *configv1.OAuthConfig) (*Client, error) {
-	blobStore, err := oauth.NewS3Store(oauthCfg)
+	blobStore, err := oauth.NewBlobStore(oauthCfg)
 	if err != nil {
 		return nil, err
 	}
@@ -37,7 +40,6 @@ func NewClient(cfg Config, bootstrap oauth.Bootstrap, decl oauth.Declaration, oa
 	if err != nil {
 		return nil, err
 	}
-	manager.StartWithInterval(context.Background(), oauth.RefreshInterval(oauthCfg))
 
 	opts := []weheatapi.ClientOption{weheatapi.WithTokenSource(oauthTokenSource{manager: manager})}
 	if cfg.BaseURL != "" {
@@ -49,7 +51,17 @@ func NewClient(cfg Config, bootstrap oauth.Bootstrap, decl oauth.Declaration, oa
 		return nil, err
 	}
 
-	return &Client{api: api, oauth: manager}, nil
+	return &Client{api: api, oauth: manager, refreshInterval: oauth.RefreshInterval(oauthCfg)}, nil
+}
+
+// Start begins background OAuth refresh; ctx bounds its lifetime.
+func (c *Client) Start(ctx context.Context) {
+	c.oauth.StartWithInterval(ctx, c.refreshInterval)
+}
+
+// Stop ends background OAuth refresh.
+func (c *Client) Stop(ctx context.Context) error {
+	return c.oauth.Stop(ctx)
 }
 
 func (c *Client) ListHeatPumps(ctx context.Context, state *weheatapi.DeviceState) ([]weheatapi.ReadAllHeatPump, error) {
@@ -1,6 +1,7 @@
 package weheat
 
 import (
+	"context"
 	_ "embed"
 
 	"github.com/joshp123/gohome/internal/core"
@@ -25,6 +26,8 @@ var agentsMD string
 //go:embed dashboard.json
 var dashboardJSON []byte
 
+var _ core.Lifecycle = Plugin{}
+
 // Plugin implements the GoHome plugin contract.
 type Plugin struct {
 	client        *Client
@@ -87,6 +90,20 @@ func (p Plugin) OAuthDeclaration() oauth.Declaration {
 	}
 }
 
+func (p Plugin) Start(ctx context.Context) error {
+	if p.client != nil {
+		p.client.Start(ctx)
+	}
+	return nil
+}
+
+func (p Plugin) Stop(ctx context.Context) error {
+	if p.client == nil {
+		return nil
+	}
+	return p.client.Stop(ctx)
+}
+
 func (p Plugin) Dashboards() []core.Dashboard {
 	return []core.Dashboard{{Name: "weheat-overview", JSON: dashboardJSON}}
 }
@@ -103,9 +120,15 @@ func (p Plugin) Collectors() []prometheus.Collector {
 }
 
 func (p Plugin) Health() core.HealthStatus {
+	if oauth.ReauthMessage("weheat") != "" {
+		return core.HealthError
+	}
 	return p.health
 }
 
 func (p Plugin) HealthMessage() string {
+	if msg := oauth.ReauthMessage("weheat"); msg != "" {
+		return msg
+	}
 	return p.healthMessage
 }
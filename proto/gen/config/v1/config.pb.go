@@ -29,18 +29,473 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
-type CoreConfig struct {
+type DegradedHealth int32
+
+const (
+	DegradedHealth_DEGRADED_HEALTH_UNSPECIFIED DegradedHealth = 0
+	DegradedHealth_DEGRADED_HEALTH_SERVING     DegradedHealth = 1
+	DegradedHealth_DEGRADED_HEALTH_NOT_SERVING DegradedHealth = 2
+)
+
+// Enum value maps for DegradedHealth.
+var (
+	DegradedHealth_name = map[int32]string{
+		0: "DEGRADED_HEALTH_UNSPECIFIED",
+		1: "DEGRADED_HEALTH_SERVING",
+		2: "DEGRADED_HEALTH_NOT_SERVING",
+	}
+	DegradedHealth_value = map[string]int32{
+		"DEGRADED_HEALTH_UNSPECIFIED": 0,
+		"DEGRADED_HEALTH_SERVING":     1,
+		"DEGRADED_HEALTH_NOT_SERVING": 2,
+	}
+)
+
+func (x DegradedHealth) Enum() *DegradedHealth {
+	p := new(DegradedHealth)
+	*p = x
+	return p
+}
+
+func (x DegradedHealth) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DegradedHealth) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_config_v1_config_proto_enumTypes[0].Descriptor()
+}
+
+func (DegradedHealth) Type() protoreflect.EnumType {
+	return &file_proto_config_v1_config_proto_enumTypes[0]
+}
+
+func (x DegradedHealth) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DegradedHealth.Descriptor instead.
+func (DegradedHealth) EnumDescriptor() ([]byte, []int) {
+	return file_proto_config_v1_config_proto_rawDescGZIP(), []int{0}
+}
+
+type RateStateBackend int32
+
+const (
+	RateStateBackend_RATE_STATE_BACKEND_UNSPECIFIED RateStateBackend = 0
+	RateStateBackend_RATE_STATE_BACKEND_FILE        RateStateBackend = 1
+	RateStateBackend_RATE_STATE_BACKEND_BLOB        RateStateBackend = 2
+)
+
+// Enum value maps for RateStateBackend.
+var (
+	RateStateBackend_name = map[int32]string{
+		0: "RATE_STATE_BACKEND_UNSPECIFIED",
+		1: "RATE_STATE_BACKEND_FILE",
+		2: "RATE_STATE_BACKEND_BLOB",
+	}
+	RateStateBackend_value = map[string]int32{
+		"RATE_STATE_BACKEND_UNSPECIFIED": 0,
+		"RATE_STATE_BACKEND_FILE":        1,
+		"RATE_STATE_BACKEND_BLOB":        2,
+	}
+)
+
+func (x RateStateBackend) Enum() *RateStateBackend {
+	p := new(RateStateBackend)
+	*p = x
+	return p
+}
+
+func (x RateStateBackend) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RateStateBackend) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_config_v1_config_proto_enumTypes[1].Descriptor()
+}
+
+func (RateStateBackend) Type() protoreflect.EnumType {
+	return &file_proto_config_v1_config_proto_enumTypes[1]
+}
+
+func (x RateStateBackend) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RateStateBackend.Descriptor instead.
+func (RateStateBackend) EnumDescriptor() ([]byte, []int) {
+	return file_proto_config_v1_config_proto_rawDescGZIP(), []int{1}
+}
+
+type DenyRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Method        string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	Between       string                 `protobuf:"bytes,2,opt,name=between,proto3" json:"between,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DenyRule) Reset() {
+	*x = DenyRule{}
+	mi := &file_proto_config_v1_config_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DenyRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DenyRule) ProtoMessage() {}
+
+func (x *DenyRule) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_config_v1_config_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DenyRule.ProtoReflect.Descriptor instead.
+func (*DenyRule) Descriptor() ([]byte, []int) {
+	return file_proto_config_v1_config_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DenyRule) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *DenyRule) GetBetween() string {
+	if x != nil {
+		return x.Between
+	}
+	return ""
+}
+
+type NamedToken struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	TokenFile     string                 `protobuf:"bytes,2,opt,name=token_file,json=tokenFile,proto3" json:"token_file,omitempty"`
+	Scopes        []string               `protobuf:"bytes,3,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	Deny          []*DenyRule            `protobuf:"bytes,4,rep,name=deny,proto3" json:"deny,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NamedToken) Reset() {
+	*x = NamedToken{}
+	mi := &file_proto_config_v1_config_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NamedToken) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NamedToken) ProtoMessage() {}
+
+func (x *NamedToken) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_config_v1_config_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NamedToken.ProtoReflect.Descriptor instead.
+func (*NamedToken) Descriptor() ([]byte, []int) {
+	return file_proto_config_v1_config_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *NamedToken) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *NamedToken) GetTokenFile() string {
+	if x != nil {
+		return x.TokenFile
+	}
+	return ""
+}
+
+func (x *NamedToken) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+func (x *NamedToken) GetDeny() []*DenyRule {
+	if x != nil {
+		return x.Deny
+	}
+	return nil
+}
+
+type AuthConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TokenFile     string                 `protobuf:"bytes,1,opt,name=token_file,json=tokenFile,proto3" json:"token_file,omitempty"`
+	Tokens        []*NamedToken          `protobuf:"bytes,2,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuthConfig) Reset() {
+	*x = AuthConfig{}
+	mi := &file_proto_config_v1_config_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthConfig) ProtoMessage() {}
+
+func (x *AuthConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_config_v1_config_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthConfig.ProtoReflect.Descriptor instead.
+func (*AuthConfig) Descriptor() ([]byte, []int) {
+	return file_proto_config_v1_config_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AuthConfig) GetTokenFile() string {
+	if x != nil {
+		return x.TokenFile
+	}
+	return ""
+}
+
+func (x *AuthConfig) GetTokens() []*NamedToken {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+type TLSConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CertFile      string                 `protobuf:"bytes,1,opt,name=cert_file,json=certFile,proto3" json:"cert_file,omitempty"`
+	KeyFile       string                 `protobuf:"bytes,2,opt,name=key_file,json=keyFile,proto3" json:"key_file,omitempty"`
+	ClientCaFile  string                 `protobuf:"bytes,3,opt,name=client_ca_file,json=clientCaFile,proto3" json:"client_ca_file,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TLSConfig) Reset() {
+	*x = TLSConfig{}
+	mi := &file_proto_config_v1_config_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TLSConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TLSConfig) ProtoMessage() {}
+
+func (x *TLSConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_config_v1_config_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TLSConfig.ProtoReflect.Descriptor instead.
+func (*TLSConfig) Descriptor() ([]byte, []int) {
+	return file_proto_config_v1_config_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TLSConfig) GetCertFile() string {
+	if x != nil {
+		return x.CertFile
+	}
+	return ""
+}
+
+func (x *TLSConfig) GetKeyFile() string {
+	if x != nil {
+		return x.KeyFile
+	}
+	return ""
+}
+
+func (x *TLSConfig) GetClientCaFile() string {
+	if x != nil {
+		return x.ClientCaFile
+	}
+	return ""
+}
+
+type AuditConfig struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	GrpcAddr      string                 `protobuf:"bytes,1,opt,name=grpc_addr,json=grpcAddr,proto3" json:"grpc_addr,omitempty"`
-	HttpAddr      string                 `protobuf:"bytes,2,opt,name=http_addr,json=httpAddr,proto3" json:"http_addr,omitempty"`
-	DashboardDir  string                 `protobuf:"bytes,3,opt,name=dashboard_dir,json=dashboardDir,proto3" json:"dashboard_dir,omitempty"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	MaxSizeMb     uint32                 `protobuf:"varint,2,opt,name=max_size_mb,json=maxSizeMb,proto3" json:"max_size_mb,omitempty"`
+	MaxFiles      uint32                 `protobuf:"varint,3,opt,name=max_files,json=maxFiles,proto3" json:"max_files,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *AuditConfig) Reset() {
+	*x = AuditConfig{}
+	mi := &file_proto_config_v1_config_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditConfig) ProtoMessage() {}
+
+func (x *AuditConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_config_v1_config_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditConfig.ProtoReflect.Descriptor instead.
+func (*AuditConfig) Descriptor() ([]byte, []int) {
+	return file_proto_config_v1_config_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AuditConfig) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *AuditConfig) GetMaxSizeMb() uint32 {
+	if x != nil {
+		return x.MaxSizeMb
+	}
+	return 0
+}
+
+func (x *AuditConfig) GetMaxFiles() uint32 {
+	if x != nil {
+		return x.MaxFiles
+	}
+	return 0
+}
+
+type RateStateConfig struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Backend             RateStateBackend       `protobuf:"varint,1,opt,name=backend,proto3,enum=gohome.config.v1.RateStateBackend" json:"backend,omitempty"`
+	Dir                 string                 `protobuf:"bytes,2,opt,name=dir,proto3" json:"dir,omitempty"`
+	SaveIntervalSeconds uint32                 `protobuf:"varint,3,opt,name=save_interval_seconds,json=saveIntervalSeconds,proto3" json:"save_interval_seconds,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *RateStateConfig) Reset() {
+	*x = RateStateConfig{}
+	mi := &file_proto_config_v1_config_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RateStateConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateStateConfig) ProtoMessage() {}
+
+func (x *RateStateConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_config_v1_config_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateStateConfig.ProtoReflect.Descriptor instead.
+func (*RateStateConfig) Descriptor() ([]byte, []int) {
+	return file_proto_config_v1_config_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RateStateConfig) GetBackend() RateStateBackend {
+	if x != nil {
+		return x.Backend
+	}
+	return RateStateBackend_RATE_STATE_BACKEND_UNSPECIFIED
+}
+
+func (x *RateStateConfig) GetDir() string {
+	if x != nil {
+		return x.Dir
+	}
+	return ""
+}
+
+func (x *RateStateConfig) GetSaveIntervalSeconds() uint32 {
+	if x != nil {
+		return x.SaveIntervalSeconds
+	}
+	return 0
+}
+
+type CoreConfig struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	GrpcAddr           string                 `protobuf:"bytes,1,opt,name=grpc_addr,json=grpcAddr,proto3" json:"grpc_addr,omitempty"`
+	HttpAddr           string                 `protobuf:"bytes,2,opt,name=http_addr,json=httpAddr,proto3" json:"http_addr,omitempty"`
+	DashboardDir       string                 `protobuf:"bytes,3,opt,name=dashboard_dir,json=dashboardDir,proto3" json:"dashboard_dir,omitempty"`
+	GrpcHealthDegraded DegradedHealth         `protobuf:"varint,4,opt,name=grpc_health_degraded,json=grpcHealthDegraded,proto3,enum=gohome.config.v1.DegradedHealth" json:"grpc_health_degraded,omitempty"`
+	Auth               *AuthConfig            `protobuf:"bytes,5,opt,name=auth,proto3" json:"auth,omitempty"`
+	Tls                *TLSConfig             `protobuf:"bytes,6,opt,name=tls,proto3" json:"tls,omitempty"`
+	Audit              *AuditConfig           `protobuf:"bytes,7,opt,name=audit,proto3" json:"audit,omitempty"`
+	RateState          *RateStateConfig       `protobuf:"bytes,8,opt,name=rate_state,json=rateState,proto3" json:"rate_state,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
 func (x *CoreConfig) Reset() {
 	*x = CoreConfig{}
-	mi := &file_proto_config_v1_config_proto_msgTypes[0]
+	mi := &file_proto_config_v1_config_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -52,7 +507,7 @@ func (x *CoreConfig) String() string {
 func (*CoreConfig) ProtoMessage() {}
 
 func (x *CoreConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_config_v1_config_proto_msgTypes[0]
+	mi := &file_proto_config_v1_config_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -65,7 +520,7 @@ func (x *CoreConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CoreConfig.ProtoReflect.Descriptor instead.
 func (*CoreConfig) Descriptor() ([]byte, []int) {
-	return file_proto_config_v1_config_proto_rawDescGZIP(), []int{0}
+	return file_proto_config_v1_config_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *CoreConfig) GetGrpcAddr() string {
@@ -89,6 +544,41 @@ func (x *CoreConfig) GetDashboardDir() string {
 	return ""
 }
 
+func (x *CoreConfig) GetGrpcHealthDegraded() DegradedHealth {
+	if x != nil {
+		return x.GrpcHealthDegraded
+	}
+	return DegradedHealth_DEGRADED_HEALTH_UNSPECIFIED
+}
+
+func (x *CoreConfig) GetAuth() *AuthConfig {
+	if x != nil {
+		return x.Auth
+	}
+	return nil
+}
+
+func (x *CoreConfig) GetTls() *TLSConfig {
+	if x != nil {
+		return x.Tls
+	}
+	return nil
+}
+
+func (x *CoreConfig) GetAudit() *AuditConfig {
+	if x != nil {
+		return x.Audit
+	}
+	return nil
+}
+
+func (x *CoreConfig) GetRateState() *RateStateConfig {
+	if x != nil {
+		return x.RateState
+	}
+	return nil
+}
+
 type OAuthConfig struct {
 	state                  protoimpl.MessageState `protogen:"open.v1"`
 	BlobEndpoint           string                 `protobuf:"bytes,1,opt,name=blob_endpoint,json=blobEndpoint,proto3" json:"blob_endpoint,omitempty"`
@@ -99,13 +589,16 @@ type OAuthConfig struct {
 	BlobRegion             string                 `protobuf:"bytes,6,opt,name=blob_region,json=blobRegion,proto3" json:"blob_region,omitempty"`
 	RefreshEnabled         *bool                  `protobuf:"varint,7,opt,name=refresh_enabled,json=refreshEnabled,proto3,oneof" json:"refresh_enabled,omitempty"`
 	RefreshIntervalSeconds uint32                 `protobuf:"varint,8,opt,name=refresh_interval_seconds,json=refreshIntervalSeconds,proto3" json:"refresh_interval_seconds,omitempty"`
+	BlobVersions           uint32                 `protobuf:"varint,9,opt,name=blob_versions,json=blobVersions,proto3" json:"blob_versions,omitempty"`
+	BlobKeyFile            string                 `protobuf:"bytes,10,opt,name=blob_key_file,json=blobKeyFile,proto3" json:"blob_key_file,omitempty"`
+	BlobPreviousKeyFiles   []string               `protobuf:"bytes,11,rep,name=blob_previous_key_files,json=blobPreviousKeyFiles,proto3" json:"blob_previous_key_files,omitempty"`
 	unknownFields          protoimpl.UnknownFields
 	sizeCache              protoimpl.SizeCache
 }
 
 func (x *OAuthConfig) Reset() {
 	*x = OAuthConfig{}
-	mi := &file_proto_config_v1_config_proto_msgTypes[1]
+	mi := &file_proto_config_v1_config_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -117,7 +610,7 @@ func (x *OAuthConfig) String() string {
 func (*OAuthConfig) ProtoMessage() {}
 
 func (x *OAuthConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_config_v1_config_proto_msgTypes[1]
+	mi := &file_proto_config_v1_config_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -130,7 +623,7 @@ func (x *OAuthConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use OAuthConfig.ProtoReflect.Descriptor instead.
 func (*OAuthConfig) Descriptor() ([]byte, []int) {
-	return file_proto_config_v1_config_proto_rawDescGZIP(), []int{1}
+	return file_proto_config_v1_config_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *OAuthConfig) GetBlobEndpoint() string {
@@ -189,6 +682,27 @@ func (x *OAuthConfig) GetRefreshIntervalSeconds() uint32 {
 	return 0
 }
 
+func (x *OAuthConfig) GetBlobVersions() uint32 {
+	if x != nil {
+		return x.BlobVersions
+	}
+	return 0
+}
+
+func (x *OAuthConfig) GetBlobKeyFile() string {
+	if x != nil {
+		return x.BlobKeyFile
+	}
+	return ""
+}
+
+func (x *OAuthConfig) GetBlobPreviousKeyFiles() []string {
+	if x != nil {
+		return x.BlobPreviousKeyFiles
+	}
+	return nil
+}
+
 type Config struct {
 	state         protoimpl.MessageState  `protogen:"open.v1"`
 	SchemaVersion uint32                  `protobuf:"varint,1,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
@@ -208,7 +722,7 @@ type Config struct {
 
 func (x *Config) Reset() {
 	*x = Config{}
-	mi := &file_proto_config_v1_config_proto_msgTypes[2]
+	mi := &file_proto_config_v1_config_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -220,7 +734,7 @@ func (x *Config) String() string {
 func (*Config) ProtoMessage() {}
 
 func (x *Config) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_config_v1_config_proto_msgTypes[2]
+	mi := &file_proto_config_v1_config_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -233,7 +747,7 @@ func (x *Config) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Config.ProtoReflect.Descriptor instead.
 func (*Config) Descriptor() ([]byte, []int) {
-	return file_proto_config_v1_config_proto_rawDescGZIP(), []int{2}
+	return file_proto_config_v1_config_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *Config) GetSchemaVersion() uint32 {
@@ -317,12 +831,45 @@ var File_proto_config_v1_config_proto protoreflect.FileDescriptor
 
 const file_proto_config_v1_config_proto_rawDesc = "" +
 	"\n" +
-	"\x1cproto/config/v1/config.proto\x12\x10gohome.config.v1\x1a\x18proto/plugins/tado.proto\x1a\x1aproto/plugins/daikin.proto\x1a\x1bproto/plugins/growatt.proto\x1a\x1cproto/plugins/roborock.proto\x1a!proto/plugins/p1_homewizard.proto\x1a\x1fproto/plugins/airgradient.proto\x1a\x1aproto/plugins/weheat.proto\x1a\x18proto/plugins/home.proto\"k\n" +
+	"\x1cproto/config/v1/config.proto\x12\x10gohome.config.v1\x1a\x18proto/plugins/tado.proto\x1a\x1aproto/plugins/daikin.proto\x1a\x1bproto/plugins/growatt.proto\x1a\x1cproto/plugins/roborock.proto\x1a!proto/plugins/p1_homewizard.proto\x1a\x1fproto/plugins/airgradient.proto\x1a\x1aproto/plugins/weheat.proto\x1a\x18proto/plugins/home.proto\"<\n" +
+	"\bDenyRule\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12\x18\n" +
+	"\abetween\x18\x02 \x01(\tR\abetween\"\x87\x01\n" +
+	"\n" +
+	"NamedToken\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"token_file\x18\x02 \x01(\tR\ttokenFile\x12\x16\n" +
+	"\x06scopes\x18\x03 \x03(\tR\x06scopes\x12.\n" +
+	"\x04deny\x18\x04 \x03(\v2\x1a.gohome.config.v1.DenyRuleR\x04deny\"a\n" +
+	"\n" +
+	"AuthConfig\x12\x1d\n" +
+	"\n" +
+	"token_file\x18\x01 \x01(\tR\ttokenFile\x124\n" +
+	"\x06tokens\x18\x02 \x03(\v2\x1c.gohome.config.v1.NamedTokenR\x06tokens\"i\n" +
+	"\tTLSConfig\x12\x1b\n" +
+	"\tcert_file\x18\x01 \x01(\tR\bcertFile\x12\x19\n" +
+	"\bkey_file\x18\x02 \x01(\tR\akeyFile\x12$\n" +
+	"\x0eclient_ca_file\x18\x03 \x01(\tR\fclientCaFile\"^\n" +
+	"\vAuditConfig\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1e\n" +
+	"\vmax_size_mb\x18\x02 \x01(\rR\tmaxSizeMb\x12\x1b\n" +
+	"\tmax_files\x18\x03 \x01(\rR\bmaxFiles\"\x95\x01\n" +
+	"\x0fRateStateConfig\x12<\n" +
+	"\abackend\x18\x01 \x01(\x0e2\".gohome.config.v1.RateStateBackendR\abackend\x12\x10\n" +
+	"\x03dir\x18\x02 \x01(\tR\x03dir\x122\n" +
+	"\x15save_interval_seconds\x18\x03 \x01(\rR\x13saveIntervalSeconds\"\x97\x03\n" +
 	"\n" +
 	"CoreConfig\x12\x1b\n" +
 	"\tgrpc_addr\x18\x01 \x01(\tR\bgrpcAddr\x12\x1b\n" +
 	"\thttp_addr\x18\x02 \x01(\tR\bhttpAddr\x12#\n" +
-	"\rdashboard_dir\x18\x03 \x01(\tR\fdashboardDir\"\xf3\x02\n" +
+	"\rdashboard_dir\x18\x03 \x01(\tR\fdashboardDir\x12R\n" +
+	"\x14grpc_health_degraded\x18\x04 \x01(\x0e2 .gohome.config.v1.DegradedHealthR\x12grpcHealthDegraded\x120\n" +
+	"\x04auth\x18\x05 \x01(\v2\x1c.gohome.config.v1.AuthConfigR\x04auth\x12-\n" +
+	"\x03tls\x18\x06 \x01(\v2\x1b.gohome.config.v1.TLSConfigR\x03tls\x123\n" +
+	"\x05audit\x18\a \x01(\v2\x1d.gohome.config.v1.AuditConfigR\x05audit\x12@\n" +
+	"\n" +
+	"rate_state\x18\b \x01(\v2!.gohome.config.v1.RateStateConfigR\trateState\"\xf3\x03\n" +
 	"\vOAuthConfig\x12#\n" +
 	"\rblob_endpoint\x18\x01 \x01(\tR\fblobEndpoint\x12\x1f\n" +
 	"\vblob_bucket\x18\x02 \x01(\tR\n" +
@@ -334,7 +881,11 @@ const file_proto_config_v1_config_proto_rawDesc = "" +
 	"\vblob_region\x18\x06 \x01(\tR\n" +
 	"blobRegion\x12,\n" +
 	"\x0frefresh_enabled\x18\a \x01(\bH\x00R\x0erefreshEnabled\x88\x01\x01\x128\n" +
-	"\x18refresh_interval_seconds\x18\b \x01(\rR\x16refreshIntervalSecondsB\x12\n" +
+	"\x18refresh_interval_seconds\x18\b \x01(\rR\x16refreshIntervalSeconds\x12#\n" +
+	"\rblob_versions\x18\t \x01(\rR\fblobVersions\x12\"\n" +
+	"\rblob_key_file\x18\n" +
+	" \x01(\tR\vblobKeyFile\x125\n" +
+	"\x17blob_previous_key_files\x18\v \x03(\tR\x14blobPreviousKeyFilesB\x12\n" +
 	"\x10_refresh_enabled\"\xc0\x05\n" +
 	"\x06Config\x12%\n" +
 	"\x0eschema_version\x18\x01 \x01(\rR\rschemaVersion\x120\n" +
@@ -348,7 +899,15 @@ const file_proto_config_v1_config_proto_rawDesc = "" +
 	"\rp1_homewizard\x18\x0e \x01(\v23.gohome.plugins.p1_homewizard.v1.P1HomewizardConfigR\fp1Homewizard\x12R\n" +
 	"\vairgradient\x18\x0f \x01(\v20.gohome.plugins.airgradient.v1.AirgradientConfigR\vairgradient\x12>\n" +
 	"\x06weheat\x18\x10 \x01(\v2&.gohome.plugins.weheat.v1.WeheatConfigR\x06weheat\x126\n" +
-	"\x04home\x18\x11 \x01(\v2\".gohome.plugins.home.v1.HomeConfigR\x04homeB9Z7github.com/joshp123/gohome/proto/gen/config/v1;configv1b\x06proto3"
+	"\x04home\x18\x11 \x01(\v2\".gohome.plugins.home.v1.HomeConfigR\x04home*o\n" +
+	"\x0eDegradedHealth\x12\x1f\n" +
+	"\x1bDEGRADED_HEALTH_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17DEGRADED_HEALTH_SERVING\x10\x01\x12\x1f\n" +
+	"\x1bDEGRADED_HEALTH_NOT_SERVING\x10\x02*p\n" +
+	"\x10RateStateBackend\x12\"\n" +
+	"\x1eRATE_STATE_BACKEND_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17RATE_STATE_BACKEND_FILE\x10\x01\x12\x1b\n" +
+	"\x17RATE_STATE_BACKEND_BLOB\x10\x02B9Z7github.com/joshp123/gohome/proto/gen/config/v1;configv1b\x06proto3"
 
 var (
 	file_proto_config_v1_config_proto_rawDescOnce sync.Once
@@ -362,36 +921,53 @@ func file_proto_config_v1_config_proto_rawDescGZIP() []byte {
 	return file_proto_config_v1_config_proto_rawDescData
 }
 
-var file_proto_config_v1_config_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_proto_config_v1_config_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_proto_config_v1_config_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_proto_config_v1_config_proto_goTypes = []any{
-	(*CoreConfig)(nil),             // 0: gohome.config.v1.CoreConfig
-	(*OAuthConfig)(nil),            // 1: gohome.config.v1.OAuthConfig
-	(*Config)(nil),                 // 2: gohome.config.v1.Config
-	(*v1.TadoConfig)(nil),          // 3: gohome.plugins.tado.v1.TadoConfig
-	(*v11.DaikinConfig)(nil),       // 4: gohome.plugins.daikin.v1.DaikinConfig
-	(*v12.GrowattConfig)(nil),      // 5: gohome.plugins.growatt.v1.GrowattConfig
-	(*v13.RoborockConfig)(nil),     // 6: gohome.plugins.roborock.v1.RoborockConfig
-	(*v14.P1HomewizardConfig)(nil), // 7: gohome.plugins.p1_homewizard.v1.P1HomewizardConfig
-	(*v15.AirgradientConfig)(nil),  // 8: gohome.plugins.airgradient.v1.AirgradientConfig
-	(*v16.WeheatConfig)(nil),       // 9: gohome.plugins.weheat.v1.WeheatConfig
-	(*v17.HomeConfig)(nil),         // 10: gohome.plugins.home.v1.HomeConfig
+	(DegradedHealth)(0),            // 0: gohome.config.v1.DegradedHealth
+	(RateStateBackend)(0),          // 1: gohome.config.v1.RateStateBackend
+	(*DenyRule)(nil),               // 2: gohome.config.v1.DenyRule
+	(*NamedToken)(nil),             // 3: gohome.config.v1.NamedToken
+	(*AuthConfig)(nil),             // 4: gohome.config.v1.AuthConfig
+	(*TLSConfig)(nil),              // 5: gohome.config.v1.TLSConfig
+	(*AuditConfig)(nil),            // 6: gohome.config.v1.AuditConfig
+	(*RateStateConfig)(nil),        // 7: gohome.config.v1.RateStateConfig
+	(*CoreConfig)(nil),             // 8: gohome.config.v1.CoreConfig
+	(*OAuthConfig)(nil),            // 9: gohome.config.v1.OAuthConfig
+	(*Config)(nil),                 // 10: gohome.config.v1.Config
+	(*v1.TadoConfig)(nil),          // 11: gohome.plugins.tado.v1.TadoConfig
+	(*v11.DaikinConfig)(nil),       // 12: gohome.plugins.daikin.v1.DaikinConfig
+	(*v12.GrowattConfig)(nil),      // 13: gohome.plugins.growatt.v1.GrowattConfig
+	(*v13.RoborockConfig)(nil),     // 14: gohome.plugins.roborock.v1.RoborockConfig
+	(*v14.P1HomewizardConfig)(nil), // 15: gohome.plugins.p1_homewizard.v1.P1HomewizardConfig
+	(*v15.AirgradientConfig)(nil),  // 16: gohome.plugins.airgradient.v1.AirgradientConfig
+	(*v16.WeheatConfig)(nil),       // 17: gohome.plugins.weheat.v1.WeheatConfig
+	(*v17.HomeConfig)(nil),         // 18: gohome.plugins.home.v1.HomeConfig
 }
 var file_proto_config_v1_config_proto_depIdxs = []int32{
-	0,  // 0: gohome.config.v1.Config.core:type_name -> gohome.config.v1.CoreConfig
-	1,  // 1: gohome.config.v1.Config.oauth:type_name -> gohome.config.v1.OAuthConfig
-	3,  // 2: gohome.config.v1.Config.tado:type_name -> gohome.plugins.tado.v1.TadoConfig
-	4,  // 3: gohome.config.v1.Config.daikin:type_name -> gohome.plugins.daikin.v1.DaikinConfig
-	5,  // 4: gohome.config.v1.Config.growatt:type_name -> gohome.plugins.growatt.v1.GrowattConfig
-	6,  // 5: gohome.config.v1.Config.roborock:type_name -> gohome.plugins.roborock.v1.RoborockConfig
-	7,  // 6: gohome.config.v1.Config.p1_homewizard:type_name -> gohome.plugins.p1_homewizard.v1.P1HomewizardConfig
-	8,  // 7: gohome.config.v1.Config.airgradient:type_name -> gohome.plugins.airgradient.v1.AirgradientConfig
-	9,  // 8: gohome.config.v1.Config.weheat:type_name -> gohome.plugins.weheat.v1.WeheatConfig
-	10, // 9: gohome.config.v1.Config.home:type_name -> gohome.plugins.home.v1.HomeConfig
-	10, // [10:10] is the sub-list for method output_type
-	10, // [10:10] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	2,  // 0: gohome.config.v1.NamedToken.deny:type_name -> gohome.config.v1.DenyRule
+	3,  // 1: gohome.config.v1.AuthConfig.tokens:type_name -> gohome.config.v1.NamedToken
+	1,  // 2: gohome.config.v1.RateStateConfig.backend:type_name -> gohome.config.v1.RateStateBackend
+	0,  // 3: gohome.config.v1.CoreConfig.grpc_health_degraded:type_name -> gohome.config.v1.DegradedHealth
+	4,  // 4: gohome.config.v1.CoreConfig.auth:type_name -> gohome.config.v1.AuthConfig
+	5,  // 5: gohome.config.v1.CoreConfig.tls:type_name -> gohome.config.v1.TLSConfig
+	6,  // 6: gohome.config.v1.CoreConfig.audit:type_name -> gohome.config.v1.AuditConfig
+	7,  // 7: gohome.config.v1.CoreConfig.rate_state:type_name -> gohome.config.v1.RateStateConfig
+	8,  // 8: gohome.config.v1.Config.core:type_name -> gohome.config.v1.CoreConfig
+	9,  // 9: gohome.config.v1.Config.oauth:type_name -> gohome.config.v1.OAuthConfig
+	11, // 10: gohome.config.v1.Config.tado:type_name -> gohome.plugins.tado.v1.TadoConfig
+	12, // 11: gohome.config.v1.Config.daikin:type_name -> gohome.plugins.daikin.v1.DaikinConfig
+	13, // 12: gohome.config.v1.Config.growatt:type_name -> gohome.plugins.growatt.v1.GrowattConfig
+	14, // 13: gohome.config.v1.Config.roborock:type_name -> gohome.plugins.roborock.v1.RoborockConfig
+	15, // 14: gohome.config.v1.Config.p1_homewizard:type_name -> gohome.plugins.p1_homewizard.v1.P1HomewizardConfig
+	16, // 15: gohome.config.v1.Config.airgradient:type_name -> gohome.plugins.airgradient.v1.AirgradientConfig
+	17, // 16: gohome.config.v1.Config.weheat:type_name -> gohome.plugins.weheat.v1.WeheatConfig
+	18, // 17: gohome.config.v1.Config.home:type_name -> gohome.plugins.home.v1.HomeConfig
+	18, // [18:18] is the sub-list for method output_type
+	18, // [18:18] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
 }
 
 func init() { file_proto_config_v1_config_proto_init() }
@@ -399,19 +975,20 @@ func file_proto_config_v1_config_proto_init() {
 	if File_proto_config_v1_config_proto != nil {
 		return
 	}
-	file_proto_config_v1_config_proto_msgTypes[1].OneofWrappers = []any{}
+	file_proto_config_v1_config_proto_msgTypes[7].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_config_v1_config_proto_rawDesc), len(file_proto_config_v1_config_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   3,
+			NumEnums:      2,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_proto_config_v1_config_proto_goTypes,
 		DependencyIndexes: file_proto_config_v1_config_proto_depIdxs,
+		EnumInfos:         file_proto_config_v1_config_proto_enumTypes,
 		MessageInfos:      file_proto_config_v1_config_proto_msgTypes,
 	}.Build()
 	File_proto_config_v1_config_proto = out.File
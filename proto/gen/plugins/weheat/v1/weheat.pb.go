@@ -1032,16 +1032,16 @@ const file_proto_plugins_weheat_proto_rawDesc = "" +
 	"\x04json\x18\x01 \x03(\tR\x04json\";\n" +
 	"\fWeheatConfig\x12\x1e\n" +
 	"\bbase_url\x18\x01 \x01(\tH\x00R\abaseUrl\x88\x01\x01B\v\n" +
-	"\t_base_url2\x9b\x06\n" +
-	"\rWeheatService\x12p\n" +
-	"\rListHeatPumps\x12..gohome.plugins.weheat.v1.ListHeatPumpsRequest\x1a/.gohome.plugins.weheat.v1.ListHeatPumpsResponse\x12j\n" +
-	"\vGetHeatPump\x12,.gohome.plugins.weheat.v1.GetHeatPumpRequest\x1a-.gohome.plugins.weheat.v1.GetHeatPumpResponse\x12m\n" +
-	"\fGetLatestLog\x12-.gohome.plugins.weheat.v1.GetLatestLogRequest\x1a..gohome.plugins.weheat.v1.GetLatestLogResponse\x12g\n" +
+	"\t_base_url2\xbe\x06\n" +
+	"\rWeheatService\x12u\n" +
+	"\rListHeatPumps\x12..gohome.plugins.weheat.v1.ListHeatPumpsRequest\x1a/.gohome.plugins.weheat.v1.ListHeatPumpsResponse\"\x03\x90\x02\x01\x12o\n" +
+	"\vGetHeatPump\x12,.gohome.plugins.weheat.v1.GetHeatPumpRequest\x1a-.gohome.plugins.weheat.v1.GetHeatPumpResponse\"\x03\x90\x02\x01\x12r\n" +
+	"\fGetLatestLog\x12-.gohome.plugins.weheat.v1.GetLatestLogRequest\x1a..gohome.plugins.weheat.v1.GetLatestLogResponse\"\x03\x90\x02\x01\x12l\n" +
 	"\n" +
-	"GetRawLogs\x12+.gohome.plugins.weheat.v1.GetRawLogsRequest\x1a,.gohome.plugins.weheat.v1.GetRawLogsResponse\x12j\n" +
-	"\vGetLogViews\x12,.gohome.plugins.weheat.v1.GetLogViewsRequest\x1a-.gohome.plugins.weheat.v1.GetLogViewsResponse\x12v\n" +
-	"\x0fGetEnergyTotals\x120.gohome.plugins.weheat.v1.GetEnergyTotalsRequest\x1a1.gohome.plugins.weheat.v1.GetEnergyTotalsResponse\x12p\n" +
-	"\rGetEnergyLogs\x12..gohome.plugins.weheat.v1.GetEnergyLogsRequest\x1a/.gohome.plugins.weheat.v1.GetEnergyLogsResponseBAZ?github.com/joshp123/gohome/proto/gen/plugins/weheat/v1;weheatv1b\x06proto3"
+	"GetRawLogs\x12+.gohome.plugins.weheat.v1.GetRawLogsRequest\x1a,.gohome.plugins.weheat.v1.GetRawLogsResponse\"\x03\x90\x02\x01\x12o\n" +
+	"\vGetLogViews\x12,.gohome.plugins.weheat.v1.GetLogViewsRequest\x1a-.gohome.plugins.weheat.v1.GetLogViewsResponse\"\x03\x90\x02\x01\x12{\n" +
+	"\x0fGetEnergyTotals\x120.gohome.plugins.weheat.v1.GetEnergyTotalsRequest\x1a1.gohome.plugins.weheat.v1.GetEnergyTotalsResponse\"\x03\x90\x02\x01\x12u\n" +
+	"\rGetEnergyLogs\x12..gohome.plugins.weheat.v1.GetEnergyLogsRequest\x1a/.gohome.plugins.weheat.v1.GetEnergyLogsResponse\"\x03\x90\x02\x01BAZ?github.com/joshp123/gohome/proto/gen/plugins/weheat/v1;weheatv1b\x06proto3"
 
 var (
 	file_proto_plugins_weheat_proto_rawDescOnce sync.Once
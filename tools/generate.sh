@@ -7,6 +7,9 @@ protoc \
   --go_out=. --go_opt=module=github.com/joshp123/gohome \
   --go-grpc_out=. --go-grpc_opt=module=github.com/joshp123/gohome \
   proto/registry.proto \
+  proto/audit.proto \
+  proto/rate.proto \
+  proto/oauth.proto \
   proto/config/v1/config.proto \
   proto/plugins/tado.proto \
   proto/plugins/daikin.proto \